@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +21,31 @@ import (
 	klog "k8s.io/klog/v2"
 )
 
+const (
+	// netDefAnnotationPrefix is the prefix of net-attach-def annotations used to configure
+	// multi-networkpolicy-tc behaviour for a network
+	netDefAnnotationPrefix = "tc.multi-networkpolicy.k8s.cni.cncf.io/"
+	// NetDefAnnotationStrictMode is the net-attach-def annotation used to enable strict mode for the network.
+	// valid values are "true" or "false"
+	NetDefAnnotationStrictMode = netDefAnnotationPrefix + "strict-mode"
+	// NetDefAnnotationAntiSpoofing is the net-attach-def annotation used to enable source address anti-spoofing
+	// for the network. valid values are "true" or "false"
+	NetDefAnnotationAntiSpoofing = netDefAnnotationPrefix + "anti-spoofing"
+	// NetDefAnnotationMACPeers is the net-attach-def annotation used to render pod/namespace selector peers
+	// to peer MAC addresses instead of peer IPs for the network. valid values are "true" or "false"
+	NetDefAnnotationMACPeers = netDefAnnotationPrefix + "mac-peers"
+	// NetDefAnnotationVlanMode is the net-attach-def annotation used to set the VLAN mode of the network
+	// (see VlanMode)
+	NetDefAnnotationVlanMode = netDefAnnotationPrefix + "vlan-mode"
+	// NetDefAnnotationTCGenerator is the net-attach-def annotation used to select the TC generator of the network
+	// overriding the default generator. valid values are the TC generator names (e.g "simple", "chain", "u32")
+	NetDefAnnotationTCGenerator = netDefAnnotationPrefix + "tc-generator"
+
+	// maxNetworkVlanIDs is the maximum number of VLAN IDs of a network, a network with more VLAN IDs
+	// (e.g a wide trunk range) is treated as if it has no VLAN configuration as every VLAN ID requires its own filters
+	maxNetworkVlanIDs = 16
+)
+
 // NetDefHandler is an abstract interface of objects which receive
 // notifications about net-attach-def object changes.
 type NetDefHandler interface {
@@ -129,10 +157,147 @@ func (c *NetDefConfig) handleDeleteNetDef(obj interface{}) {
 	}
 }
 
+const (
+	// VlanModeTagged is the VLAN mode of a network whose interfaces may send VLAN tagged traffic
+	VlanModeTagged VlanMode = "tagged"
+	// VlanModeUntagged is the VLAN mode of a network whose interfaces never send VLAN tagged traffic
+	VlanModeUntagged VlanMode = "untagged"
+)
+
+// VlanMode is the VLAN mode of a network, it determines whether VLAN tagged traffic is expected on the network
+type VlanMode string
+
+// VlanModeFromString returns VlanMode from its string representation,
+// an error is returned if string does not represent a known VlanMode
+func VlanModeFromString(s string) (VlanMode, error) {
+	mode := VlanMode(strings.ToLower(strings.TrimSpace(s)))
+	switch mode {
+	case "", VlanModeTagged, VlanModeUntagged:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown VLAN mode: %s", s)
+}
+
+// NetworkConfig contains multi-networkpolicy-tc specific configuration of a network
+type NetworkConfig struct {
+	// StrictMode if set, all traffic which is not explicitly allowed is dropped on isolated interfaces
+	// not just IP traffic
+	StrictMode bool
+	// AntiSpoofing if set, traffic sent from the interface with source address which does not belong to it is dropped
+	AntiSpoofing bool
+	// MACPeers if set, pod/namespace selector peers are matched by their MAC address instead of their IPs
+	MACPeers bool
+	// VlanIDs are the VLAN IDs used by the network as specified in its CNI configuration (vlan, trunk),
+	// empty if network has no VLAN configuration
+	VlanIDs []uint16
+	// VlanMode is the VLAN mode of the network, empty if not configured
+	VlanMode VlanMode
+	// TCGenerator is the name of the TC generator of the network, empty if not configured
+	TCGenerator string
+}
+
+// networkConfigFromNetDef creates NetworkConfig from NetworkAttachmentDefinition annotations.
+// invalid annotation values are logged and ignored.
+func networkConfigFromNetDef(netdef *netdefv1.NetworkAttachmentDefinition) NetworkConfig {
+	return NetworkConfig{
+		StrictMode:   boolFromNetDefAnnotation(netdef, NetDefAnnotationStrictMode),
+		AntiSpoofing: boolFromNetDefAnnotation(netdef, NetDefAnnotationAntiSpoofing),
+		MACPeers:     boolFromNetDefAnnotation(netdef, NetDefAnnotationMACPeers),
+		VlanMode:     vlanModeFromNetDef(netdef),
+		TCGenerator:  strings.ToLower(strings.TrimSpace(netdef.Annotations[NetDefAnnotationTCGenerator])),
+	}
+}
+
+// vlanModeFromNetDef returns the VlanMode of the given NetworkAttachmentDefinition.
+// empty VlanMode is returned if annotation does not exist or its value is invalid.
+func vlanModeFromNetDef(netdef *netdefv1.NetworkAttachmentDefinition) VlanMode {
+	mode, err := VlanModeFromString(netdef.Annotations[NetDefAnnotationVlanMode])
+	if err != nil {
+		klog.Warningf("invalid value for annotation %s in net-attach-def %s/%s. %v",
+			NetDefAnnotationVlanMode, netdef.Namespace, netdef.Name, err)
+		return ""
+	}
+	return mode
+}
+
+// boolFromNetDefAnnotation returns the boolean value of the given NetworkAttachmentDefinition annotation.
+// false is returned if annotation does not exist or its value is invalid.
+func boolFromNetDefAnnotation(netdef *netdefv1.NetworkAttachmentDefinition, annotation string) bool {
+	val, ok := netdef.Annotations[annotation]
+	if !ok {
+		return false
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		klog.Warningf("invalid value for annotation %s in net-attach-def %s/%s. %v",
+			annotation, netdef.Namespace, netdef.Name, err)
+		return false
+	}
+	return b
+}
+
+// vlanNetConf contains the VLAN related attributes of a CNI plugin configuration
+// (e.g accelerated-bridge, sriov)
+type vlanNetConf struct {
+	Vlan  int             `json:"vlan"`
+	Trunk []vlanTrunkConf `json:"trunk"`
+}
+
+// vlanTrunkConf is a single VLAN trunk configuration, either a VLAN ID range or a VLAN ID
+type vlanTrunkConf struct {
+	MinID *uint16 `json:"minID,omitempty"`
+	MaxID *uint16 `json:"maxID,omitempty"`
+	ID    *uint16 `json:"id,omitempty"`
+}
+
+// vlanIDsFromPluginConf returns the sorted VLAN IDs specified in the given CNI plugin configuration.
+// nil is returned if plugin configuration does not specify VLAN IDs or if it specifies more than maxNetworkVlanIDs.
+func vlanIDsFromPluginConf(pluginConf []byte) ([]uint16, error) {
+	vlanConf := &vlanNetConf{}
+	if err := json.Unmarshal(pluginConf, vlanConf); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint16]struct{})
+	if vlanConf.Vlan > 0 {
+		ids[uint16(vlanConf.Vlan)] = struct{}{}
+	}
+	for _, trunk := range vlanConf.Trunk {
+		if trunk.ID != nil {
+			ids[*trunk.ID] = struct{}{}
+		}
+		if trunk.MinID == nil || trunk.MaxID == nil {
+			continue
+		}
+		for id := int(*trunk.MinID); id <= int(*trunk.MaxID); id++ {
+			ids[uint16(id)] = struct{}{}
+			if len(ids) > maxNetworkVlanIDs {
+				break
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if len(ids) > maxNetworkVlanIDs {
+		return nil, fmt.Errorf("network has more than %d VLAN IDs", maxNetworkVlanIDs)
+	}
+
+	vlanIDs := make([]uint16, 0, len(ids))
+	for id := range ids {
+		vlanIDs = append(vlanIDs, id)
+	}
+	sort.Slice(vlanIDs, func(i, j int) bool { return vlanIDs[i] < vlanIDs[j] })
+	return vlanIDs, nil
+}
+
 // NetDefInfo contains information about NetworkAttachmentDefinition.
 type NetDefInfo struct {
 	Netdef     *netdefv1.NetworkAttachmentDefinition
 	PluginType string
+	Config     NetworkConfig
 }
 
 // Name returns NetworkAttachmentDefinition name
@@ -218,6 +383,15 @@ func (ndt *NetDefChangeTracker) GetPluginType(name types.NamespacedName) string
 	return ""
 }
 
+// GetNetworkConfig returns the NetworkConfig for the given (secondary) network represented by its namespaced name
+func (ndt *NetDefChangeTracker) GetNetworkConfig(name types.NamespacedName) NetworkConfig {
+	ndt.netdefMap.Update(ndt)
+	if cur, ok := ndt.netdefMap[name]; ok {
+		return cur.Config
+	}
+	return NetworkConfig{}
+}
+
 // newNetDefInfo creates a new instance of NetDefInfo
 func (ndt *NetDefChangeTracker) newNetDefInfo(netdef *netdefv1.NetworkAttachmentDefinition) (*NetDefInfo, error) {
 	confBytes, err := netdefutils.GetCNIConfig(netdef, "/etc/cni/multus/net.d")
@@ -225,28 +399,38 @@ func (ndt *NetDefChangeTracker) newNetDefInfo(netdef *netdefv1.NetworkAttachment
 		return nil, err
 	}
 
-	netconfList := &cnitypes.NetConfList{}
+	netconfList := &struct {
+		Plugins []json.RawMessage `json:"plugins"`
+	}{}
 	if err := json.Unmarshal(confBytes, netconfList); err != nil {
 		return nil, err
 	}
 
-	var info *NetDefInfo
-	if len(netconfList.Plugins) == 0 {
-		netconf := &cnitypes.NetConf{}
-		if err := json.Unmarshal(confBytes, netconf); err != nil {
-			return nil, err
-		}
+	// the first plugin in the list is the main plugin of the network
+	pluginConf := confBytes
+	if len(netconfList.Plugins) > 0 {
+		pluginConf = netconfList.Plugins[0]
+	}
 
-		info = &NetDefInfo{
-			Netdef:     netdef,
-			PluginType: netconf.Type,
-		}
-	} else {
-		info = &NetDefInfo{
-			Netdef:     netdef,
-			PluginType: netconfList.Plugins[0].Type,
-		}
+	netconf := &cnitypes.NetConf{}
+	if err := json.Unmarshal(pluginConf, netconf); err != nil {
+		return nil, err
+	}
+
+	info := &NetDefInfo{
+		Netdef:     netdef,
+		PluginType: netconf.Type,
+		Config:     networkConfigFromNetDef(netdef),
 	}
+
+	vlanIDs, err := vlanIDsFromPluginConf(pluginConf)
+	if err != nil {
+		// tagged traffic is not restricted to specific VLANs
+		klog.Warningf("failed to get VLAN IDs of net-attach-def %s/%s, ignoring. %v",
+			netdef.Namespace, netdef.Name, err)
+	}
+	info.Config.VlanIDs = vlanIDs
+
 	return info, nil
 }
 
@@ -195,6 +195,90 @@ var _ = Describe("net-attach-def change tracker", func() {
 		checkNetDefMapWithNetDef(updatedNd1, "testType2")
 	})
 
+	It("Add netdef with strict mode annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{controllers.NetDefAnnotationStrictMode: "true"}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+		Expect(ndChanges.Update(nil, nd2)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{StrictMode: true}))
+		Expect(ndChanges.GetNetworkConfig(nsName(nd2))).To(Equal(controllers.NetworkConfig{}))
+	})
+
+	It("Add netdef with invalid strict mode annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{controllers.NetDefAnnotationStrictMode: "maybe"}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{}))
+	})
+
+	It("Add netdef with anti-spoofing annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{
+			controllers.NetDefAnnotationAntiSpoofing: "true",
+			controllers.NetDefAnnotationStrictMode:   "false",
+		}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{AntiSpoofing: true}))
+	})
+
+	It("Add netdef with mac peers annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{controllers.NetDefAnnotationMACPeers: "true"}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{MACPeers: true}))
+	})
+
+	It("Add netdef with vlan mode annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{controllers.NetDefAnnotationVlanMode: "Untagged"}
+		nd2.Annotations = map[string]string{controllers.NetDefAnnotationVlanMode: "trunk"}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+		Expect(ndChanges.Update(nil, nd2)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(
+			controllers.NetworkConfig{VlanMode: controllers.VlanModeUntagged}))
+		Expect(ndChanges.GetNetworkConfig(nsName(nd2))).To(Equal(controllers.NetworkConfig{}))
+	})
+
+	It("Add netdef with tc generator annotation and verify network config", func() {
+		nd1.Annotations = map[string]string{controllers.NetDefAnnotationTCGenerator: " U32 "}
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{TCGenerator: "u32"}))
+	})
+
+	It("Add netdef with vlan and trunk and verify network config", func() {
+		nd1.Spec.Config = `{
+			"name": "cniConfig1",
+			"type": "accelerated-bridge",
+			"vlan": 100,
+			"trunk": [{"minID": 200, "maxID": 202}, {"id": 300}, {"id": 100}]
+		}`
+		nd2.Spec.Config = `{
+			"name": "cniConfig2",
+			"plugins": [{"type": "accelerated-bridge", "vlan": 10}, {"type": "tuning"}]
+		}`
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+		Expect(ndChanges.Update(nil, nd2)).To(BeTrue())
+
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(
+			controllers.NetworkConfig{VlanIDs: []uint16{100, 200, 201, 202, 300}}))
+		Expect(ndChanges.GetNetworkConfig(nsName(nd2))).To(Equal(
+			controllers.NetworkConfig{VlanIDs: []uint16{10}}))
+	})
+
+	It("Add netdef with wide trunk range and verify network config has no vlan IDs", func() {
+		nd1.Spec.Config = `{
+			"name": "cniConfig1",
+			"type": "accelerated-bridge",
+			"trunk": [{"minID": 1, "maxID": 4094}]
+		}`
+		Expect(ndChanges.Update(nil, nd1)).To(BeTrue())
+
+		ndMap.Update(ndChanges)
+		checkNetDefMapWithNetDef(nd1, "accelerated-bridge")
+		Expect(ndChanges.GetNetworkConfig(nsName(nd1))).To(Equal(controllers.NetworkConfig{}))
+	})
+
 	It("add same netdef as current and previous", func() {
 		Expect(ndChanges.Update(nd1, nd1)).To(BeTrue())
 		ndMap.Update(ndChanges)
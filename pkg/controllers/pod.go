@@ -12,6 +12,7 @@ import (
 	netdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	netdefutils "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -21,6 +22,11 @@ import (
 	klog "k8s.io/klog/v2"
 )
 
+// PodAnnotationEgressBandwidth is the pod annotation used to limit egress bandwidth of pod interfaces.
+// value is either a bandwidth (in bits per second) which applies to all pod interfaces (e.g "10M")
+// or a comma separated list of <network>:<bandwidth> (e.g "net1:10M,default/net2:1G")
+const PodAnnotationEgressBandwidth = "k8s.v1.cni.cncf.io/egress-bandwidth"
+
 // PodHandler is an abstract interface of objects which receive
 // notifications about pod object changes.
 type PodHandler interface {
@@ -138,6 +144,10 @@ type InterfaceInfo struct {
 	InterfaceName string
 	InterfaceType string
 	IPs           []string
+	MAC           string
+	NetworkConfig NetworkConfig
+	// EgressBandwidth is the egress bandwidth limit of the interface in bits per second, 0 if not limited
+	EgressBandwidth uint64
 }
 
 // CheckPolicyNetwork checks whether given interface is target or not,
@@ -268,16 +278,7 @@ func (pct *PodChangeTracker) newPodInfo(pod *v1.Pod) *PodInfo {
 		// match it with
 		for _, s := range statuses {
 			klog.V(8).Infof("processing network status: %+v", s)
-			var netNamespace, netName string
-			slashItems := strings.Split(s.Name, "/")
-			if len(slashItems) == 2 {
-				netNamespace = strings.TrimSpace(slashItems[0])
-				netName = slashItems[1]
-			} else {
-				netNamespace = pod.ObjectMeta.Namespace
-				netName = s.Name
-			}
-			namespacedName := types.NamespacedName{Namespace: netNamespace, Name: netName}
+			namespacedName := networkNamespacedName(pod.ObjectMeta.Namespace, s.Name)
 
 			for _, pluginName := range pct.networkPlugins {
 				if networkPlugins[namespacedName] == pluginName {
@@ -287,12 +288,20 @@ func (pct *PodChangeTracker) newPodInfo(pod *v1.Pod) *PodInfo {
 							"pod", podNamespacedName, "network", namespacedName)
 						continue
 					}
+					bandwidth, err := egressBandwidthForNetwork(pod, namespacedName)
+					if err != nil {
+						klog.ErrorS(err, "failed to get egress bandwidth for pod interface, ignoring",
+							"pod", podNamespacedName, "network", namespacedName)
+					}
 					netifs = append(netifs, InterfaceInfo{
-						NetattachName: s.Name,
-						InterfaceName: s.Interface,
-						DeviceID:      deviceID,
-						InterfaceType: networkPlugins[namespacedName],
-						IPs:           s.IPs,
+						NetattachName:   s.Name,
+						InterfaceName:   s.Interface,
+						DeviceID:        deviceID,
+						InterfaceType:   networkPlugins[namespacedName],
+						IPs:             s.IPs,
+						MAC:             s.Mac,
+						NetworkConfig:   pct.netdefChanges.GetNetworkConfig(namespacedName),
+						EgressBandwidth: bandwidth,
 					})
 				}
 			}
@@ -392,6 +401,25 @@ func (pm *PodMap) apply(changes *PodChangeTracker) {
 	}
 	// clear changes after applying them to ServiceMap.
 	changes.items = make(map[types.NamespacedName]*podChange)
+	// network configuration may change while the pod is running
+	pm.updateNetworkConfig(changes.netdefChanges)
+}
+
+// updateNetworkConfig sets NetworkConfig of every pod interface in PodMap to the current NetworkConfig of its network
+func (pm *PodMap) updateNetworkConfig(ndt *NetDefChangeTracker) {
+	if ndt == nil {
+		return
+	}
+
+	for podName, info := range *pm {
+		netifs := make([]InterfaceInfo, len(info.Interfaces))
+		copy(netifs, info.Interfaces)
+		for i := range netifs {
+			netifs[i].NetworkConfig = ndt.GetNetworkConfig(networkNamespacedName(info.Namespace, netifs[i].NetattachName))
+		}
+		info.Interfaces = netifs
+		(*pm)[podName] = info
+	}
 }
 
 // merge changes into PodMap
@@ -443,3 +471,53 @@ func (pm *PodMap) List() ([]PodInfo, error) {
 	}
 	return lst, nil
 }
+
+// networkNamespacedName returns the namespaced name of network as it appears in pod network status
+// (<namespace>/<name> or <name> for a network in the pod namespace)
+func networkNamespacedName(podNamespace, network string) types.NamespacedName {
+	if slashItems := strings.Split(network, "/"); len(slashItems) == 2 {
+		return types.NamespacedName{Namespace: strings.TrimSpace(slashItems[0]), Name: slashItems[1]}
+	}
+	return types.NamespacedName{Namespace: podNamespace, Name: network}
+}
+
+// egressBandwidthForNetwork returns the egress bandwidth limit (in bits per second) of the pod interface on the
+// given network according to PodAnnotationEgressBandwidth pod annotation. 0 is returned if not limited.
+func egressBandwidthForNetwork(pod *v1.Pod, network types.NamespacedName) (uint64, error) {
+	val, ok := pod.Annotations[PodAnnotationEgressBandwidth]
+	if !ok {
+		return 0, nil
+	}
+
+	parseBandwidth := func(s string) (uint64, error) {
+		q, err := resource.ParseQuantity(strings.TrimSpace(s))
+		if err != nil {
+			return 0, err
+		}
+		if q.Sign() <= 0 {
+			return 0, fmt.Errorf("invalid bandwidth %s", s)
+		}
+		return uint64(q.Value()), nil
+	}
+
+	if !strings.Contains(val, ":") {
+		return parseBandwidth(val)
+	}
+
+	for _, entry := range strings.Split(val, ",") {
+		netAndBandwidth := strings.SplitN(entry, ":", 2)
+		if len(netAndBandwidth) != 2 {
+			return 0, fmt.Errorf("invalid egress bandwidth entry %q", entry)
+		}
+		netName := strings.TrimSpace(netAndBandwidth[0])
+		entryNetwork := types.NamespacedName{Namespace: pod.Namespace, Name: netName}
+		if nsAndName := strings.Split(netName, "/"); len(nsAndName) == 2 {
+			entryNetwork = types.NamespacedName{Namespace: nsAndName[0], Name: nsAndName[1]}
+		}
+		if entryNetwork != network {
+			continue
+		}
+		return parseBandwidth(netAndBandwidth[1])
+	}
+	return 0, nil
+}
@@ -248,5 +248,43 @@ var _ = Describe("pod change tracker", func() {
 			Expect(podMap).To(HaveLen(1))
 			checkPodInfo(podWithNeworkAndStatus, 1)
 		})
+
+		It("Update netdef of running pod and verify network config", func() {
+			pod := testutil.NewFakePodWithNetAnnotation("testns1", "testpod1",
+				"net-attach1", testutil.NewFakeNetworkStatus("testns1", "net-attach1"))
+			Expect(podChanges.Update(nil, pod)).To(BeTrue())
+			podMap.Update(podChanges)
+			checkPodInfo(pod, 1)
+			Expect(podMap[nsName(pod)].Interfaces[0].NetworkConfig).To(Equal(controllers.NetworkConfig{}))
+
+			oldNetDef := testutil.NewNetDef("testns1", "net-attach1", testutil.NewCNIConfig(
+				"testCNI", "accelerated-bridge"))
+			netDef := oldNetDef.DeepCopy()
+			netDef.Annotations = map[string]string{controllers.NetDefAnnotationStrictMode: "true"}
+			Expect(ndChanges.Update(oldNetDef, netDef)).To(BeTrue())
+
+			// no pod change
+			podMap.Update(podChanges)
+			checkPodInfo(pod, 1)
+			Expect(podMap[nsName(pod)].Interfaces[0].NetworkConfig).To(Equal(controllers.NetworkConfig{StrictMode: true}))
+		})
+
+		DescribeTable("Add pod with egress bandwidth annotation",
+			func(annotation string, expectedBandwidth uint64) {
+				pod := testutil.NewFakePodWithNetAnnotation("testns1", "testpod1",
+					"net-attach1", testutil.NewFakeNetworkStatus("testns1", "net-attach1"))
+				pod.Annotations[controllers.PodAnnotationEgressBandwidth] = annotation
+				Expect(podChanges.Update(nil, pod)).To(BeTrue())
+				podMap.Update(podChanges)
+				checkPodInfo(pod, 1)
+				Expect(podMap[nsName(pod)].Interfaces[0].EgressBandwidth).To(Equal(expectedBandwidth))
+			},
+			Entry("all networks", "10M", uint64(10000000)),
+			Entry("network", "net-attach1:1G", uint64(1000000000)),
+			Entry("namespaced network", "other-net:10M,testns1/net-attach1:100M", uint64(100000000)),
+			Entry("other network", "testns2/net-attach1:10M", uint64(0)),
+			Entry("invalid", "fast", uint64(0)),
+			Entry("negative", "-10M", uint64(0)),
+		)
 	})
 })
@@ -0,0 +1,112 @@
+package policyrules
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Allowlist is a node-wide configuration of peers which are always allowed on isolated interfaces
+// of a network, regardless of policies. it is loaded from a YAML (or JSON) file, e.g:
+//
+//	networks:
+//	- name: default/net1
+//	  peers:
+//	  - cidr: 10.0.0.1/32
+//	    ports:
+//	    - protocol: UDP
+//	      port: 53
+type Allowlist struct {
+	Networks []AllowlistNetwork `json:"networks"`
+}
+
+// AllowlistNetwork holds the allowed peers of a network
+type AllowlistNetwork struct {
+	// Name is the network name in <namespace>/<name> format
+	Name  string          `json:"name"`
+	Peers []AllowlistPeer `json:"peers"`
+}
+
+// AllowlistPeer is an allowed peer CIDR, optionally on the given ports only
+type AllowlistPeer struct {
+	CIDR  string          `json:"cidr"`
+	Ports []AllowlistPort `json:"ports,omitempty"`
+}
+
+// AllowlistPort is an allowed peer port
+type AllowlistPort struct {
+	Protocol PolicyPortProtocol `json:"protocol"`
+	Port     uint16             `json:"port"`
+}
+
+// LoadAllowlist loads and validates Allowlist from the given file path
+func LoadAllowlist(path string) (*Allowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read allowlist file")
+	}
+
+	allowlist := &Allowlist{}
+	if err = yaml.UnmarshalStrict(data, allowlist); err != nil {
+		return nil, errors.Wrap(err, "failed to parse allowlist file")
+	}
+
+	if err = allowlist.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid allowlist")
+	}
+	return allowlist, nil
+}
+
+// validate validates Allowlist
+func (a *Allowlist) validate() error {
+	for _, n := range a.Networks {
+		if len(strings.Split(n.Name, "/")) != 2 {
+			return fmt.Errorf("network name %q is not in <namespace>/<name> format", n.Name)
+		}
+		for _, p := range n.Peers {
+			if _, _, err := net.ParseCIDR(p.CIDR); err != nil {
+				return errors.Wrapf(err, "invalid peer CIDR for network %s", n.Name)
+			}
+			for _, port := range p.Ports {
+				if port.Protocol != ProtocolTCP && port.Protocol != ProtocolUDP {
+					return fmt.Errorf("unsupported port protocol %q for network %s", port.Protocol, n.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RulesForNetwork returns Allowlist Rules for the given network. Rules are generated with Global set.
+// nil is returned if the network has no allowed peers.
+func (a *Allowlist) RulesForNetwork(network string) []Rule {
+	if a == nil {
+		return nil
+	}
+
+	var rules []Rule
+	for _, n := range a.Networks {
+		if n.Name != network {
+			continue
+		}
+		for _, p := range n.Peers {
+			// Note(adrianc): peer CIDR is validated when Allowlist is loaded
+			_, ipNet, _ := net.ParseCIDR(p.CIDR)
+			ports := make([]Port, 0, len(p.Ports))
+			for _, port := range p.Ports {
+				ports = append(ports, Port{Protocol: port.Protocol, Number: port.Port})
+			}
+			rules = append(rules, Rule{
+				IPCidrs: []*net.IPNet{ipNet},
+				Ports:   ports,
+				Action:  PolicyActionPass,
+				Global:  true,
+			})
+		}
+	}
+	return rules
+}
@@ -0,0 +1,101 @@
+package policyrules_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+)
+
+var _ = Describe("Allowlist tests", func() {
+	var tmpDir string
+
+	writeAllowlist := func(content string) string {
+		path := filepath.Join(tmpDir, "allowlist.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "allowlist")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	Context("LoadAllowlist", func() {
+		It("loads allowlist and returns rules for network", func() {
+			allowlist, err := policyrules.LoadAllowlist(writeAllowlist(`
+networks:
+- name: default/net1
+  peers:
+  - cidr: 10.0.0.1/32
+    ports:
+    - protocol: UDP
+      port: 53
+    - protocol: TCP
+      port: 53
+  - cidr: 192.168.0.0/24
+- name: default/net2
+  peers:
+  - cidr: 2001::/64
+`))
+			Expect(err).ToNot(HaveOccurred())
+
+			rules := allowlist.RulesForNetwork("default/net1")
+			checkRules(rules, []policyrules.Rule{
+				{
+					IPCidrs: []*net.IPNet{{IP: net.IP{10, 0, 0, 1}, Mask: net.CIDRMask(32, 32)}},
+					Ports: []policyrules.Port{
+						{Protocol: policyrules.ProtocolUDP, Number: 53},
+						{Protocol: policyrules.ProtocolTCP, Number: 53},
+					},
+					Action: policyrules.PolicyActionPass,
+					Global: true,
+				},
+				{
+					IPCidrs: []*net.IPNet{{IP: net.IP{192, 168, 0, 0}, Mask: net.CIDRMask(24, 32)}},
+					Ports:   []policyrules.Port{},
+					Action:  policyrules.PolicyActionPass,
+					Global:  true,
+				},
+			})
+			Expect(allowlist.RulesForNetwork("default/net2")).To(HaveLen(1))
+			Expect(allowlist.RulesForNetwork("default/net3")).To(BeEmpty())
+		})
+
+		It("fails if file does not exist", func() {
+			_, err := policyrules.LoadAllowlist(filepath.Join(tmpDir, "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		expectLoadFailure := func(content string) {
+			_, err := policyrules.LoadAllowlist(writeAllowlist(content))
+			Expect(err).To(HaveOccurred())
+		}
+
+		It("fails if allowlist is invalid", func() {
+			By("unknown field")
+			expectLoadFailure("networks:\n- name: default/net1\n  cidrs: []\n")
+			By("network name without namespace")
+			expectLoadFailure("networks:\n- name: net1\n")
+			By("invalid CIDR")
+			expectLoadFailure("networks:\n- name: default/net1\n  peers:\n  - cidr: 10.0.0.1\n")
+			By("unsupported port protocol")
+			expectLoadFailure(
+				"networks:\n- name: default/net1\n  peers:\n  - cidr: 10.0.0.1/32\n    ports:\n    - protocol: SCTP\n")
+		})
+	})
+
+	It("returns no rules for nil allowlist", func() {
+		var allowlist *policyrules.Allowlist
+		Expect(allowlist.RulesForNetwork("default/net1")).To(BeNil())
+	})
+})
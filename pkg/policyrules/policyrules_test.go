@@ -27,6 +27,7 @@ func checkInterfaceInfos(rules []policyrules.PolicyRuleSet, podInterfaceInfos []
 				ExpectWithOffset(1, rule.IfcInfo.DeviceID).To(BeEquivalentTo(podInterfaceInfo.DeviceID))
 				ExpectWithOffset(1, rule.IfcInfo.InterfaceName).To(BeEquivalentTo(podInterfaceInfo.InterfaceName))
 				ExpectWithOffset(1, rule.IfcInfo.Network).To(BeEquivalentTo(podInterfaceInfo.NetattachName))
+				ExpectWithOffset(1, rule.IfcInfo.EgressBandwidth).To(Equal(podInterfaceInfo.EgressBandwidth))
 				// Ensure same IPs
 				ExpectWithOffset(1, rule.IfcInfo.IPs).To(HaveLen(len(podInterfaceInfo.IPs)))
 				for k := range rule.IfcInfo.IPs {
@@ -39,7 +40,7 @@ func checkInterfaceInfos(rules []policyrules.PolicyRuleSet, podInterfaceInfos []
 }
 
 // ruleEqual checks that this and other Rules are equal
-// limitation: it assumes IPCidrs and Ports contain no duplicate entries
+// limitation: it assumes IPCidrs, MACs and Ports contain no duplicate entries
 func ruleEqual(this, other policyrules.Rule) bool {
 	// Note(adrianc): we can probably do something more efficient here. (e.g use maps as sets)
 	if this.Action != other.Action {
@@ -50,10 +51,35 @@ func ruleEqual(this, other policyrules.Rule) bool {
 		return false
 	}
 
+	if len(this.MACs) != len(other.MACs) {
+		return false
+	}
+
 	if len(this.Ports) != len(other.Ports) {
 		return false
 	}
 
+	if this.Global != other.Global {
+		return false
+	}
+
+	if (this.DSCP == nil) != (other.DSCP == nil) || (this.DSCP != nil && *this.DSCP != *other.DSCP) {
+		return false
+	}
+
+	for _, mac := range this.MACs {
+		match := false
+		for _, otherMAC := range other.MACs {
+			if mac.String() == otherMAC.String() {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
 	for _, ip := range this.IPCidrs {
 		match := false
 		for _, otherIP := range other.IPCidrs {
@@ -129,7 +155,7 @@ var _ = Describe("Renderer tests", func() {
 	}
 
 	BeforeEach(func() {
-		renderer = policyrules.NewRendererImpl(logger)
+		renderer = policyrules.NewRendererImpl(logger, nil)
 		currentPolicies = make(controllers.PolicyMap)
 		currentPods = make(controllers.PodMap)
 		currentNamespaces = make(controllers.NamespaceMap)
@@ -223,6 +249,73 @@ var _ = Describe("Renderer tests", func() {
 					checkRules(ruleSets[0].Rules, expectedRules)
 				})
 			})
+
+			Context("with allowlist", func() {
+				allowlist := &policyrules.Allowlist{Networks: []policyrules.AllowlistNetwork{{
+					Name: "default/accel-net",
+					Peers: []policyrules.AllowlistPeer{{
+						CIDR:  "10.0.0.1/32",
+						Ports: []policyrules.AllowlistPort{{Protocol: policyrules.ProtocolUDP, Port: 53}},
+					}},
+				}}}
+				allowlistRule := policyrules.Rule{
+					IPCidrs: []*net.IPNet{{IP: net.IP{10, 0, 0, 1}, Mask: net.CIDRMask(32, 32)}},
+					Ports:   []policyrules.Port{{Protocol: policyrules.ProtocolUDP, Number: 53}},
+					Action:  policyrules.PolicyActionPass,
+					Global:  true,
+				}
+
+				BeforeEach(func() {
+					renderer = policyrules.NewRendererImpl(logger, allowlist)
+					target.Interfaces[0].NetattachName = "default/accel-net"
+				})
+
+				It("merges allowlist rules into isolated interface rule set", func() {
+					addPolicy(&testutil.PolicyDefaultDeny, "default/accel-net")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+					checkInterfaceInfos(ruleSets, target.Interfaces)
+					checkRules(ruleSets[0].Rules, []policyrules.Rule{allowlistRule})
+				})
+
+				It("does not merge allowlist rules into non isolated interface rule set", func() {
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+					Expect(ruleSets[0].Rules).To(BeNil())
+				})
+
+				It("does not merge allowlist rules of other networks", func() {
+					target.Interfaces[0].NetattachName = "default/other-net"
+					addPolicy(&testutil.PolicyDefaultDeny, "default/other-net")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+					Expect(ruleSets[0].Rules).To(BeEmpty())
+				})
+			})
+
+			Context("interface with egress bandwidth", func() {
+				It("renders rule set with egress bandwidth", func() {
+					target.Interfaces[0].EgressBandwidth = 10000000
+
+					By("no policy")
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+					checkInterfaceInfos(ruleSets, target.Interfaces)
+
+					By("default deny policy")
+					addPolicy(&testutil.PolicyDefaultDeny, "accel-net")
+					ruleSets, err = renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+					checkInterfaceInfos(ruleSets, target.Interfaces)
+				})
+			})
 		})
 
 		Describe("IPBlock single policy", func() {
@@ -263,6 +356,33 @@ var _ = Describe("Renderer tests", func() {
 					Expect(ruleSets[0].Type).To(Equal(policyrules.PolicyTypeEgress))
 					checkRules(ruleSets[0].Rules, expectedPolicyRules)
 				})
+
+				It("returns no rules with ports for peers without MACs", func() {
+					addPolicy(&testutil.PolicySelectorAsSourceWithPorts, "accel-net")
+					target.Interfaces[0].NetworkConfig = controllers.NetworkConfig{MACPeers: true}
+
+					source := testutil.NewPodInfoBuiler().
+						WithName("source-pod-1").
+						WithNamespace(testutil.SourceNamespace).
+						WithInterface(
+							"accel-net",
+							"0000:03:00.5",
+							"net1",
+							"accelerated-bridge",
+							[]string{"192.168.1.4"}).
+						WithLabels("app=source").
+						Build()
+
+					addPodInfo(source, target)
+					addNsByName("target", "source")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(ruleSets).To(HaveLen(1))
+					Expect(ruleSets[0].Rules).ToNot(BeNil())
+					Expect(ruleSets[0].Rules).To(BeEmpty())
+				})
 			})
 
 			Context("with ports", func() {
@@ -397,6 +517,69 @@ var _ = Describe("Renderer tests", func() {
 				})
 			})
 
+			Context("with egress dscp annotation", func() {
+				var policy *multiv1beta1.MultiNetworkPolicy
+
+				BeforeEach(func() {
+					policy = testutil.PolicyIPBlockWithMultipeRules.DeepCopy()
+				})
+
+				dscpOf := func(rules []policyrules.Rule) map[string]*uint8 {
+					dscps := make(map[string]*uint8)
+					for _, r := range rules {
+						if r.Action == policyrules.PolicyActionPass {
+							dscps[r.IPCidrs[0].String()] = r.DSCP
+						} else {
+							Expect(r.DSCP).To(BeNil())
+						}
+					}
+					return dscps
+				}
+
+				It("sets DSCP on pass rules of all egress rules", func() {
+					policy.Annotations = map[string]string{policyrules.PolicyAnnotationEgressDSCP: "46"}
+					addPolicy(policy, "accel-net")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+
+					dscps := dscpOf(ruleSets[0].Rules)
+					Expect(dscps).To(HaveLen(2))
+					for _, dscp := range dscps {
+						Expect(dscp).ToNot(BeNil())
+						Expect(*dscp).To(BeEquivalentTo(46))
+					}
+				})
+
+				It("sets DSCP on pass rules of specified egress rules", func() {
+					policy.Annotations = map[string]string{policyrules.PolicyAnnotationEgressDSCP: "1:10"}
+					addPolicy(policy, "accel-net")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+
+					dscps := dscpOf(ruleSets[0].Rules)
+					Expect(dscps["10.17.0.0/16"]).To(BeNil())
+					Expect(dscps["20.17.0.0/16"]).ToNot(BeNil())
+					Expect(*dscps["20.17.0.0/16"]).To(BeEquivalentTo(10))
+				})
+
+				It("ignores invalid annotation", func() {
+					policy.Annotations = map[string]string{policyrules.PolicyAnnotationEgressDSCP: "0:64"}
+					addPolicy(policy, "accel-net")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ruleSets).To(HaveLen(1))
+
+					for _, dscp := range dscpOf(ruleSets[0].Rules) {
+						Expect(dscp).To(BeNil())
+					}
+				})
+			})
+
 			Context("multiple peers", func() {
 				It("returns expected rules", func() {
 					addPolicy(&testutil.PolicyIPBlockWithMultipePeers, "accel-net")
@@ -535,6 +718,85 @@ var _ = Describe("Renderer tests", func() {
 				})
 			})
 
+			Context("with mac peers network", func() {
+				It("returns rules with peer MACs", func() {
+					addPolicy(&testutil.PolicySelectorAsSourceNoPorts, "accel-net")
+					target.Interfaces[0].NetworkConfig = controllers.NetworkConfig{MACPeers: true}
+
+					source1 := testutil.NewPodInfoBuiler().
+						WithName("source-pod-1").
+						WithNamespace(testutil.SourceNamespace).
+						WithInterface(
+							"accel-net",
+							"0000:03:00.5",
+							"net1",
+							"accelerated-bridge",
+							nil).
+						WithLabels("app=source").
+						Build()
+					source1.Interfaces[0].MAC = "aa:bb:cc:dd:ee:03"
+
+					// source pod without MAC
+					source2 := testutil.NewPodInfoBuiler().
+						WithName("source-pod-2").
+						WithNamespace(testutil.SourceNamespace).
+						WithInterface(
+							"accel-net",
+							"0000:03:00.6",
+							"net1",
+							"accelerated-bridge",
+							[]string{"192.168.1.4"}).
+						WithLabels("app=source").
+						Build()
+
+					addPodInfo(source1, source2, target)
+					addNsByName("target", "source")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(ruleSets).To(HaveLen(1))
+					checkInterfaceInfos(ruleSets, target.Interfaces)
+					Expect(ruleSets[0].IfcInfo.NetworkConfig.MACPeers).To(BeTrue())
+
+					expectedPolicyRules := []policyrules.Rule{
+						{
+							MACs:   []net.HardwareAddr{{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x03}},
+							Ports:  []policyrules.Port{},
+							Action: policyrules.PolicyActionPass,
+						},
+					}
+					checkRules(ruleSets[0].Rules, expectedPolicyRules)
+				})
+
+				It("returns no rules with ports for peers without MACs", func() {
+					addPolicy(&testutil.PolicySelectorAsSourceWithPorts, "accel-net")
+					target.Interfaces[0].NetworkConfig = controllers.NetworkConfig{MACPeers: true}
+
+					source := testutil.NewPodInfoBuiler().
+						WithName("source-pod-1").
+						WithNamespace(testutil.SourceNamespace).
+						WithInterface(
+							"accel-net",
+							"0000:03:00.5",
+							"net1",
+							"accelerated-bridge",
+							[]string{"192.168.1.4"}).
+						WithLabels("app=source").
+						Build()
+
+					addPodInfo(source, target)
+					addNsByName("target", "source")
+
+					ruleSets, err := renderer.RenderEgress(target, currentPolicies, currentPods, currentNamespaces)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(ruleSets).To(HaveLen(1))
+					Expect(ruleSets[0].Rules).ToNot(BeNil())
+					Expect(ruleSets[0].Rules).To(BeEmpty())
+				})
+			})
+
 			Context("with ports", func() {
 				It("returns correct rules for single pod interface", func() {
 					addPolicy(&testutil.PolicySelectorAsSourceWithPorts, "accel-net")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers"
 	multiutils "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
@@ -40,11 +41,14 @@ type Renderer interface {
 // RendererImpl implements Renderer Interface
 type RendererImpl struct {
 	log klog.Logger
+	// allowlist is the node-wide Allowlist, nil if not configured
+	allowlist *Allowlist
 }
 
-// NewRendererImpl creates a new instance of Renderer implementation
-func NewRendererImpl(log klog.Logger) *RendererImpl {
-	return &RendererImpl{log: log}
+// NewRendererImpl creates a new instance of Renderer implementation. allowlist Rules are merged
+// into every isolated interface PolicyRuleSet of the network, allowlist may be nil.
+func NewRendererImpl(log klog.Logger, allowlist *Allowlist) *RendererImpl {
+	return &RendererImpl{log: log, allowlist: allowlist}
 }
 
 // RenderEgress implements Renderer Interface
@@ -101,14 +105,23 @@ func (r *RendererImpl) RenderEgress(target *controllers.PodInfo,
 		}
 	}
 
+	// merge allowlist rules into rule sets of isolated interfaces
+	for uid, ruleSet := range policyRulesMap {
+		ruleSet.Rules = append(ruleSet.Rules, r.allowlist.RulesForNetwork(ruleSet.IfcInfo.Network)...)
+		policyRulesMap[uid] = ruleSet
+	}
+
 	// iterate over target interfaces and append empty rule set if no policy applied
 	for _, ifc := range target.Interfaces {
 		emptyPolicyRuleSet := PolicyRuleSet{
 			IfcInfo: InterfaceInfo{
-				Network:       ifc.NetattachName,
-				InterfaceName: ifc.InterfaceName,
-				IPs:           multiutils.IPsFromStrings(ifc.IPs),
-				DeviceID:      ifc.DeviceID,
+				Network:         ifc.NetattachName,
+				InterfaceName:   ifc.InterfaceName,
+				IPs:             multiutils.IPsFromStrings(ifc.IPs),
+				MAC:             multiutils.MACFromString(ifc.MAC),
+				DeviceID:        ifc.DeviceID,
+				NetworkConfig:   networkConfigFromInterface(ifc),
+				EgressBandwidth: ifc.EgressBandwidth,
 			},
 			Type:  PolicyTypeEgress,
 			Rules: nil,
@@ -128,6 +141,18 @@ func (r *RendererImpl) RenderEgress(target *controllers.PodInfo,
 	return policyRules, nil
 }
 
+// networkConfigFromInterface returns NetworkConfig of the network of ifc
+func networkConfigFromInterface(ifc controllers.InterfaceInfo) NetworkConfig {
+	return NetworkConfig{
+		StrictMode:   ifc.NetworkConfig.StrictMode,
+		AntiSpoofing: ifc.NetworkConfig.AntiSpoofing,
+		MACPeers:     ifc.NetworkConfig.MACPeers,
+		VlanIDs:      ifc.NetworkConfig.VlanIDs,
+		VlanMode:     string(ifc.NetworkConfig.VlanMode),
+		TCGenerator:  ifc.NetworkConfig.TCGenerator,
+	}
+}
+
 // renderEgressForInterface renders egress policyRuleSet for given interface and given policy
 func (r *RendererImpl) renderEgressForInterface(targetInterface controllers.InterfaceInfo,
 	policy controllers.PolicyInfo,
@@ -135,17 +160,27 @@ func (r *RendererImpl) renderEgressForInterface(targetInterface controllers.Inte
 	currentNamespaces controllers.NamespaceMap) PolicyRuleSet {
 	policyRuleSet := PolicyRuleSet{
 		IfcInfo: InterfaceInfo{
-			Network:       targetInterface.NetattachName,
-			InterfaceName: targetInterface.InterfaceName,
-			IPs:           multiutils.IPsFromStrings(targetInterface.IPs),
-			DeviceID:      targetInterface.DeviceID,
+			Network:         targetInterface.NetattachName,
+			InterfaceName:   targetInterface.InterfaceName,
+			IPs:             multiutils.IPsFromStrings(targetInterface.IPs),
+			MAC:             multiutils.MACFromString(targetInterface.MAC),
+			DeviceID:        targetInterface.DeviceID,
+			NetworkConfig:   networkConfigFromInterface(targetInterface),
+			EgressBandwidth: targetInterface.EgressBandwidth,
 		},
 		Type:  PolicyTypeEgress,
 		Rules: []Rule{},
 	}
 
+	dscpForRule, err := egressDSCPFromPolicy(policy)
+	if err != nil {
+		r.log.Error(err, "failed to parse policy egress DSCP annotation, ignoring",
+			"policy", types.NamespacedName{Namespace: policy.Namespace(), Name: policy.Name()})
+	}
+
 	// iterate over to fields
-	for _, egressPolicyRule := range policy.Policy.Spec.Egress {
+	for idx, egressPolicyRule := range policy.Policy.Spec.Egress {
+		ruleCount := len(policyRuleSet.Rules)
 		ports := r.getPorts(egressPolicyRule.Ports)
 		for _, peer := range egressPolicyRule.To {
 			// Note(adrianc): an all nil MultiNetworkPolicyPeer is skipped as it assumes to be invalid
@@ -160,7 +195,8 @@ func (r *RendererImpl) renderEgressForInterface(targetInterface controllers.Inte
 			} else if peer.PodSelector != nil || peer.NamespaceSelector != nil {
 				// handle pod/ns selectors
 				rules := r.renderRulesWithSelectors(peer.PodSelector, peer.NamespaceSelector, ports, currentPods,
-					currentNamespaces, targetInterface.NetattachName, policy.Namespace())
+					currentNamespaces, targetInterface.NetattachName, policy.Namespace(),
+					targetInterface.NetworkConfig.MACPeers)
 				if len(rules) > 0 {
 					policyRuleSet.Rules = append(policyRuleSet.Rules, rules...)
 				}
@@ -176,18 +212,80 @@ func (r *RendererImpl) renderEgressForInterface(targetInterface controllers.Inte
 				Action: PolicyActionPass,
 			})
 		}
+
+		// set DSCP for pass rules rendered from this egress rule
+		if dscp, ok := dscpForRule[idx]; ok {
+			for i := ruleCount; i < len(policyRuleSet.Rules); i++ {
+				if policyRuleSet.Rules[i].Action == PolicyActionPass {
+					dscp := dscp
+					policyRuleSet.Rules[i].DSCP = &dscp
+				}
+			}
+		}
 	}
 	return policyRuleSet
 }
 
-// renderRulesWithSelectors renders rules for pod/ns Peers
+// egressDSCPFromPolicy returns a map of egress rule index to DSCP value according to PolicyAnnotationEgressDSCP
+// annotation of the given policy. an empty map is returned if policy has no such annotation.
+func egressDSCPFromPolicy(policy controllers.PolicyInfo) (map[int]uint8, error) {
+	dscpForRule := make(map[int]uint8)
+	val, ok := policy.Policy.Annotations[PolicyAnnotationEgressDSCP]
+	if !ok {
+		return dscpForRule, nil
+	}
+
+	parseDSCP := func(s string) (uint8, error) {
+		dscp, err := strconv.ParseUint(strings.TrimSpace(s), 0, 8)
+		if err != nil {
+			return 0, err
+		}
+		if dscp > uint64(DSCPMax) {
+			return 0, fmt.Errorf("DSCP value out of range: %d", dscp)
+		}
+		return uint8(dscp), nil
+	}
+
+	if !strings.Contains(val, ":") {
+		// single value for all egress rules
+		dscp, err := parseDSCP(val)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range policy.Policy.Spec.Egress {
+			dscpForRule[idx] = dscp
+		}
+		return dscpForRule, nil
+	}
+
+	for _, entry := range strings.Split(val, ",") {
+		idxAndDSCP := strings.SplitN(entry, ":", 2)
+		if len(idxAndDSCP) != 2 {
+			return nil, fmt.Errorf("invalid entry: %s", entry)
+		}
+		idx, err := strconv.Atoi(strings.TrimSpace(idxAndDSCP[0]))
+		if err != nil {
+			return nil, err
+		}
+		dscp, err := parseDSCP(idxAndDSCP[1])
+		if err != nil {
+			return nil, err
+		}
+		dscpForRule[idx] = dscp
+	}
+	return dscpForRule, nil
+}
+
+// renderRulesWithSelectors renders rules for pod/ns Peers. if macPeers is set, peers are rendered to their
+// MAC addresses instead of their IPs
 func (r *RendererImpl) renderRulesWithSelectors(podSel *metav1.LabelSelector,
 	nsSel *metav1.LabelSelector,
 	ports []Port,
 	currentPods controllers.PodMap,
 	currentNamespaces controllers.NamespaceMap,
 	networkName string,
-	policyNamespace string) []Rule {
+	policyNamespace string,
+	macPeers bool) []Rule {
 	rules := []Rule{}
 
 	if podSel == nil && nsSel == nil {
@@ -254,6 +352,10 @@ func (r *RendererImpl) renderRulesWithSelectors(podSel *metav1.LabelSelector,
 		}
 	}
 
+	if macPeers {
+		return r.renderRulesWithPeerMACs(matchingPodsAndNs, ports, networkName)
+	}
+
 	// 	collect IPs for network
 	var ipCidrs []*net.IPNet
 	for _, podInfo := range matchingPodsAndNs {
@@ -288,6 +390,38 @@ func (r *RendererImpl) renderRulesWithSelectors(podSel *metav1.LabelSelector,
 	return rules
 }
 
+// renderRulesWithPeerMACs renders rules for the MAC addresses of the provided peer pods interfaces on the given network
+func (r *RendererImpl) renderRulesWithPeerMACs(peerPods []controllers.PodInfo, ports []Port,
+	networkName string) []Rule {
+	rules := []Rule{}
+
+	// collect MACs for network
+	var macs []net.HardwareAddr
+	for _, podInfo := range peerPods {
+		for _, ifc := range podInfo.Interfaces {
+			if ifc.NetattachName != networkName {
+				continue
+			}
+			mac := multiutils.MACFromString(ifc.MAC)
+			if mac == nil {
+				r.log.V(4).Info("no MAC for pod interface, skipping", "pod", podInfo.Name,
+					"interface", ifc.InterfaceName, "mac", ifc.MAC)
+				continue
+			}
+			macs = append(macs, mac)
+		}
+	}
+	// add Rule with these MACs. Note(adrianc): a Rule without MACs would allow ports to all destinations
+	if len(macs) > 0 {
+		rules = append(rules, Rule{
+			MACs:   macs,
+			Ports:  ports,
+			Action: PolicyActionPass,
+		})
+	}
+	return rules
+}
+
 // renderRulesWithIPBlock renders Rules for IPBlock peer with CIDR and Except
 func (r *RendererImpl) renderRulesWithIPBlock(ipBlock *multiv1beta1.IPBlock, ports []Port) []Rule {
 	var rules []Rule
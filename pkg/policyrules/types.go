@@ -14,6 +14,13 @@ const (
 
 	ProtocolTCP PolicyPortProtocol = "TCP"
 	ProtocolUDP PolicyPortProtocol = "UDP"
+
+	// PolicyAnnotationEgressDSCP is the MultiNetworkPolicy annotation used to set DSCP of traffic allowed by
+	// the policy egress rules. value is either a DSCP value which applies to all egress rules (e.g "46")
+	// or a comma separated list of <egress rule index>:<DSCP value> (e.g "0:46,2:10")
+	PolicyAnnotationEgressDSCP = "tc.multi-networkpolicy.k8s.cni.cncf.io/egress-dscp"
+	// DSCPMax is the maximal DSCP value
+	DSCPMax = 63
 )
 
 // PolicyType is the type of policy either PolicyTypeIngress or PolicyTypeEgress
@@ -33,8 +40,30 @@ type InterfaceInfo struct {
 	InterfaceName string
 	// IPs are the IPs assigned to the interface
 	IPs []net.IP
+	// MAC is the MAC address of the interface, nil if unknown
+	MAC net.HardwareAddr
 	// DeviceID is the Device ID associated with the interface
 	DeviceID string
+	// NetworkConfig is the configuration of the network the interface is associated with
+	NetworkConfig NetworkConfig
+	// EgressBandwidth is the egress bandwidth limit of the interface in bits per second, 0 if not limited
+	EgressBandwidth uint64
+}
+
+// NetworkConfig holds the configuration of the network an interface is associated with
+type NetworkConfig struct {
+	// StrictMode if set, all traffic which is not explicitly allowed is dropped on the interface not just IP traffic
+	StrictMode bool
+	// AntiSpoofing if set, traffic sent from the interface with source address which does not belong to it is dropped
+	AntiSpoofing bool
+	// MACPeers if set, pod/namespace selector peers are rendered to Rules with MACs instead of IPCidrs
+	MACPeers bool
+	// VlanIDs are the VLAN IDs used by the network, empty if network has no VLAN configuration
+	VlanIDs []uint16
+	// VlanMode is the VLAN mode of the network ("tagged" or "untagged"), empty if not configured
+	VlanMode string
+	// TCGenerator is the name of the TC generator of the network, empty if not configured
+	TCGenerator string
 }
 
 // GetUID returns a unique ID for InterfaceInfo in the following format:
@@ -53,8 +82,14 @@ type Port struct {
 // Rule represents a single Policy Rule
 type Rule struct {
 	IPCidrs []*net.IPNet
-	Ports   []Port
-	Action  PolicyAction
+	// MACs are peer MAC addresses, a Rule has either IPCidrs or MACs
+	MACs   []net.HardwareAddr
+	Ports  []Port
+	Action PolicyAction
+	// DSCP is the DSCP value set on traffic matching the Rule, only valid for PolicyActionPass. nil if not set
+	DSCP *uint8
+	// Global is set for Rules of the node-wide Allowlist, only valid for PolicyActionPass
+	Global bool
 }
 
 // PolicyRuleSet holds the set of Rules of the given Type that should apply to the interface identified by IfcInfo
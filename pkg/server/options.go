@@ -2,6 +2,8 @@ package server
 
 import (
 	"flag"
+	"fmt"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"k8s.io/client-go/rest"
@@ -25,6 +27,20 @@ type Options struct {
 	networkPlugins   []string
 	podRulesPath     string
 	tcDriver         string
+	tcGenerator      string
+	controlTraffic   []string
+	strictMode       bool
+	stateful         bool
+	ipFragments      string
+	multicastMAC     bool
+	allowlistPath    string
+	rulePriorities   bool
+	vlanMode         string
+	chainTemplates   bool
+	clsact           bool
+	repFilterBudget  int
+	nodeFilterBudget int
+	budgetOverflow   string
 
 	// below here, used for testing purposes, leave empty otherwise
 	createActuatorForRep func(string) (tc.Actuator, error)
@@ -50,9 +66,76 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 		"If non-empty, will use this path to store pod's rules for troubleshooting.")
 	fs.StringVar(&o.tcDriver, "tc-driver", "cmdline",
 		"TC driver to use for interacting with linux Traffic Class subsystem. [cmdline, netlink].")
+	fs.StringVar(&o.tcGenerator, "tc-generator", "simple",
+		"TC generator to use for generating TC filters from policy rules. [simple, chain, u32].")
+	fs.StringSliceVar(&o.controlTraffic, "control-traffic", o.controlTraffic,
+		"List of essential control traffic types to always allow on isolated interfaces. "+
+			"[nd, dhcp, arp, igmp, mld, broadcast].")
+	fs.BoolVar(&o.strictMode, "strict-mode", o.strictMode,
+		"If set, drop all traffic which is not explicitly allowed on isolated interfaces, not only IP traffic.")
+	fs.BoolVar(&o.stateful, "stateful", o.stateful,
+		"If set, use connection tracking on isolated interfaces to allow established traffic of allowed connections.")
+	fs.StringVar(&o.ipFragments, "ip-fragments", o.ipFragments,
+		"If non-empty, policy for IP fragments on isolated interfaces. [allow, drop].")
+	fs.BoolVar(&o.multicastMAC, "multicast-mac", o.multicastMAC,
+		"If set, rules with multicast or broadcast destination IPs match multicast or broadcast destination MAC as well.")
+	fs.StringVar(&o.allowlistPath, "allowlist", o.allowlistPath,
+		"If non-empty, path to a file with peers which are always allowed on isolated interfaces per network.")
+	fs.BoolVar(&o.rulePriorities, "rule-priorities", o.rulePriorities,
+		"If set, generate filters of each policy rule at a dedicated priority which is kept across syncs.")
+	fs.StringVar(&o.vlanMode, "vlan-mode", o.vlanMode,
+		"If non-empty, VLAN mode of networks which do not configure a VLAN mode. [tagged, untagged].")
+	fs.BoolVar(&o.chainTemplates, "chain-templates", o.chainTemplates,
+		"If set, group filters which match on the same mask in dedicated chains declared with a chain template.")
+	fs.BoolVar(&o.clsact, "clsact", o.clsact,
+		"If set, attach filters to the ingress hook of a clsact qdisc instead of an ingress qdisc.")
+	fs.IntVar(&o.repFilterBudget, "rep-filter-budget", o.repFilterBudget,
+		"If non-zero, maximal number of hardware offloaded filters per VF representor.")
+	fs.IntVar(&o.nodeFilterBudget, "node-filter-budget", o.nodeFilterBudget,
+		"If non-zero, maximal number of hardware offloaded filters of all VF representors on the node.")
+	fs.StringVar(&o.budgetOverflow, "filter-budget-overflow", "refuse",
+		"Policy applied to VF representors whose filters exceed the filter budget. [refuse, software, default-deny].")
 	fs.AddGoFlagSet(flag.CommandLine)
 }
 
+// validateTCDriver returns an error if options which generate filters that cannot be applied with the
+// netlink TC driver are set while netlink TC driver is selected
+func (o *Options) validateTCDriver() error {
+	if o.tcDriver != "netlink" {
+		return nil
+	}
+
+	unsupported := make([]string, 0)
+	for _, ct := range o.controlTraffic {
+		switch generator.ControlTrafficType(strings.ToLower(strings.TrimSpace(ct))) {
+		case generator.ControlTrafficND, generator.ControlTrafficMLD, generator.ControlTrafficBroadcast:
+			unsupported = append(unsupported, "--control-traffic="+ct)
+		}
+	}
+	flags := []struct {
+		name  string
+		isSet bool
+	}{
+		{"--stateful", o.stateful},
+		{"--ip-fragments", o.ipFragments != ""},
+		{"--multicast-mac", o.multicastMAC},
+		{"--chain-templates", o.chainTemplates},
+		// u32 and matchall filters cannot be applied with skip_hw
+		{"--filter-budget-overflow=software", (o.repFilterBudget > 0 || o.nodeFilterBudget > 0) &&
+			tc.OverflowPolicy(strings.ToLower(o.budgetOverflow)) == tc.OverflowPolicySoftware},
+	}
+	for _, f := range flags {
+		if f.isSet {
+			unsupported = append(unsupported, f.name)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s not supported with netlink TC driver", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
 // NewOptions initializes Options
 func NewOptions() *Options {
 	return &Options{}
@@ -93,9 +93,13 @@ type Server struct {
 
 	policyRuleRenderer      policyrules.Renderer
 	tcRuleGenerator         generator.Generator
+	tcGenerators            map[string]generator.Generator
 	sriovnetProvider        netwrappers.SriovnetProvider
 	netlinkProvider         netwrappers.NetlinkProvider
 	createActuatorFromRepFn func(string) (tc.Actuator, error)
+	filterBudget            *tc.FilterBudget
+	// filterBudgetSeeded is set once filter budget usage was seeded from the filters applied before startup
+	filterBudgetSeeded bool
 }
 
 func (s *Server) RunPodConfig(ctx context.Context) {
@@ -200,6 +204,10 @@ func NewServer(o *Options) (*Server, error) {
 	var kubeConfig *rest.Config
 	var err error
 
+	if err = o.validateTCDriver(); err != nil {
+		return nil, err
+	}
+
 	switch {
 	case o.KConfig != nil:
 		kubeConfig = o.KConfig
@@ -269,11 +277,51 @@ func NewServer(o *Options) (*Server, error) {
 	podChanges := controllers.NewPodChangeTracker(o.networkPlugins, netdefChanges)
 
 	if o.policyRuleRenderer == nil {
-		o.policyRuleRenderer = policyrules.NewRendererImpl(klog.NewKlogr().WithName("policy-rule-renderer"))
+		var allowlist *policyrules.Allowlist
+		if o.allowlistPath != "" {
+			allowlist, err = policyrules.LoadAllowlist(o.allowlistPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		o.policyRuleRenderer = policyrules.NewRendererImpl(
+			klog.NewKlogr().WithName("policy-rule-renderer"), allowlist)
 	}
 
+	var tcGenerators map[string]generator.Generator
 	if o.tcRuleGenerator == nil {
-		o.tcRuleGenerator = generator.NewSimpleTCGenerator()
+		controlTraffic := make([]generator.ControlTrafficType, 0, len(o.controlTraffic))
+		for _, ct := range o.controlTraffic {
+			t, err := generator.ControlTrafficTypeFromString(ct)
+			if err != nil {
+				return nil, err
+			}
+			controlTraffic = append(controlTraffic, t)
+		}
+		fragments, err := generator.FragmentsPolicyFromString(o.ipFragments)
+		if err != nil {
+			return nil, err
+		}
+		vlanMode, err := controllers.VlanModeFromString(o.vlanMode)
+		if err != nil {
+			return nil, err
+		}
+		genOpts := generator.Options{
+			ControlTraffic: controlTraffic,
+			StrictMode:     o.strictMode,
+			Stateful:       o.stateful,
+			Fragments:      fragments,
+			MulticastMAC:   o.multicastMAC,
+			RulePriorities: o.rulePriorities,
+			VlanMode:       vlanMode,
+			ChainTemplates: o.chainTemplates,
+			Clsact:         o.clsact,
+		}
+		tcGenerators = newTCGenerators(genOpts)
+		var ok bool
+		if o.tcRuleGenerator, ok = tcGenerators[o.tcGenerator]; !ok {
+			return nil, fmt.Errorf("unknown TC generator: %s", o.tcGenerator)
+		}
 	}
 
 	if o.sriovnetProvider == nil {
@@ -284,6 +332,15 @@ func NewServer(o *Options) (*Server, error) {
 		o.netlinkProvider = netwrappers.NewNetlinkProviderImpl()
 	}
 
+	var filterBudget *tc.FilterBudget
+	if o.repFilterBudget > 0 || o.nodeFilterBudget > 0 {
+		overflow, err := tc.OverflowPolicyFromString(o.budgetOverflow)
+		if err != nil {
+			return nil, err
+		}
+		filterBudget = tc.NewFilterBudget(o.repFilterBudget, o.nodeFilterBudget, overflow)
+	}
+
 	server := &Server{
 		Options:             o,
 		Client:              client,
@@ -305,9 +362,11 @@ func NewServer(o *Options) (*Server, error) {
 
 		policyRuleRenderer:      o.policyRuleRenderer,
 		tcRuleGenerator:         o.tcRuleGenerator,
+		tcGenerators:            tcGenerators,
 		sriovnetProvider:        o.sriovnetProvider,
 		netlinkProvider:         o.netlinkProvider,
 		createActuatorFromRepFn: o.createActuatorForRep,
+		filterBudget:            filterBudget,
 	}
 
 	if server.createActuatorFromRepFn == nil {
@@ -508,7 +567,10 @@ func (s *Server) syncMultiPolicy() {
 	s.policyMap.Update(s.policyChanges)
 
 	podsInfo, _ := s.podMap.List()
+	s.seedFilterBudget(podsInfo)
 	podsWithRules := make(map[string]struct{})
+	repsWithRules := make(map[string]struct{})
+	generatedIfcs := make(map[generator.Generator][]policyrules.InterfaceInfo)
 	for _, p := range podsInfo {
 		podNamespacedName := types.NamespacedName{Namespace: p.Namespace, Name: p.Name}.String()
 		// skip pods that are not scheduled on this node
@@ -545,6 +607,12 @@ func (s *Server) syncMultiPolicy() {
 			klog.InfoS("processing policy rule set for pod",
 				"network", ruleSet.IfcInfo.Network, "interface", ruleSet.IfcInfo.InterfaceName)
 
+			if err = s.validateNetworkConfig(ruleSet.IfcInfo.NetworkConfig); err != nil {
+				klog.ErrorS(err, "Unsupported network configuration. skipping.", "network", ruleSet.IfcInfo.Network)
+				continue
+			}
+			ruleSet = s.withSupportedRules(ruleSet)
+
 			// get VF rep
 			rep, err := s.getRepresentor(ruleSet.IfcInfo.DeviceID)
 			if err != nil {
@@ -554,37 +622,232 @@ func (s *Server) syncMultiPolicy() {
 			}
 
 			// Generate TC rules for ruleSet
-			tcObjs, err := s.tcRuleGenerator.GenerateFromPolicyRuleSet(ruleSet)
+			tcGenerator := s.generatorFor(ruleSet)
+			generatedIfcs[tcGenerator] = append(generatedIfcs[tcGenerator], ruleSet.IfcInfo)
+			tcObjs, err := tcGenerator.GenerateFromPolicyRuleSet(ruleSet)
 			if err != nil {
 				klog.ErrorS(err, "Failed to generate tc rules. skipping.")
 				continue
 			}
 			klog.V(5).Infof("tcObjs: %+v", tcObjs)
+			repsWithRules[rep] = struct{}{}
 
-			// Actuate TC rules
-			actuator, err := s.createActuatorFromRepFn(rep)
-			if err != nil {
-				klog.ErrorS(err, "Failed to create actuator. skipping.")
+			// Actuate TC rules and optionally save them to file
+			if err = s.actuateRuleSet(podInfo, ruleSet, rep, tcObjs); err != nil {
+				klog.ErrorS(err, "Failed to actuate rules. skipping.")
 				continue
 			}
+		}
+	}
+
+	s.deleteStalePodInterfaceRules(podsWithRules)
+	if s.filterBudget != nil {
+		s.filterBudget.Retain(repsWithRules)
+	}
+	s.retainGeneratorInterfaces(generatedIfcs)
+}
+
+// retainGeneratorInterfaces releases the state kept by each TC generator for interfaces which are not in its
+// generated interfaces of the last sync (e.g interfaces of deleted pods)
+func (s *Server) retainGeneratorInterfaces(generatedIfcs map[generator.Generator][]policyrules.InterfaceInfo) {
+	generators := map[generator.Generator]struct{}{s.tcRuleGenerator: {}}
+	for _, g := range s.tcGenerators {
+		generators[g] = struct{}{}
+	}
+	for g := range generators {
+		g.RetainInterfaces(generatedIfcs[g])
+	}
+}
+
+// validateNetworkConfig returns an error if netConf requires filters which cannot be applied with the selected
+// TC driver
+func (s *Server) validateNetworkConfig(netConf policyrules.NetworkConfig) error {
+	if s.Options.tcDriver != "netlink" {
+		return nil
+	}
+
+	switch {
+	case netConf.AntiSpoofing:
+		return fmt.Errorf("anti-spoofing network configuration not supported with netlink TC driver")
+	case netConf.MACPeers:
+		return fmt.Errorf("mac-peers network configuration not supported with netlink TC driver")
+	case len(netConf.VlanIDs) > 0:
+		return fmt.Errorf("VLAN network configuration not supported with netlink TC driver")
+	}
+	return nil
+}
+
+// withSupportedRules returns ruleSet without DSCP marking of its rules if DSCP marking cannot be applied with the
+// selected TC driver. rules with DSCP marking are applied as regular pass rules.
+func (s *Server) withSupportedRules(ruleSet policyrules.PolicyRuleSet) policyrules.PolicyRuleSet {
+	if s.Options.tcDriver != "netlink" || ruleSet.Rules == nil {
+		return ruleSet
+	}
+
+	rules := make([]policyrules.Rule, 0, len(ruleSet.Rules))
+	for _, rule := range ruleSet.Rules {
+		if rule.DSCP != nil {
+			klog.Warningf("DSCP marking not supported with netlink TC driver, ignoring DSCP of rule on network %s",
+				ruleSet.IfcInfo.Network)
+			rule.DSCP = nil
+		}
+		rules = append(rules, rule)
+	}
+	ruleSet.Rules = rules
+	return ruleSet
+}
 
-			err = actuator.Actuate(tcObjs)
+// newTCGenerators returns the TC generators created with opts by their name
+func newTCGenerators(opts generator.Options) map[string]generator.Generator {
+	return map[string]generator.Generator{
+		"simple": generator.NewSimpleTCGenerator(opts),
+		"chain":  generator.NewChainTCGenerator(opts),
+		"u32":    generator.NewU32TCGenerator(opts),
+	}
+}
+
+// generatorFor returns the TC generator selected by the network of ruleSet. the default TC generator is returned
+// if the network does not select a TC generator or selects an unknown one.
+func (s *Server) generatorFor(ruleSet policyrules.PolicyRuleSet) generator.Generator {
+	name := ruleSet.IfcInfo.NetworkConfig.TCGenerator
+	if name == "" {
+		return s.tcRuleGenerator
+	}
+	if g, ok := s.tcGenerators[name]; ok {
+		return g
+	}
+	klog.Warningf("unknown TC generator %s selected by network %s, using default TC generator",
+		name, ruleSet.IfcInfo.Network)
+	return s.tcRuleGenerator
+}
+
+// actuateRuleSet applies tcObjs generated for ruleSet of pod on rep within the filter budget (see enforceFilterBudget)
+// and optionally saves the applied objects to file.
+func (s *Server) actuateRuleSet(pInfo *controllers.PodInfo, ruleSet policyrules.PolicyRuleSet, rep string,
+	tcObjs *generator.Objects) error {
+	tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, rep, tcObjs)
+	if err != nil {
+		return errors.Wrap(err, "failed to enforce filter budget")
+	}
+	if tcObjs == nil {
+		klog.InfoS("filter budget exceeded, keeping previous rules")
+		return nil
+	}
+
+	actuator, err := s.createActuatorFromRepFn(rep)
+	if err != nil {
+		return errors.Wrap(err, "failed to create actuator")
+	}
+
+	if err = actuator.Actuate(tcObjs); err != nil {
+		return err
+	}
+	klog.InfoS("rules set applied successfully for pod")
+
+	if s.filterBudget != nil {
+		s.filterBudget.Commit(rep, tcObjs)
+	}
+
+	// optionally save rules to file
+	if err = s.savePodInterfaceRules(pInfo, ruleSet, tcObjs, rep); err != nil {
+		klog.Warningf("failed to save pod interface rules. %v", err)
+	}
+	return nil
+}
+
+// enforceFilterBudget checks tcObjs generated for ruleSet against the filter budget of rep. if the budget is exceeded
+// the overflow policy of the budget is applied and an event is recorded for the pod. it returns the objects
+// to apply on rep, or nil if the previously applied objects should be kept. objects generated with no policy rules
+// are returned instead of nil if no objects were applied on rep.
+func (s *Server) enforceFilterBudget(pInfo *controllers.PodInfo, ruleSet policyrules.PolicyRuleSet, rep string,
+	tcObjs *generator.Objects) (*generator.Objects, error) {
+	if s.filterBudget == nil {
+		return tcObjs, nil
+	}
+
+	budgetErr := s.filterBudget.Check(rep, tcObjs)
+	if budgetErr == nil {
+		return tcObjs, nil
+	}
+
+	podRef := &v1.ObjectReference{
+		Kind:      "Pod",
+		Name:      pInfo.Name,
+		Namespace: pInfo.Namespace,
+		UID:       types.UID(pInfo.UID),
+	}
+	switch s.filterBudget.Overflow() {
+	case tc.OverflowPolicySoftware:
+		s.Recorder.Eventf(podRef, v1.EventTypeWarning, "FilterBudgetExceeded",
+			"%s. policy rules of interface %s are applied in software only.", budgetErr, ruleSet.IfcInfo.InterfaceName)
+		return tc.WithSoftwareOnlyFilters(tcObjs), nil
+	case tc.OverflowPolicyDefaultDeny:
+		s.Recorder.Eventf(podRef, v1.EventTypeWarning, "FilterBudgetExceeded",
+			"%s. all traffic of interface %s is denied.", budgetErr, ruleSet.IfcInfo.InterfaceName)
+		return s.defaultDenyObjects(ruleSet, rep)
+	default:
+		if !s.filterBudget.Applied(rep) {
+			// no previous policy rules to keep, interface is not left unrestricted
+			s.Recorder.Eventf(podRef, v1.EventTypeWarning, "FilterBudgetExceeded",
+				"%s. no previous policy rules of interface %s, all traffic is denied.", budgetErr,
+				ruleSet.IfcInfo.InterfaceName)
+			return s.defaultDenyObjects(ruleSet, rep)
+		}
+		s.Recorder.Eventf(podRef, v1.EventTypeWarning, "FilterBudgetExceeded",
+			"%s. previous policy rules of interface %s are kept.", budgetErr, ruleSet.IfcInfo.InterfaceName)
+		return nil, nil
+	}
+}
+
+// seedFilterBudget records the filters applied on the representors of pods on the node as their filter budget
+// usage. it is done once, on the first sync, so that filters applied before startup are accounted for and kept
+// by the refuse overflow policy.
+func (s *Server) seedFilterBudget(pods []controllers.PodInfo) {
+	if s.filterBudget == nil || s.filterBudgetSeeded {
+		return
+	}
+	s.filterBudgetSeeded = true
+
+	for _, p := range pods {
+		if !multiutils.CheckNodeNameIdentical(s.Hostname, p.NodeName) {
+			continue
+		}
+		for _, ifc := range p.Interfaces {
+			rep, err := s.getRepresentor(ifc.DeviceID)
+			if err != nil || s.filterBudget.Applied(rep) {
+				continue
+			}
+			actuator, err := s.createActuatorFromRepFn(rep)
 			if err != nil {
-				klog.ErrorS(err, "Failed to actuate rules. skipping.")
+				klog.ErrorS(err, "Failed to create actuator, filter budget usage not seeded", "rep", rep)
 				continue
 			}
-			klog.InfoS("rules set applied successfully for pod")
-
-			// optionally save rules to file
-			err = s.savePodInterfaceRules(podInfo, ruleSet, tcObjs, rep)
+			filters, err := actuator.FilterList()
 			if err != nil {
-				klog.Warningf("failed to save pod interface rules. %v", err)
+				klog.ErrorS(err, "Failed to list filters, filter budget usage not seeded", "rep", rep)
 				continue
 			}
+			if len(filters) > 0 {
+				s.filterBudget.Commit(rep, &generator.Objects{Filters: filters})
+			}
 		}
 	}
+}
 
-	s.deleteStalePodInterfaceRules(podsWithRules)
+// defaultDenyObjects returns the objects generated for ruleSet with no policy rules, i.e all traffic except for
+// control traffic is denied. the objects are checked against the filter budget of rep, if they exceed it as well
+// their filters are applied in software only.
+func (s *Server) defaultDenyObjects(ruleSet policyrules.PolicyRuleSet, rep string) (*generator.Objects, error) {
+	ruleSet.Rules = []policyrules.Rule{}
+	tcObjs, err := s.generatorFor(ruleSet).GenerateFromPolicyRuleSet(ruleSet)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate default deny tc rules")
+	}
+	if budgetErr := s.filterBudget.Check(rep, tcObjs); budgetErr != nil {
+		klog.InfoS("default deny rules exceed filter budget, applying in software only", "reason", budgetErr.Error())
+		return tc.WithSoftwareOnlyFilters(tcObjs), nil
+	}
+	return tcObjs, nil
 }
 
 // savePodInterfaceRules saves pod interface tc objects to file if podRulesPath option is enabled in server
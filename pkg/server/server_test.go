@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -12,17 +13,21 @@ import (
 	"github.com/vishvananda/netlink"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers/testutil"
 	netmocks "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/net/mocks"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
 	policymocks "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules/mocks"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
 	generatorMocks "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator/mocks"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/mocks"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 )
 
 // fakeLink is a dummy netlink struct used during testing
@@ -56,6 +61,18 @@ func lenOfCalls(m *mock.Mock) func() int {
 	}
 }
 
+func lenOfMethodCalls(m *mock.Mock, method string) func() int {
+	return func() int {
+		n := 0
+		for _, call := range m.Calls {
+			if call.Method == method {
+				n++
+			}
+		}
+		return n
+	}
+}
+
 var _ = Describe("Server test", func() {
 	var testServer *Server
 	var runCtx context.Context
@@ -71,6 +88,7 @@ var _ = Describe("Server test", func() {
 		mockActuator = &mocks.Actuator{}
 		mockRenderer = &policymocks.Renderer{}
 		mockRuleGenerator = &generatorMocks.Generator{}
+		mockRuleGenerator.On("RetainInterfaces", mock.Anything).Return()
 		mockSriovnetProvider = &netmocks.SriovnetProvider{}
 
 		o := &Options{
@@ -227,7 +245,7 @@ var _ = Describe("Server test", func() {
 			Eventually(lenOfCalls(&mockSriovnetProvider.Mock)).
 				WithTimeout(5 * time.Second).
 				Should(BeNumerically(">=", 1))
-			Eventually(lenOfCalls(&mockRuleGenerator.Mock)).
+			Eventually(lenOfMethodCalls(&mockRuleGenerator.Mock, "GenerateFromPolicyRuleSet")).
 				WithTimeout(5 * time.Second).
 				Should(BeNumerically(">=", 1))
 			Eventually(lenOfCalls(&mockActuator.Mock)).
@@ -281,3 +299,193 @@ var _ = Describe("Server createActuatorForRep test", func() {
 		Expect(err).To(HaveOccurred())
 	})
 })
+
+var _ = Describe("Server TC driver validation test", func() {
+	It("accepts options which require cmdline TC driver with cmdline TC driver", func() {
+		o := &Options{tcDriver: "cmdline", controlTraffic: []string{"nd", "mld", "broadcast"}, stateful: true,
+			ipFragments: "drop", multicastMAC: true, chainTemplates: true, repFilterBudget: 10, budgetOverflow: "software"}
+		Expect(o.validateTCDriver()).To(Succeed())
+	})
+
+	It("rejects options which require cmdline TC driver with netlink TC driver", func() {
+		for _, o := range []*Options{
+			{tcDriver: "netlink", controlTraffic: []string{"arp", "ND"}},
+			{tcDriver: "netlink", controlTraffic: []string{"mld"}},
+			{tcDriver: "netlink", controlTraffic: []string{"broadcast"}},
+			{tcDriver: "netlink", stateful: true},
+			{tcDriver: "netlink", ipFragments: "allow"},
+			{tcDriver: "netlink", multicastMAC: true},
+			{tcDriver: "netlink", chainTemplates: true},
+			{tcDriver: "netlink", repFilterBudget: 10, budgetOverflow: "software"},
+			{tcDriver: "netlink", nodeFilterBudget: 10, budgetOverflow: "Software"},
+		} {
+			Expect(o.validateTCDriver()).ToNot(Succeed())
+		}
+		o := &Options{tcDriver: "netlink", controlTraffic: []string{"arp", "dhcp", "igmp"}, strictMode: true}
+		Expect(o.validateTCDriver()).To(Succeed())
+		o = &Options{tcDriver: "netlink", repFilterBudget: 10, budgetOverflow: "default-deny"}
+		Expect(o.validateTCDriver()).To(Succeed())
+		o = &Options{tcDriver: "netlink", budgetOverflow: "software"}
+		Expect(o.validateTCDriver()).To(Succeed())
+	})
+
+	It("rejects network configuration which requires cmdline TC driver with netlink TC driver", func() {
+		s := &Server{Options: &Options{tcDriver: "netlink"}}
+		Expect(s.validateNetworkConfig(policyrules.NetworkConfig{StrictMode: true})).To(Succeed())
+		Expect(s.validateNetworkConfig(policyrules.NetworkConfig{AntiSpoofing: true})).ToNot(Succeed())
+		Expect(s.validateNetworkConfig(policyrules.NetworkConfig{MACPeers: true})).ToNot(Succeed())
+		Expect(s.validateNetworkConfig(policyrules.NetworkConfig{VlanIDs: []uint16{10}})).ToNot(Succeed())
+
+		s.Options.tcDriver = "cmdline"
+		Expect(s.validateNetworkConfig(policyrules.NetworkConfig{AntiSpoofing: true, MACPeers: true,
+			VlanIDs: []uint16{10}})).To(Succeed())
+	})
+})
+
+var _ = Describe("Server DSCP marking with netlink TC driver test", func() {
+	dscp := uint8(10)
+	ruleSet := policyrules.PolicyRuleSet{
+		Type: policyrules.PolicyTypeEgress,
+		Rules: []policyrules.Rule{{
+			IPCidrs: []*net.IPNet{{IP: net.IPv4(10, 10, 10, 0), Mask: net.CIDRMask(24, 32)}},
+			Action:  policyrules.PolicyActionPass,
+			DSCP:    &dscp,
+		}},
+	}
+
+	It("generates filters without pedit and csum actions with netlink TC driver", func() {
+		s := &Server{Options: &Options{tcDriver: "netlink"}}
+		supported := s.withSupportedRules(ruleSet)
+		Expect(supported.Rules).To(HaveLen(1))
+		Expect(supported.Rules[0].DSCP).To(BeNil())
+		Expect(ruleSet.Rules[0].DSCP).ToNot(BeNil())
+
+		tcObjs, err := generator.NewSimpleTCGenerator(generator.Options{}).GenerateFromPolicyRuleSet(supported)
+		Expect(err).ToNot(HaveOccurred())
+		for _, f := range tcObjs.Filters {
+			for _, a := range f.(*tctypes.FlowerFilter).Actions {
+				Expect(a.Type()).ToNot(BeElementOf(tctypes.ActionTypePedit, tctypes.ActionTypeCsum))
+			}
+		}
+	})
+
+	It("keeps DSCP marking with cmdline TC driver", func() {
+		s := &Server{Options: &Options{tcDriver: "cmdline"}}
+		Expect(s.withSupportedRules(ruleSet)).To(Equal(ruleSet))
+	})
+
+	It("keeps nil rules", func() {
+		s := &Server{Options: &Options{tcDriver: "netlink"}}
+		Expect(s.withSupportedRules(policyrules.PolicyRuleSet{}).Rules).To(BeNil())
+	})
+})
+
+var _ = Describe("Server filter budget test", func() {
+	pInfo := &controllers.PodInfo{Name: "pod", Namespace: "default", UID: "uid"}
+	ruleSet := policyrules.PolicyRuleSet{
+		Type: policyrules.PolicyTypeEgress,
+		Rules: []policyrules.Rule{{
+			Ports:  []policyrules.Port{{Protocol: policyrules.ProtocolTCP, Number: 80}},
+			Action: policyrules.PolicyActionPass,
+		}},
+	}
+	newServer := func(overflow tc.OverflowPolicy, perRep int) *Server {
+		return &Server{
+			Recorder:        record.NewFakeRecorder(10),
+			tcRuleGenerator: generator.NewSimpleTCGenerator(generator.Options{}),
+			filterBudget:    tc.NewFilterBudget(perRep, 0, overflow),
+		}
+	}
+	generate := func(s *Server, ruleSet policyrules.PolicyRuleSet) *generator.Objects {
+		tcObjs, err := s.tcRuleGenerator.GenerateFromPolicyRuleSet(ruleSet)
+		Expect(err).ToNot(HaveOccurred())
+		return tcObjs
+	}
+	defaultDeny := func(s *Server) *generator.Objects {
+		return generate(s, policyrules.PolicyRuleSet{Type: policyrules.PolicyTypeEgress, Rules: []policyrules.Rule{}})
+	}
+	hwFilters := func(tcObjs *generator.Objects) int {
+		count := 0
+		for _, f := range tcObjs.Filters {
+			if !f.(*tctypes.FlowerFilter).SkipHW {
+				count++
+			}
+		}
+		return count
+	}
+
+	It("keeps previous rules with refuse overflow policy", func() {
+		s := newServer(tc.OverflowPolicyRefuse, 6)
+		s.filterBudget.Commit("rep0", defaultDeny(s))
+
+		tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, "rep0", generate(s, ruleSet))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tcObjs).To(BeNil())
+	})
+
+	It("denies all traffic with refuse overflow policy if no rules were applied", func() {
+		s := newServer(tc.OverflowPolicyRefuse, 6)
+
+		tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, "rep0", generate(s, ruleSet))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tcObjs.Filters).To(HaveLen(len(defaultDeny(s).Filters)))
+		Expect(hwFilters(tcObjs)).To(Equal(len(tcObjs.Filters)))
+	})
+
+	It("applies default deny rules in software only if they exceed the budget", func() {
+		s := newServer(tc.OverflowPolicyDefaultDeny, 1)
+
+		tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, "rep0", generate(s, ruleSet))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tcObjs.Filters).To(HaveLen(len(defaultDeny(s).Filters)))
+		Expect(hwFilters(tcObjs)).To(BeZero())
+	})
+
+	Context("with filters applied before startup", func() {
+		var s *Server
+		var actuator *mocks.Actuator
+		pods := []controllers.PodInfo{{
+			Name:       "pod",
+			NodeName:   "node",
+			Interfaces: []controllers.InterfaceInfo{{DeviceID: "0000:03:00.2"}},
+		}, {
+			Name:       "pod-on-other-node",
+			NodeName:   "other-node",
+			Interfaces: []controllers.InterfaceInfo{{DeviceID: "0000:03:00.3"}},
+		}}
+
+		BeforeEach(func() {
+			s = newServer(tc.OverflowPolicyRefuse, 6)
+			s.Hostname = "node"
+			sriovnetProvider := &netmocks.SriovnetProvider{}
+			sriovnetProvider.On("GetVfIndexByPciAddress", "0000:03:00.2").Return(1, nil)
+			sriovnetProvider.On("GetUplinkRepresentor", "0000:03:00.2").Return("enp3s0f0", nil)
+			sriovnetProvider.On("GetVfRepresentor", "enp3s0f0", 1).Return("rep0", nil)
+			s.sriovnetProvider = sriovnetProvider
+			actuator = &mocks.Actuator{}
+			s.createActuatorFromRepFn = func(string) (tc.Actuator, error) { return actuator, nil }
+		})
+
+		It("keeps rules applied before startup with refuse overflow policy", func() {
+			actuator.On("FilterList").Return(defaultDeny(s).Filters, nil).Once()
+			s.seedFilterBudget(pods)
+			s.seedFilterBudget(pods)
+			actuator.AssertExpectations(GinkgoT())
+			Expect(s.filterBudget.Applied("rep0")).To(BeTrue())
+
+			tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, "rep0", generate(s, ruleSet))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tcObjs).To(BeNil())
+		})
+
+		It("denies all traffic with refuse overflow policy if no rules were applied before startup", func() {
+			actuator.On("FilterList").Return(nil, nil).Once()
+			s.seedFilterBudget(pods)
+			Expect(s.filterBudget.Applied("rep0")).To(BeFalse())
+
+			tcObjs, err := s.enforceFilterBudget(pInfo, ruleSet, "rep0", generate(s, ruleSet))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tcObjs.Filters).To(HaveLen(len(defaultDeny(s).Filters)))
+		})
+	})
+})
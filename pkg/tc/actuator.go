@@ -2,10 +2,13 @@ package tc
 
 import (
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 )
 
 // Actuator is an interface that applies specified TC Objects on netdev
 type Actuator interface {
 	// Actuate applies TC object in Objects on NetDev provided in Objects
 	Actuate(objects *generator.Objects) error
+	// FilterList lists the filters currently applied on NetDev
+	FilterList() ([]types.Filter, error)
 }
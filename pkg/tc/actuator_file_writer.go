@@ -10,6 +10,7 @@ import (
 	klog "k8s.io/klog/v2"
 
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 )
 
@@ -30,8 +31,8 @@ type ActuatorFileWriterImpl struct {
 // Actuate implements Actuator interface
 // Note(adrianc): As we are saving tc objects (mainly filters) to file
 // in a human-readable format (as this is really intended for debug purposes). We need represent
-// these objects as string. For now, we leverage CmdLineGenerator interface which is implemented by all objects.
-// Later on, it may be desired to extend the interface with String() method and implement throughout then use it here.
+// these objects as string. We leverage CmdLineGenerator interface which is implemented by all objects.
+// A structured (JSON/YAML) representation of objects is available via generator.Objects MarshalJSON.
 func (a ActuatorFileWriterImpl) Actuate(objects *generator.Objects) error {
 	exist, err := utils.PathExists(a.path)
 	if err != nil {
@@ -56,8 +57,18 @@ func (a ActuatorFileWriterImpl) Actuate(objects *generator.Objects) error {
 			strings.Join(objects.QDisc.GenCmdLineArgs(), " ")))
 	}
 
+	if len(objects.Chains) > 0 {
+		_, _ = newBuf.WriteString("chains:\n")
+		for _, c := range objects.Chains {
+			_, _ = newBuf.WriteString(hookPrefix(objects.QDisc, c.Attrs().Parent))
+			_, _ = newBuf.WriteString(strings.Join(c.GenCmdLineArgs(), " "))
+			_, _ = newBuf.WriteRune('\n')
+		}
+	}
+
 	_, _ = newBuf.WriteString("filters:\n")
 	for _, f := range objects.Filters {
+		_, _ = newBuf.WriteString(hookPrefix(objects.QDisc, f.Attrs().Parent))
 		_, _ = newBuf.WriteString(strings.Join(f.GenCmdLineArgs(), " "))
 		_, _ = newBuf.WriteRune('\n')
 	}
@@ -78,3 +89,33 @@ func (a ActuatorFileWriterImpl) Actuate(objects *generator.Objects) error {
 	_, err = newBuf.WriteTo(file)
 	return err
 }
+
+// FilterList implements Actuator interface, it returns the filters saved to file. no filters are returned if
+// the file does not exist.
+func (a ActuatorFileWriterImpl) FilterList() ([]types.Filter, error) {
+	exist, err := utils.PathExists(a.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to determine if path exist: %s", a.path)
+	}
+	if !exist {
+		return nil, nil
+	}
+
+	objs, err := ReadRulesFile(a.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rules file: %s", a.path)
+	}
+	return objs.Filters, nil
+}
+
+// hookPrefix returns the clsact hook (ingress or egress) of an object with the given parent as a line prefix, or
+// an empty string if qdisc is not a clsact qdisc
+func hookPrefix(qdisc types.QDisc, parent *uint32) string {
+	if qdisc == nil || qdisc.Type() != types.QDiscClsactType {
+		return ""
+	}
+	if types.HookOf(parent) == types.ParentEgress {
+		return "egress "
+	}
+	return "ingress "
+}
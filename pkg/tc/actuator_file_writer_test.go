@@ -125,6 +125,61 @@ protocol all pref 200 flower
 			Expect(string(content)).To(BeEquivalentTo(expectedFileContent))
 		})
 
+		It("writes chains to file", func() {
+			chainObjs := &generator.Objects{
+				QDisc:   ingressQdisc,
+				Filters: objs.Filters[:1],
+				Chains: []types.Chain{types.NewChainBuilder().WithChain(generator.TemplateChainBase).
+					WithTemplate(types.FilterProtocolIPv4, &types.FlowerSpec{}).Build()},
+			}
+			err := actuator.Actuate(chainObjs)
+			Expect(err).ToNot(HaveOccurred())
+
+			content, err := os.ReadFile(tmpFilePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(BeEquivalentTo(`qdisc: ingress
+chains:
+chain 10000 protocol ip flower
+filters:
+protocol ip pref 100 flower
+`))
+		})
+
+		It("writes hook of filters on clsact qdisc", func() {
+			clsactObjs := &generator.Objects{
+				QDisc: types.NewClsactQDiscBuilder().Build(),
+				Filters: []types.Filter{
+					types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).Build(),
+					types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).
+						WithParent(types.ParentEgress).Build(),
+				},
+			}
+			err := actuator.Actuate(clsactObjs)
+			Expect(err).ToNot(HaveOccurred())
+
+			content, err := os.ReadFile(tmpFilePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(BeEquivalentTo(`qdisc: clsact
+filters:
+ingress protocol ip pref 100 flower
+egress protocol ip pref 100 flower
+`))
+		})
+
+		It("lists filters saved to file", func() {
+			listed, err := actuator.FilterList()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(BeEmpty())
+
+			Expect(actuator.Actuate(objs)).To(Succeed())
+			listed, err = actuator.FilterList()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(HaveLen(len(objs.Filters)))
+			for i := range objs.Filters {
+				Expect(listed[i].Equals(objs.Filters[i])).To(BeTrue())
+			}
+		})
+
 		It("does not update file if same objects provided", func() {
 			err := actuator.Actuate(objs)
 			Expect(err).ToNot(HaveOccurred())
@@ -1,6 +1,8 @@
 package tc
 
 import (
+	"sort"
+
 	"github.com/pkg/errors"
 	klog "k8s.io/klog/v2"
 
@@ -20,54 +22,31 @@ type ActuatorTCImpl struct {
 }
 
 // Actuate is an implementation of Actuator interface. it applies Objects on the representor
-// Note: it assumes all filters are in Chain 0
+// Note: it assumes all filters are in Chain 0, generator.PolicyChain, generator.ConnTrackChain,
+// generator.RateLimitChain, port chains (starting from generator.PortChainBase) or template chains (starting from
+// generator.TemplateChainBase). filters and chains of the ingress and egress hooks of a clsact qdisc are reconciled
+// independently. an existing qdisc of a different type than objects qdisc is replaced.
 func (a *ActuatorTCImpl) Actuate(objects *generator.Objects) error {
 	if objects.QDisc == nil && len(objects.Filters) > 0 {
 		return errors.New("Qdisc cannot be nil if Filters are provided")
 	}
 
-	// list qdiscs
-	currentQDiscs, err := a.tcAPI.QDiscList()
-	if err != nil {
-		return errors.Wrap(err, "failed to list qdiscs")
-	}
-
-	var ingressQDiscExist bool
-	for _, q := range currentQDiscs {
-		if q.Type() == types.QDiscIngressType {
-			ingressQDiscExist = true
-			break
-		}
-	}
-
-	if objects.QDisc == nil {
-		// delete ingress qdisc if exist
-		if ingressQDiscExist {
-			return a.tcAPI.QDiscDel(types.NewIngressQDiscBuilder().Build())
+	if objects.QDisc != nil && objects.QDisc.Type() != types.QDiscClsactType {
+		for _, f := range objects.Filters {
+			if types.HookOf(f.Attrs().Parent) == types.ParentEgress {
+				return errors.Errorf("egress filters require %s qdisc", types.QDiscClsactType)
+			}
 		}
-		return nil
 	}
 
-	if len(objects.Filters) == 0 {
-		// delete filters in chain 0 if exist
-		chains, err := a.tcAPI.ChainList(types.NewIngressQDiscBuilder().Build())
-		if err != nil {
-			return err
-		}
-
-		for _, c := range chains {
-			if *c.Attrs().Chain == 0 {
-				return a.tcAPI.ChainDel(objects.QDisc, types.NewChainBuilder().WithChain(0).Build())
-			}
-		}
-		return nil
+	done, err := a.actuateQDisc(objects)
+	if err != nil || done {
+		return err
 	}
 
-	// add ingress qdisc if needed
-	if !ingressQDiscExist {
-		if err = a.tcAPI.QDiscAdd(objects.QDisc); err != nil {
-			return err
-		}
+	// template chains must exist before filters are added to them
+	if err = a.addTemplateChains(objects); err != nil {
+		return err
 	}
 
 	// get existing filters
@@ -77,8 +56,8 @@ func (a *ActuatorTCImpl) Actuate(objects *generator.Objects) error {
 	}
 
 	// create filter sets
-	existingFilterSet := NewFilterSetImpl()
-	newFilterSet := NewFilterSetImpl()
+	existingFilterSet := NewFilterHashSetImpl()
+	newFilterSet := NewFilterHashSetImpl()
 
 	for _, f := range existing {
 		existingFilterSet.Add(f)
@@ -97,11 +76,23 @@ func (a *ActuatorTCImpl) Actuate(objects *generator.Objects) error {
 	toRemove := existingFilterSet.Difference(newFilterSet).List()
 	toAdd := newFilterSet.Difference(existingFilterSet).List()
 
+	// Note(adrianc): u32 hash tables must exist before filters are added to or linked to them and cannot be
+	// deleted while linked or holding filters. links are removed first and hash tables are removed last.
+	sort.SliceStable(toAdd, func(i, j int) bool { return u32AddOrder(toAdd[i]) < u32AddOrder(toAdd[j]) })
+	sort.SliceStable(toRemove, func(i, j int) bool { return u32AddOrder(toRemove[i]) > u32AddOrder(toRemove[j]) })
+
+	var chainRemoved bool
+	var hashTablesToRemove []types.Filter
 	for _, f := range toRemove {
+		if isU32HashTable(f) {
+			hashTablesToRemove = append(hashTablesToRemove, f)
+			continue
+		}
 		err := a.tcAPI.FilterDel(objects.QDisc, f.Attrs())
 		if err != nil {
 			return err
 		}
+		chainRemoved = chainRemoved || isPortChain(chainOf(f)) || isTemplateChain(chainOf(f))
 	}
 
 	for _, f := range toAdd {
@@ -111,5 +102,212 @@ func (a *ActuatorTCImpl) Actuate(objects *generator.Objects) error {
 		}
 	}
 
+	for _, f := range hashTablesToRemove {
+		// Note(adrianc): the kernel releases a hash table asynchronously once its link is removed, deletion
+		// may fail until then. it is retried on the next sync.
+		if err := a.tcAPI.FilterDel(objects.QDisc, f.Attrs()); err != nil {
+			a.log.Info("failed to delete u32 hash table, will retry on next sync", "error", err)
+		}
+	}
+
+	if !chainRemoved {
+		return nil
+	}
+
+	// delete port chains and template chains which are no longer in use
+	inUse := make(map[hookChain]struct{})
+	for _, f := range objects.Filters {
+		inUse[hookChain{hook: types.HookOf(f.Attrs().Parent), chain: chainOf(f)}] = struct{}{}
+	}
+	for _, c := range objects.Chains {
+		inUse[hookChain{hook: types.HookOf(c.Attrs().Parent), chain: *c.Attrs().Chain}] = struct{}{}
+	}
+	return a.deleteChains(objects.QDisc, func(hook, chain uint32) bool {
+		_, ok := inUse[hookChain{hook: hook, chain: chain}]
+		return (isPortChain(chain) || isTemplateChain(chain)) && !ok
+	})
+}
+
+// FilterList is an implementation of Actuator interface. it lists the filters of the ingress or clsact qdisc of
+// the representor, no filters are returned if there is no such qdisc.
+func (a *ActuatorTCImpl) FilterList() ([]types.Filter, error) {
+	currentQDiscs, err := a.tcAPI.QDiscList()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list qdiscs")
+	}
+
+	for _, q := range currentQDiscs {
+		if q.Type() == types.QDiscIngressType || q.Type() == types.QDiscClsactType {
+			return a.tcAPI.FilterList(newQDisc(q.Type()))
+		}
+	}
+	return nil, nil
+}
+
+// hookChain identifies a chain of a qdisc hook
+type hookChain struct {
+	hook  uint32
+	chain uint32
+}
+
+// actuateQDisc applies objects qdisc, an existing qdisc of a different type is replaced. if objects has no filters,
+// filters of managed chains are deleted from the qdisc. it returns true if there is nothing more to apply.
+func (a *ActuatorTCImpl) actuateQDisc(objects *generator.Objects) (bool, error) {
+	// list qdiscs
+	currentQDiscs, err := a.tcAPI.QDiscList()
+	if err != nil {
+		return true, errors.Wrap(err, "failed to list qdiscs")
+	}
+
+	var currentQDisc types.QDisc
+	for _, q := range currentQDiscs {
+		if q.Type() == types.QDiscIngressType || q.Type() == types.QDiscClsactType {
+			currentQDisc = q
+			break
+		}
+	}
+
+	if objects.QDisc == nil {
+		// delete ingress or clsact qdisc if exist
+		if currentQDisc != nil {
+			return true, a.tcAPI.QDiscDel(newQDisc(currentQDisc.Type()))
+		}
+		return true, nil
+	}
+
+	if currentQDisc != nil && currentQDisc.Type() != objects.QDisc.Type() {
+		// Note(adrianc): ingress and clsact qdiscs share the same handle and cannot co-exist. the existing qdisc
+		// is deleted along with its filters and chains, objects are then applied on the new qdisc.
+		a.log.Info("replacing qdisc", "current", currentQDisc.Type(), "new", objects.QDisc.Type())
+		if err = a.tcAPI.QDiscDel(newQDisc(currentQDisc.Type())); err != nil {
+			return true, errors.Wrap(err, "failed to delete qdisc")
+		}
+		if len(objects.Filters) == 0 {
+			return true, nil
+		}
+		currentQDisc = nil
+	}
+
+	if len(objects.Filters) == 0 {
+		// delete filters in chain 0, policy chain, conntrack chain, rate limit chain and port chains if exist
+		return true, a.deleteChains(objects.QDisc, func(_, chain uint32) bool { return isManagedChain(chain) })
+	}
+
+	// add qdisc if needed
+	if currentQDisc == nil {
+		if err = a.tcAPI.QDiscAdd(objects.QDisc); err != nil {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// addTemplateChains adds the template chains in objects which do not exist on their objects qdisc hook. existing
+// template chains with a different template are deleted (along with their filters) and added again.
+func (a *ActuatorTCImpl) addTemplateChains(objects *generator.Objects) error {
+	if len(objects.Chains) == 0 {
+		return nil
+	}
+
+	chains, err := a.tcAPI.ChainList(objects.QDisc)
+	if err != nil {
+		return err
+	}
+	existing := make(map[hookChain]types.Chain, len(chains))
+	for _, c := range chains {
+		existing[hookChain{hook: types.HookOf(c.Attrs().Parent), chain: *c.Attrs().Chain}] = c
+	}
+
+	for _, c := range objects.Chains {
+		if cur, ok := existing[hookChain{hook: types.HookOf(c.Attrs().Parent), chain: *c.Attrs().Chain}]; ok {
+			if cur.Attrs().Template.Equals(c.Attrs().Template) {
+				continue
+			}
+			if err = a.tcAPI.ChainDel(objects.QDisc, cur); err != nil {
+				return err
+			}
+		}
+		if err = a.tcAPI.ChainAdd(objects.QDisc, c); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// deleteChains deletes chains on qdisc hooks for which shouldDelete returns true
+func (a *ActuatorTCImpl) deleteChains(qdisc types.QDisc, shouldDelete func(hook, chain uint32) bool) error {
+	chains, err := a.tcAPI.ChainList(qdisc)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range chains {
+		hook := types.HookOf(c.Attrs().Parent)
+		chain := *c.Attrs().Chain
+		if !shouldDelete(hook, chain) {
+			continue
+		}
+		cb := types.NewChainBuilder().WithChain(chain)
+		if qdisc.Type() == types.QDiscClsactType {
+			cb.WithParent(hook)
+		}
+		if err = a.tcAPI.ChainDel(qdisc, cb.Build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newQDisc returns a new qdisc of qdiscType
+func newQDisc(qdiscType types.QDiscType) types.QDisc {
+	if qdiscType == types.QDiscClsactType {
+		return types.NewClsactQDiscBuilder().Build()
+	}
+	return types.NewIngressQDiscBuilder().Build()
+}
+
+// isManagedChain returns true if chain may hold filters generated by generator
+func isManagedChain(chain uint32) bool {
+	return chain == types.ChainDefaultChain || chain == generator.PolicyChain ||
+		chain == generator.ConnTrackChain || chain == generator.RateLimitChain || isPortChain(chain) ||
+		isTemplateChain(chain)
+}
+
+// isPortChain returns true if chain is a port chain generated by generator.ChainTCGenerator
+func isPortChain(chain uint32) bool {
+	return chain >= generator.PortChainBase && chain < generator.TemplateChainBase
+}
+
+// isTemplateChain returns true if chain is a template chain generated by generator
+func isTemplateChain(chain uint32) bool {
+	return chain >= generator.TemplateChainBase
+}
+
+// chainOf returns the chain of filter
+func chainOf(filter types.Filter) uint32 {
+	if filter.Attrs().Chain == nil {
+		return types.ChainDefaultChain
+	}
+	return *filter.Attrs().Chain
+}
+
+// u32AddOrder returns the order in which filter should be added: u32 hash tables first, then filters and
+// u32 links last
+func u32AddOrder(filter types.Filter) int {
+	u32Filter, ok := filter.(*types.U32Filter)
+	if !ok || u32Filter.U32 == nil {
+		return 1
+	}
+	switch {
+	case u32Filter.U32.Divisor != 0:
+		return 0
+	case u32Filter.U32.Link != 0:
+		return 2
+	}
+	return 1
+}
+
+// isU32HashTable returns true if filter is a u32 filter which creates a hash table
+func isU32HashTable(filter types.Filter) bool {
+	return u32AddOrder(filter) == 0
+}
@@ -26,6 +26,12 @@ func ingressQdiscMatch() func(q tctypes.QDisc) bool {
 	}
 }
 
+func clsactQdiscMatch() func(q tctypes.QDisc) bool {
+	return func(q tctypes.QDisc) bool {
+		return q.Type() == tctypes.QDiscClsactType
+	}
+}
+
 func filterMatch(filter tctypes.Filter) func(f tctypes.Filter) bool {
 	return func(f tctypes.Filter) bool {
 		return filter.Equals(f)
@@ -106,12 +112,12 @@ var _ = Describe("Actuator TC tests", func() {
 		})
 
 		When("Objects contain ingress Qdisc", func() {
-			It("does nothing if ingress Qdisc exist without chain 0", func() {
+			It("does nothing if ingress Qdisc exist without chain 0, policy, conntrack and rate limit chains", func() {
 				tcObj.QDisc = ingressQdisc
 
 				tcMock.On("QDiscList").Return([]tctypes.QDisc{}, nil)
 				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{
-					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(1).Build()}, nil)
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(5).Build()}, nil)
 
 				err := actuator.Actuate(tcObj)
 				Expect(err).ToNot(HaveOccurred())
@@ -132,6 +138,56 @@ var _ = Describe("Actuator TC tests", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("deletes chain 0, policy chain, conntrack chain and rate limit chain on ingress qdisc when exist", func() {
+				tcObj.QDisc = ingressQdisc
+
+				tcMock.On("QDiscList").Return([]tctypes.QDisc{}, nil)
+				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(0).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.PolicyChain).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.ConnTrackChain).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.RateLimitChain).Build()}, nil)
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(0))).
+					Return(nil).Once()
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.PolicyChain))).
+					Return(nil).Once()
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.ConnTrackChain))).
+					Return(nil).Once()
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.RateLimitChain))).
+					Return(nil).Once()
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("deletes port chains on ingress qdisc when exist", func() {
+				tcObj.QDisc = ingressQdisc
+
+				tcMock.On("QDiscList").Return([]tctypes.QDisc{}, nil)
+				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.PortChainBase).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.PortChainBase + 1).Build()}, nil)
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.PortChainBase))).
+					Return(nil).Once()
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.PortChainBase+1))).
+					Return(nil).Once()
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
 			It("does nothing if ingress Qdisc exists, chain 0 does not exist", func() {
 				tcObj.QDisc = ingressQdisc
 
@@ -223,6 +279,42 @@ var _ = Describe("Actuator TC tests", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("adds template chains before filters", func() {
+				template := tctypes.NewFlowerFilterBuilder().WithMatchKeyDstIP(ipToIpNet("0.0.0.0/24")).Build().Flower
+				templateChain := tctypes.NewChainBuilder().WithChain(generator.TemplateChainBase).
+					WithTemplate(tctypes.FilterProtocolIPv4, template).Build()
+				otherTemplateChain := tctypes.NewChainBuilder().WithChain(generator.TemplateChainBase+1).
+					WithTemplate(tctypes.FilterProtocolIPv4, template).Build()
+				tcObj.Chains = []tctypes.Chain{templateChain, otherTemplateChain}
+				var calls []string
+				tcMock.On("ChainList", mock.MatchedBy(ingressQdiscMatch())).Return([]tctypes.Chain{
+					tctypes.NewChainBuilder().WithChain(generator.TemplateChainBase).
+						WithTemplate(tctypes.FilterProtocolIPv6, template).Build(),
+					otherTemplateChain}, nil)
+				tcMock.On("ChainDel", mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.TemplateChainBase))).
+					Run(func(mock.Arguments) { calls = append(calls, "ChainDel") }).Return(nil).Once()
+				tcMock.On("ChainAdd", mock.MatchedBy(ingressQdiscMatch()), templateChain).
+					Run(func(mock.Arguments) { calls = append(calls, "ChainAdd") }).Return(nil).Once()
+				tcMock.On("FilterList", mock.MatchedBy(ingressQdiscMatch())).Return([]tctypes.Filter{}, nil)
+				tcMock.On("FilterAdd", mock.MatchedBy(ingressQdiscMatch()), mock.Anything).
+					Run(func(mock.Arguments) { calls = append(calls, "FilterAdd") }).Return(nil)
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(calls).To(Equal([]string{"ChainDel", "ChainAdd", "FilterAdd", "FilterAdd"}))
+			})
+
+			It("fails if adding template chain fails", func() {
+				tcObj.Chains = []tctypes.Chain{tctypes.NewChainBuilder().WithChain(generator.TemplateChainBase).
+					WithTemplate(tctypes.FilterProtocolIPv4, &tctypes.FlowerSpec{}).Build()}
+				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{}, nil)
+				tcMock.On("ChainAdd", mock.Anything, mock.Anything).Return(errors.New("test error!"))
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).To(HaveOccurred())
+			})
+
 			It("fails if listing filter on qdisc fails", func() {
 				tcMock.On("FilterList", mock.Anything).
 					Return(nil, errors.New("test error!"))
@@ -262,6 +354,65 @@ var _ = Describe("Actuator TC tests", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("deletes port chains which are no longer in use", func() {
+				portChainFilter := tctypes.NewFlowerFilterBuilder().
+					WithProtocol(tctypes.FilterProtocolIPv4).
+					WithChain(generator.PortChainBase + 1).
+					WithPriority(200).
+					WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+					WithMatchKeyDstPort(80).
+					WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+					Build()
+				tcMock.ExpectedCalls = nil
+				tcMock.On("QDiscList").Return([]tctypes.QDisc{ingressQdisc}, nil)
+				tcMock.On("FilterList", mock.MatchedBy(ingressQdiscMatch())).
+					Return(append([]tctypes.Filter{portChainFilter}, neededFilters...), nil)
+				tcMock.On(
+					"FilterDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(filterAttrMatch(portChainFilter.Attrs()))).
+					Return(nil)
+				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(0).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.PortChainBase + 1).Build()}, nil)
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.PortChainBase+1))).
+					Return(nil).Once()
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("deletes template chains which are no longer in use", func() {
+				templateChainFilter := tctypes.NewFlowerFilterBuilder().
+					WithProtocol(tctypes.FilterProtocolAll).
+					WithChain(generator.TemplateChainBase).
+					WithPriority(100).
+					WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+					Build()
+				tcMock.ExpectedCalls = nil
+				tcMock.On("QDiscList").Return([]tctypes.QDisc{ingressQdisc}, nil)
+				tcMock.On("FilterList", mock.MatchedBy(ingressQdiscMatch())).
+					Return(append([]tctypes.Filter{templateChainFilter}, neededFilters...), nil)
+				tcMock.On(
+					"FilterDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(filterAttrMatch(templateChainFilter.Attrs()))).
+					Return(nil)
+				tcMock.On("ChainList", mock.Anything).Return([]tctypes.Chain{
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(0).Build(),
+					tctypes.NewChainBuilder().WithParent(0xfffffff1).WithChain(generator.TemplateChainBase).
+						WithTemplate(tctypes.FilterProtocolAll, &tctypes.FlowerSpec{}).Build()}, nil)
+				tcMock.On("ChainDel",
+					mock.MatchedBy(ingressQdiscMatch()),
+					mock.MatchedBy(chainMatch(generator.TemplateChainBase))).
+					Return(nil).Once()
+
+				err := actuator.Actuate(tcObj)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
 			It("fails if removing filter from qdisc fails", func() {
 				tcMock.On(
 					"FilterDel",
@@ -273,4 +424,180 @@ var _ = Describe("Actuator TC tests", func() {
 			})
 		})
 	})
+
+	Context("Actuate with u32 filters", func() {
+		ipToIpNet := func(ip string) *net.IPNet { ipn, _ := utils.IPToIPNet(ip); return ipn }
+		// u32Filters returns link, hash table entry and hash table filters as listed by TC
+		u32Filters := func(hashTable uint32) []tctypes.Filter {
+			u32Filter := func() *tctypes.U32FilterBuilder {
+				return tctypes.NewU32FilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(206)
+			}
+			return []tctypes.Filter{
+				u32Filter().
+					WithHandle(tctypes.U32Handle(0x800, 0, hashTable)).
+					WithMatch(0, 0, 0).
+					WithHashKey(0xff, 16).
+					WithLink(hashTable).
+					Build(),
+				u32Filter().
+					WithHandle(tctypes.U32Handle(hashTable, 0xa, 0x800)).
+					WithHashTable(hashTable, 0xa).
+					WithMatchDstIP(ipToIpNet("10.0.0.10")).
+					WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+					Build(),
+				u32Filter().WithDivisor(hashTable, 256).Build(),
+			}
+		}
+
+		It("adds hash tables first and links last, removes links first and hash tables last", func() {
+			var calls []string
+			record := func(op string, f *tctypes.FilterAttrs) {
+				calls = append(calls, op+" "+tctypes.U32HandleString(*f.Handle))
+			}
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{ingressQdisc}, nil)
+			tcMock.On("FilterList", mock.MatchedBy(ingressQdiscMatch())).Return(u32Filters(0x100), nil)
+			tcMock.On("FilterDel", mock.MatchedBy(ingressQdiscMatch()), mock.Anything).
+				Run(func(args mock.Arguments) { record("del", args.Get(1).(*tctypes.FilterAttrs)) }).
+				Return(nil)
+			tcMock.On("FilterAdd", mock.MatchedBy(ingressQdiscMatch()), mock.Anything).
+				Run(func(args mock.Arguments) { record("add", args.Get(1).(tctypes.Filter).Attrs()) }).
+				Return(nil)
+
+			err := actuator.Actuate(&generator.Objects{QDisc: ingressQdisc, Filters: u32Filters(0x200)})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(calls).To(Equal([]string{
+				"del 800::100", "del 100:a:800", "add 200:", "add 200:a:800", "add 800::200", "del 100:"}))
+		})
+
+		It("does not fail if hash table deletion fails", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{ingressQdisc}, nil)
+			tcMock.On("FilterList", mock.MatchedBy(ingressQdiscMatch())).Return(u32Filters(0x100), nil)
+			tcMock.On("FilterDel", mock.MatchedBy(ingressQdiscMatch()), mock.MatchedBy(func(f *tctypes.FilterAttrs) bool {
+				return f.Handle != nil && *f.Handle == tctypes.U32Handle(0x100, 0, 0)
+			})).Return(errors.New("device or resource busy"))
+			tcMock.On("FilterDel", mock.MatchedBy(ingressQdiscMatch()), mock.Anything).Return(nil)
+			tcMock.On("FilterAdd", mock.MatchedBy(ingressQdiscMatch()), mock.Anything).Return(nil)
+
+			err := actuator.Actuate(&generator.Objects{QDisc: ingressQdisc, Filters: u32Filters(0x200)})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Actuate with clsact qdisc", func() {
+		clsactQdisc := tctypes.NewClsactQDiscBuilder().Build()
+		dropAction := tctypes.NewGenericActionBuiler().WithDrop().Build()
+		filter := func(parent uint32, prio uint16) tctypes.Filter {
+			return tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(prio).
+				WithParent(parent).
+				WithAction(dropAction).
+				Build()
+		}
+		hookMatch := func(hook uint32) func(f *tctypes.FilterAttrs) bool {
+			return func(f *tctypes.FilterAttrs) bool { return tctypes.HookOf(f.Parent) == hook }
+		}
+
+		It("replaces existing ingress qdisc and adds filters of both hooks", func() {
+			ingressFilter := tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(dropAction).
+				Build()
+			egressFilter := filter(tctypes.ParentEgress, 300)
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{ingressQdisc}, nil)
+			tcMock.On("QDiscDel", mock.MatchedBy(ingressQdiscMatch())).Return(nil).Once()
+			tcMock.On("QDiscAdd", mock.MatchedBy(clsactQdiscMatch())).Return(nil).Once()
+			tcMock.On("FilterList", mock.MatchedBy(clsactQdiscMatch())).Return([]tctypes.Filter{}, nil)
+			tcMock.On("FilterAdd", mock.MatchedBy(clsactQdiscMatch()), mock.MatchedBy(filterMatch(ingressFilter))).
+				Return(nil).Once()
+			tcMock.On("FilterAdd", mock.MatchedBy(clsactQdiscMatch()), mock.MatchedBy(filterMatch(egressFilter))).
+				Return(nil).Once()
+
+			err := actuator.Actuate(&generator.Objects{
+				QDisc: clsactQdisc, Filters: []tctypes.Filter{ingressFilter, egressFilter}})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reconciles each hook independently", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{clsactQdisc}, nil)
+			tcMock.On("FilterList", mock.MatchedBy(clsactQdiscMatch())).Return([]tctypes.Filter{
+				filter(tctypes.ParentIngress, 300), filter(tctypes.ParentEgress, 301)}, nil)
+			tcMock.On("FilterDel", mock.MatchedBy(clsactQdiscMatch()), mock.MatchedBy(hookMatch(tctypes.ParentEgress))).
+				Return(nil).Once()
+			tcMock.On("FilterAdd", mock.MatchedBy(clsactQdiscMatch()),
+				mock.MatchedBy(filterMatch(filter(tctypes.ParentEgress, 300)))).Return(nil).Once()
+
+			err := actuator.Actuate(&generator.Objects{QDisc: clsactQdisc, Filters: []tctypes.Filter{
+				filter(tctypes.ParentIngress, 300), filter(tctypes.ParentEgress, 300)}})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes managed chains of both hooks if there are no filters", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{clsactQdisc}, nil)
+			tcMock.On("ChainList", mock.MatchedBy(clsactQdiscMatch())).Return([]tctypes.Chain{
+				tctypes.NewChainBuilder().WithParent(tctypes.ParentIngress).WithChain(0).Build(),
+				tctypes.NewChainBuilder().WithParent(tctypes.ParentEgress).WithChain(0).Build(),
+			}, nil)
+			for _, hook := range []uint32{tctypes.ParentIngress, tctypes.ParentEgress} {
+				hook := hook
+				tcMock.On("ChainDel", mock.MatchedBy(clsactQdiscMatch()), mock.MatchedBy(func(c tctypes.Chain) bool {
+					return c.Attrs().Parent != nil && *c.Attrs().Parent == hook
+				})).Return(nil).Once()
+			}
+
+			err := actuator.Actuate(&generator.Objects{QDisc: clsactQdisc})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes clsact qdisc when objects do not contain qdisc", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{clsactQdisc}, nil)
+			tcMock.On("QDiscDel", mock.MatchedBy(clsactQdiscMatch())).Return(nil).Once()
+
+			err := actuator.Actuate(&generator.Objects{})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails if egress filters are provided with ingress qdisc", func() {
+			err := actuator.Actuate(&generator.Objects{
+				QDisc: ingressQdisc, Filters: []tctypes.Filter{filter(tctypes.ParentEgress, 300)}})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("FilterList", func() {
+		filters := []tctypes.Filter{
+			tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(100).Build()}
+
+		It("lists filters of clsact qdisc", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{tctypes.NewClsactQDiscBuilder().Build()}, nil)
+			tcMock.On("FilterList", mock.MatchedBy(clsactQdiscMatch())).Return(filters, nil)
+
+			listed, err := actuator.FilterList()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(Equal(filters))
+		})
+
+		It("returns no filters if ingress or clsact qdisc does not exist", func() {
+			tcMock.On("QDiscList").Return([]tctypes.QDisc{}, nil)
+
+			listed, err := actuator.FilterList()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(BeEmpty())
+		})
+
+		It("fails if listing qdisc fails", func() {
+			tcMock.On("QDiscList").Return(nil, errors.New("test error!"))
+
+			_, err := actuator.FilterList()
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
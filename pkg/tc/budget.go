@@ -0,0 +1,161 @@
+package tc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// OverflowPolicy is the policy applied when TC objects exceed the filter budget
+type OverflowPolicy string
+
+const (
+	// OverflowPolicyRefuse keeps the previously applied filters of the representor. if no filters were applied
+	// on the representor, OverflowPolicyDefaultDeny is applied.
+	OverflowPolicyRefuse OverflowPolicy = "refuse"
+	// OverflowPolicySoftware applies the filters of the representor in software only (not offloaded to hardware)
+	OverflowPolicySoftware OverflowPolicy = "software"
+	// OverflowPolicyDefaultDeny applies the filters generated for the representor with no policy rules,
+	// i.e all traffic (except for control traffic) is denied
+	OverflowPolicyDefaultDeny OverflowPolicy = "default-deny"
+)
+
+// OverflowPolicyFromString returns OverflowPolicy from string. empty string is OverflowPolicyRefuse
+func OverflowPolicyFromString(policy string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(strings.ToLower(policy)); p {
+	case "":
+		return OverflowPolicyRefuse, nil
+	case OverflowPolicyRefuse, OverflowPolicySoftware, OverflowPolicyDefaultDeny:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy: %s", policy)
+	}
+}
+
+// NewFilterBudget creates a new FilterBudget instance. perRep and perNode are the maximal number of hardware
+// filters per representor and per node, 0 means unlimited.
+func NewFilterBudget(perRep, perNode int, overflow OverflowPolicy) *FilterBudget {
+	return &FilterBudget{
+		perRep:   perRep,
+		perNode:  perNode,
+		overflow: overflow,
+		usage:    make(map[string]int),
+	}
+}
+
+// FilterBudget limits the number of filters offloaded to hardware per representor and per node.
+// filters which are not offloaded (see SkipHW of types.FlowerFilter, types.U32Filter and types.MatchAllFilter)
+// and basic filters, which are never offloaded, are not counted.
+type FilterBudget struct {
+	perRep   int
+	perNode  int
+	overflow OverflowPolicy
+
+	mu sync.Mutex
+	// usage holds the number of hardware filters applied per representor
+	usage map[string]int
+}
+
+// Overflow returns the OverflowPolicy of the budget
+func (b *FilterBudget) Overflow() OverflowPolicy {
+	return b.overflow
+}
+
+// Check returns an error which describes the exceeded budget if objects applied on rep exceed the budget,
+// nil is returned otherwise. filters applied on other representors count towards the node budget.
+func (b *FilterBudget) Check(rep string, objects *generator.Objects) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	count := hwFilterCount(objects)
+	if b.perRep > 0 && count > b.perRep {
+		return fmt.Errorf("%d filters exceed representor %s filter budget of %d", count, rep, b.perRep)
+	}
+
+	if b.perNode > 0 {
+		nodeCount := count
+		for r, c := range b.usage {
+			if r != rep {
+				nodeCount += c
+			}
+		}
+		if nodeCount > b.perNode {
+			return fmt.Errorf("%d filters of representor %s exceed node filter budget of %d, %d filters in use",
+				count, rep, b.perNode, nodeCount-count)
+		}
+	}
+	return nil
+}
+
+// Commit records objects as applied on rep
+func (b *FilterBudget) Commit(rep string, objects *generator.Objects) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage[rep] = hwFilterCount(objects)
+}
+
+// Applied returns true if objects were recorded as applied on rep
+func (b *FilterBudget) Applied(rep string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.usage[rep]
+	return ok
+}
+
+// Retain releases the filters recorded for representors which are not in reps
+func (b *FilterBudget) Retain(reps map[string]struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for rep := range b.usage {
+		if _, ok := reps[rep]; !ok {
+			delete(b.usage, rep)
+		}
+	}
+}
+
+// WithSoftwareOnlyFilters sets the filters in objects not to be offloaded to hardware
+func WithSoftwareOnlyFilters(objects *generator.Objects) *generator.Objects {
+	for _, f := range objects.Filters {
+		switch filter := f.(type) {
+		case *types.FlowerFilter:
+			filter.SkipHW = true
+		case *types.U32Filter:
+			filter.SkipHW = true
+		case *types.MatchAllFilter:
+			filter.SkipHW = true
+		}
+	}
+	return objects
+}
+
+// hwFilterCount returns the number of filters in objects which are offloaded to hardware
+func hwFilterCount(objects *generator.Objects) int {
+	if objects == nil {
+		return 0
+	}
+	count := 0
+	for _, f := range objects.Filters {
+		if !isSkipHW(f) {
+			count++
+		}
+	}
+	return count
+}
+
+// isSkipHW returns true if filter is not offloaded to hardware
+func isSkipHW(filter types.Filter) bool {
+	switch f := filter.(type) {
+	case *types.FlowerFilter:
+		return f.SkipHW
+	case *types.U32Filter:
+		return f.SkipHW
+	case *types.MatchAllFilter:
+		return f.SkipHW
+	case *types.BasicFilter:
+		return true
+	}
+	return false
+}
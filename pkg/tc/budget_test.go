@@ -0,0 +1,113 @@
+package tc_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+var _ = Describe("FilterBudget tests", func() {
+	objects := func(count int) *generator.Objects {
+		objs := &generator.Objects{QDisc: tctypes.NewIngressQDiscBuilder().Build()}
+		for i := 0; i < count; i++ {
+			objs.Filters = append(objs.Filters, tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(uint16(100+i)).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build())
+		}
+		return objs
+	}
+
+	Context("OverflowPolicyFromString", func() {
+		It("parses overflow policies", func() {
+			for s, expected := range map[string]tc.OverflowPolicy{
+				"":             tc.OverflowPolicyRefuse,
+				"refuse":       tc.OverflowPolicyRefuse,
+				"Software":     tc.OverflowPolicySoftware,
+				"default-deny": tc.OverflowPolicyDefaultDeny,
+			} {
+				p, err := tc.OverflowPolicyFromString(s)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).To(Equal(expected))
+			}
+		})
+
+		It("fails for unknown overflow policy", func() {
+			_, err := tc.OverflowPolicyFromString("foo")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Check", func() {
+		It("allows filters within representor budget", func() {
+			budget := tc.NewFilterBudget(3, 0, tc.OverflowPolicyRefuse)
+			Expect(budget.Check("rep0", objects(3))).To(Succeed())
+			Expect(budget.Check("rep0", objects(4))).ToNot(Succeed())
+		})
+
+		It("counts filters of other representors towards node budget", func() {
+			budget := tc.NewFilterBudget(0, 5, tc.OverflowPolicyRefuse)
+			Expect(budget.Check("rep0", objects(3))).To(Succeed())
+			budget.Commit("rep0", objects(3))
+
+			Expect(budget.Check("rep1", objects(2))).To(Succeed())
+			Expect(budget.Check("rep1", objects(3))).ToNot(Succeed())
+			// filters of the same representor are replaced
+			Expect(budget.Check("rep0", objects(5))).To(Succeed())
+		})
+
+		It("records representors with applied filters", func() {
+			budget := tc.NewFilterBudget(0, 5, tc.OverflowPolicyRefuse)
+			Expect(budget.Applied("rep0")).To(BeFalse())
+			budget.Commit("rep0", objects(0))
+			Expect(budget.Applied("rep0")).To(BeTrue())
+
+			budget.Retain(map[string]struct{}{})
+			Expect(budget.Applied("rep0")).To(BeFalse())
+		})
+
+		It("releases filters of representors which are not retained", func() {
+			budget := tc.NewFilterBudget(0, 5, tc.OverflowPolicyRefuse)
+			budget.Commit("rep0", objects(3))
+			budget.Commit("rep1", objects(2))
+			Expect(budget.Check("rep2", objects(1))).ToNot(Succeed())
+
+			budget.Retain(map[string]struct{}{"rep1": {}})
+			Expect(budget.Check("rep2", objects(3))).To(Succeed())
+		})
+
+		It("does not count software only filters", func() {
+			budget := tc.NewFilterBudget(2, 2, tc.OverflowPolicySoftware)
+			objs := tc.WithSoftwareOnlyFilters(objects(3))
+			for _, f := range objs.Filters {
+				Expect(f.(*tctypes.FlowerFilter).SkipHW).To(BeTrue())
+			}
+			Expect(budget.Check("rep0", objs)).To(Succeed())
+			budget.Commit("rep0", objs)
+			Expect(budget.Check("rep1", objects(2))).To(Succeed())
+		})
+
+		It("does not count software only u32 filters", func() {
+			budget := tc.NewFilterBudget(1, 1, tc.OverflowPolicySoftware)
+			objs := objects(1)
+			objs.Filters = append(objs.Filters, tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithDivisor(0x100, 256).
+				Build())
+			Expect(budget.Check("rep0", objs)).ToNot(Succeed())
+			objs = tc.WithSoftwareOnlyFilters(objs)
+			Expect(objs.Filters[1].(*tctypes.U32Filter).SkipHW).To(BeTrue())
+			Expect(budget.Check("rep0", objs)).To(Succeed())
+		})
+
+		It("returns overflow policy", func() {
+			Expect(tc.NewFilterBudget(1, 1, tc.OverflowPolicyDefaultDeny).Overflow()).
+				To(Equal(tc.OverflowPolicyDefaultDeny))
+		})
+	})
+})
@@ -1,9 +1,16 @@
 package cmdline
 
 import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 )
 
 const (
@@ -11,9 +18,15 @@ const (
 	ipStr        = "ip"
 	ipv6Str      = "ipv6"
 	vlanProtoStr = "802.1q"
+	qinqProtoStr = "802.1ad"
+	arpStr       = "arp"
 
-	tcpStr = "tcp"
-	udpStr = "udp"
+	tcpStr    = "tcp"
+	udpStr    = "udp"
+	icmpStr   = "icmp"
+	icmpv6Str = "icmpv6"
+	// tc prints protocols with no name as two hex digits
+	igmpStr = "02"
 )
 
 // sToFilterProtocol converts given string to types.FilterProtocol. returns "" in case of an invalid conversion
@@ -29,6 +42,10 @@ func sToFilterProtocol(proto string) types.FilterProtocol {
 		fp = types.FilterProtocolIPv6
 	case vlanProtoStr:
 		fp = types.FilterProtocol8021Q
+	case qinqProtoStr:
+		fp = types.FilterProtocol8021AD
+	case arpStr:
+		fp = types.FilterProtocolARP
 	}
 
 	return fp
@@ -43,6 +60,12 @@ func sToFlowerIPProto(ipp string) types.FlowerIPProto {
 		fp = types.FlowerIPProtoTCP
 	case udpStr:
 		fp = types.FlowerIPProtoUDP
+	case icmpStr:
+		fp = types.FlowerIPProtoICMP
+	case icmpv6Str:
+		fp = types.FlowerIPProtoICMPv6
+	case igmpStr, string(types.FlowerIPProtoIGMP):
+		fp = types.FlowerIPProtoIGMP
 	}
 
 	return fp
@@ -57,7 +80,446 @@ func sToFlowerVlanEthType(ethtype string) types.FlowerVlanEthType {
 		vlanEthType = types.FlowerVlanEthTypeIPv4
 	case ipv6Str:
 		vlanEthType = types.FlowerVlanEthTypeIPv6
+	case arpStr:
+		vlanEthType = types.FlowerVlanEthTypeARP
+	case vlanProtoStr:
+		vlanEthType = types.FlowerVlanEthType8021Q
 	}
 
 	return vlanEthType
 }
+
+// cIPFlagsToFlowerIPFlags converts cIPFlags to types.FlowerIPFlags
+func cIPFlagsToFlowerIPFlags(ipFlags *cIPFlags) types.FlowerIPFlags {
+	flags := make([]string, 0, 2)
+	for _, f := range []struct {
+		name string
+		val  *bool
+	}{{"frag", ipFlags.Frag}, {"firstfrag", ipFlags.FirstFrag}} {
+		if f.val == nil {
+			continue
+		}
+		if *f.val {
+			flags = append(flags, f.name)
+		} else {
+			flags = append(flags, "no"+f.name)
+		}
+	}
+	return types.FlowerIPFlags(strings.Join(flags, "/"))
+}
+
+// sToFlowerTCPFlags converts given string in the format of <flags>[/<mask>] where flags and mask are hex numbers
+// to types.FlowerTCPFlags. if mask is not provided, all flags are matched.
+func sToFlowerTCPFlags(tcpFlags string) (types.FlowerTCPFlags, error) {
+	parseHex := func(s string) (uint16, error) {
+		val, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 16)
+		return uint16(val), err
+	}
+
+	flagsAndMask := strings.SplitN(tcpFlags, "/", 2)
+	flags, err := parseHex(flagsAndMask[0])
+	if err != nil {
+		return types.FlowerTCPFlags{}, err
+	}
+
+	mask := uint16(0xffff)
+	if len(flagsAndMask) == 2 {
+		if mask, err = parseHex(flagsAndMask[1]); err != nil {
+			return types.FlowerTCPFlags{}, err
+		}
+	}
+
+	return types.FlowerTCPFlags{Flags: flags, Mask: mask}, nil
+}
+
+// sToFlowerMAC converts given string in the format of <mac>[/<mask>] where mask is either a MAC address or a prefix
+// length to types.FlowerMAC. if mask is not provided, the entire address is matched.
+func sToFlowerMAC(mac string) (types.FlowerMAC, error) {
+	addrAndMask := strings.SplitN(mac, "/", 2)
+	addr, err := net.ParseMAC(addrAndMask[0])
+	if err != nil {
+		return types.FlowerMAC{}, err
+	}
+
+	if len(addrAndMask) == 1 {
+		return types.FlowerMAC{Addr: addr}, nil
+	}
+
+	if bits, err := strconv.ParseUint(addrAndMask[1], 10, 8); err == nil {
+		if int(bits) > len(addr)*8 {
+			return types.FlowerMAC{}, fmt.Errorf("invalid MAC mask length: %d", bits)
+		}
+		mask := make(net.HardwareAddr, len(addr))
+		for i := 0; i < int(bits); i++ {
+			mask[i/8] |= 0x80 >> (i % 8)
+		}
+		return types.FlowerMAC{Addr: addr, Mask: mask}, nil
+	}
+
+	mask, err := net.ParseMAC(addrAndMask[1])
+	if err != nil {
+		return types.FlowerMAC{}, err
+	}
+	return types.FlowerMAC{Addr: addr, Mask: mask}, nil
+}
+
+// cFilterToFlowerFilter converts cFilter of kind flower to types.FlowerFilter
+func cFilterToFlowerFilter(f *cFilter) (*types.FlowerFilter, error) {
+	fb := types.NewFlowerFilterBuilder().
+		WithChain(f.Chain).
+		WithProtocol(sToFilterProtocol(f.Protocol)).
+		WithPriority(f.Priority).
+		WithHandle(uint32(f.Options.Handle))
+
+	if f.Options.SkipHW {
+		fb.WithSkipHW()
+	}
+
+	if err := addFlowerKeys(fb, &f.Options.Keys); err != nil {
+		return nil, err
+	}
+
+	actions, err := cActionsToActions(f.Options.Actions)
+	if err != nil {
+		return nil, err
+	}
+	for _, act := range actions {
+		fb.WithAction(act)
+	}
+
+	return fb.Build(), nil
+}
+
+// cFilterToU32Filter converts cFilter of kind u32 to types.U32Filter. filters in the root hash table of their
+// priority (one of rootHashTables) are converted to filters with no hash table
+func cFilterToU32Filter(f *cFilter, rootHashTables map[uint32]struct{}) (*types.U32Filter, error) {
+	handle, err := parseU32Handle(f.Options.U32Handle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse u32 filter handle")
+	}
+
+	ub := types.NewU32FilterBuilder().
+		WithChain(f.Chain).
+		WithProtocol(sToFilterProtocol(f.Protocol)).
+		WithPriority(f.Priority)
+
+	if f.Options.Divisor != 0 {
+		return ub.WithDivisor(handle>>20, f.Options.Divisor).Build(), nil
+	}
+	ub.WithHandle(handle)
+
+	if f.Options.SkipHW {
+		ub.WithSkipHW()
+	}
+
+	hashTable, err := strconv.ParseUint(f.Options.HashTable, 16, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse u32 filter hash table")
+	}
+	if _, ok := rootHashTables[uint32(hashTable)]; !ok {
+		bucket, err := strconv.ParseUint(f.Options.Bucket, 16, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse u32 filter bucket")
+		}
+		ub.WithHashTable(uint32(hashTable), uint32(bucket))
+	}
+
+	for _, m := range f.Options.Matches {
+		value, err := strconv.ParseUint(m.Value, 16, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse u32 match value")
+		}
+		mask, err := strconv.ParseUint(m.Mask, 16, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse u32 match mask")
+		}
+		ub.WithMatch(uint32(value), uint32(mask), m.Off)
+	}
+
+	if f.Options.Link != "" {
+		link, err := parseU32Handle(f.Options.Link)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse u32 filter link")
+		}
+		ub.WithLink(link >> 20)
+		if f.Options.HashMask != "" {
+			mask, err := strconv.ParseUint(f.Options.HashMask, 16, 32)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse u32 hash mask")
+			}
+			ub.WithHashKey(uint32(mask), f.Options.HashOff)
+		}
+	}
+
+	actions, err := cActionsToActions(f.Options.Actions)
+	if err != nil {
+		return nil, err
+	}
+	for _, act := range actions {
+		ub.WithAction(act)
+	}
+
+	return ub.Build(), nil
+}
+
+// cFilterToMatchAllFilter converts cFilter of kind matchall to types.MatchAllFilter
+func cFilterToMatchAllFilter(f *cFilter) (*types.MatchAllFilter, error) {
+	mb := types.NewMatchAllFilterBuilder().
+		WithChain(f.Chain).
+		WithProtocol(sToFilterProtocol(f.Protocol)).
+		WithPriority(f.Priority).
+		WithHandle(uint32(f.Options.Handle))
+
+	if f.Options.SkipHW {
+		mb.WithSkipHW()
+	}
+
+	actions, err := cActionsToActions(f.Options.Actions)
+	if err != nil {
+		return nil, err
+	}
+	for _, act := range actions {
+		mb.WithAction(act)
+	}
+
+	return mb.Build(), nil
+}
+
+// cFilterToBasicFilter converts cFilter of kind basic to types.BasicFilter
+func cFilterToBasicFilter(f *cFilter) (*types.BasicFilter, error) {
+	bb := types.NewBasicFilterBuilder().
+		WithChain(f.Chain).
+		WithProtocol(sToFilterProtocol(f.Protocol)).
+		WithPriority(f.Priority).
+		WithHandle(uint32(f.Options.Handle))
+
+	actions, err := cActionsToActions(f.Options.Actions)
+	if err != nil {
+		return nil, err
+	}
+	for _, act := range actions {
+		bb.WithAction(act)
+	}
+
+	return bb.Build(), nil
+}
+
+// cActionsToActions converts cActions to types.Actions ordered by their order attribute
+func cActionsToActions(cActions []cAction) ([]types.Action, error) {
+	sort.SliceStable(cActions, func(i, j int) bool { return cActions[i].Order < cActions[j].Order })
+	actions := make([]types.Action, 0, len(cActions))
+	for i := range cActions {
+		act, err := cActionToAction(&cActions[i])
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, act)
+	}
+	return actions, nil
+}
+
+// parseU32Handle parses tc u32 handle string (e.g "100:a:800") to u32 handle (see types.U32Handle)
+func parseU32Handle(handle string) (uint32, error) {
+	parts := strings.Split(handle, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("failed to parse u32 handle string: %s", handle)
+	}
+
+	var vals [3]uint32
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(p, 16, 32)
+		if err != nil {
+			return 0, err
+		}
+		vals[i] = uint32(v)
+	}
+	return types.U32Handle(vals[0], vals[1], vals[2]), nil
+}
+
+// cChainToChainTemplate converts template attributes of cChain to types.ChainTemplate
+func cChainToChainTemplate(c *cChain) (*types.ChainTemplate, error) {
+	if c.Kind != string(types.FilterKindFlower) {
+		return nil, fmt.Errorf("unexpected chain template Kind: %s", c.Kind)
+	}
+
+	fb := types.NewFlowerFilterBuilder()
+	if c.Options != nil {
+		if err := addFlowerKeys(fb, &c.Options.Keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.ChainTemplate{Protocol: sToFilterProtocol(c.Protocol), Flower: fb.Build().Flower}, nil
+}
+
+// addFlowerKeys adds flower match keys in keys to FlowerFilterBuilder
+func addFlowerKeys(fb *types.FlowerFilterBuilder, keys *cFlowerKeys) error {
+	if keys.VlanID != nil {
+		fb.WithMatchKeyVlanID(*keys.VlanID)
+	}
+	if keys.VlanEthType != nil {
+		fb.WithMatchKeyVlanEthType(sToFlowerVlanEthType(*keys.VlanEthType))
+	}
+	if keys.CVlanID != nil {
+		fb.WithMatchKeyCVlanID(*keys.CVlanID)
+	}
+	if keys.CVlanEthType != nil {
+		fb.WithMatchKeyCVlanEthType(sToFlowerVlanEthType(*keys.CVlanEthType))
+	}
+	if keys.SrcMAC != nil {
+		mac, err := net.ParseMAC(*keys.SrcMAC)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse source MAC: %s", *keys.SrcMAC)
+		}
+		fb.WithMatchKeySrcMAC(mac)
+	}
+	if keys.DstMAC != nil {
+		fm, err := sToFlowerMAC(*keys.DstMAC)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse dest MAC: %s", *keys.DstMAC)
+		}
+		fb.WithMatchKeyDstMAC(fm.Addr, fm.Mask)
+	}
+	if keys.IPProto != nil {
+		fb.WithMatchKeyIPProto(sToFlowerIPProto(*keys.IPProto))
+	}
+	if keys.SrcIP != nil {
+		ipn, err := utils.IPToIPNet(*keys.SrcIP)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse source IP: %s", *keys.SrcIP)
+		}
+		fb.WithMatchKeySrcIP(ipn)
+	}
+	if keys.DstIP != nil {
+		ipn, err := utils.IPToIPNet(*keys.DstIP)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse dest IP: %s", *keys.DstIP)
+		}
+		fb.WithMatchKeyDstIP(ipn)
+	}
+	if keys.ArpSIP != nil {
+		ipn, err := utils.IPToIPNet(*keys.ArpSIP)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse ARP sender IP: %s", *keys.ArpSIP)
+		}
+		fb.WithMatchKeyArpSIP(ipn)
+	}
+	if keys.SrcPort != nil {
+		fb.WithMatchKeySrcPort(*keys.SrcPort)
+	}
+	if keys.DstPort != nil {
+		fb.WithMatchKeyDstPort(*keys.DstPort)
+	}
+	if keys.IPFlags != nil {
+		fb.WithMatchKeyIPFlags(cIPFlagsToFlowerIPFlags(keys.IPFlags))
+	}
+	if keys.TCPFlags != nil {
+		tf, err := sToFlowerTCPFlags(*keys.TCPFlags)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse tcp flags: %s", *keys.TCPFlags)
+		}
+		fb.WithMatchKeyTCPFlags(tf.Flags, tf.Mask)
+	}
+	if keys.ICMPType != nil {
+		fb.WithMatchKeyICMPType(*keys.ICMPType)
+	}
+	if keys.CtState != nil {
+		fb.WithMatchKeyCtState(types.FlowerCtState(*keys.CtState))
+	}
+
+	return nil
+}
+
+// cActionToAction converts cAction to types.Action
+func cActionToAction(a *cAction) (types.Action, error) {
+	switch a.Kind {
+	case string(types.ActionTypeGeneric):
+		if a.ControlAction.Type == string(types.ActionGenericGoto) && a.ControlAction.Chain != nil {
+			return types.NewGenericGotoAction(*a.ControlAction.Chain), nil
+		}
+		return types.NewGenericAction(types.ActionGenericType(a.ControlAction.Type)), nil
+	case string(types.ActionTypeConnTrack):
+		cb := types.NewConnTrackActionBuilder()
+		if a.CtAction != nil && *a.CtAction == string(types.ActionConnTrackCommit) {
+			cb.WithCommit()
+		}
+		if a.Zone != nil {
+			cb.WithZone(*a.Zone)
+		}
+		return cb.Build(), nil
+	case string(types.ActionTypePedit):
+		return cPeditKeysToPeditDSCPAction(a.Keys)
+	case string(types.ActionTypeCsum):
+		if a.Csum == nil {
+			return types.NewCsumAction(), nil
+		}
+		updates := make([]types.CsumUpdateType, 0)
+		for _, u := range strings.Split(*a.Csum, ",") {
+			updates = append(updates, types.CsumUpdateType(strings.TrimSpace(u)))
+		}
+		return types.NewCsumAction(updates...), nil
+	case string(types.ActionTypePolice):
+		return cActionToPoliceAction(a)
+	}
+	return nil, fmt.Errorf("unexpected action: %s", a.Kind)
+}
+
+// cActionToPoliceAction converts police cAction to types.PoliceAction
+func cActionToPoliceAction(a *cAction) (*types.PoliceAction, error) {
+	if a.Rate == nil || a.Burst == nil {
+		return nil, fmt.Errorf("police action without rate or burst")
+	}
+	pb := types.NewPoliceActionBuilder().WithRate(*a.Rate).WithBurst(*a.Burst)
+	switch len(a.ControlActions) {
+	case 1:
+		// Note(adrianc): conform control action is omitted if it is pass
+		pb.WithConformExceed(types.PoliceControlPass, types.PoliceControlType(a.ControlActions[0].Type))
+	case 2:
+		pb.WithConformExceed(types.PoliceControlType(a.ControlActions[1].Type),
+			types.PoliceControlType(a.ControlActions[0].Type))
+	default:
+		return nil, fmt.Errorf("unexpected police action control actions: %+v", a.ControlActions)
+	}
+	return pb.Build(), nil
+}
+
+// cPeditKeysToPeditDSCPAction converts pedit action keys to types.PeditDSCPAction. an error is returned if keys
+// do not represent setting DSCP of ipv4 or ipv6 header.
+func cPeditKeysToPeditDSCPAction(keys []cPeditKey) (*types.PeditDSCPAction, error) {
+	// DSCP is located in the first 32bit word of ipv4 (TOS) and ipv6 (traffic class) headers
+	dscpShift := map[string]uint{"ipv4": 18, "ipv6": 22}
+	headers := map[string]types.PeditHeaderType{"ipv4": types.PeditHeaderIPv4, "ipv6": types.PeditHeaderIPv6}
+
+	if len(keys) != 1 || keys[0].Offset != 0 || (keys[0].Cmd != "" && keys[0].Cmd != "set") {
+		return nil, fmt.Errorf("unsupported pedit action keys: %+v", keys)
+	}
+	shift, ok := dscpShift[keys[0].HType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported pedit action header type: %s", keys[0].HType)
+	}
+
+	parseHex := func(s string) (uint32, error) {
+		val, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 32)
+		return uint32(val), err
+	}
+	val, err := parseHex(keys[0].Val)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse pedit action value: %s", keys[0].Val)
+	}
+	mask, err := parseHex(keys[0].Mask)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse pedit action mask: %s", keys[0].Mask)
+	}
+	// pedit mask holds the bits which are retained
+	if ^mask != uint32(types.DSCPMax)<<shift {
+		return nil, fmt.Errorf("unsupported pedit action mask: %s", keys[0].Mask)
+	}
+
+	return types.NewPeditDSCPActionBuilder().
+		WithHeader(headers[keys[0].HType]).
+		WithDSCP(uint8(val >> shift & uint32(types.DSCPMax))).
+		Build(), nil
+}
@@ -1,5 +1,11 @@
 package cmdline
 
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
 type cQDisc struct {
 	Kind   string `json:"kind"`
 	Handle string `json:"handle"`
@@ -9,6 +15,10 @@ type cQDisc struct {
 type cChain struct {
 	Parent string `json:"parent"`
 	Chain  uint32 `json:"chain"`
+	// template specific attributes
+	Kind     string          `json:"kind,omitempty"`
+	Protocol string          `json:"protocol,omitempty"`
+	Options  *cFilterOptions `json:"options,omitempty"`
 }
 
 type cFilter struct {
@@ -20,24 +30,166 @@ type cFilter struct {
 }
 
 type cFilterOptions struct {
-	Handle  uint32      `json:"handle"`
+	Handle  cHandle     `json:"handle"`
+	SkipHW  bool        `json:"skip_hw,omitempty"`
 	Keys    cFlowerKeys `json:"keys"`
 	Actions []cAction   `json:"actions"`
+	// u32 filter specific attributes
+	U32Handle string `json:"fh,omitempty"`
+	Divisor   uint32 `json:"ht_divisor,omitempty"`
+	HashTable string `json:"key_ht,omitempty"`
+	Bucket    string `json:"bkt,omitempty"`
+	Link      string `json:"link,omitempty"`
+	HashMask  string `json:"hash_mask,omitempty"`
+	HashOff   int32  `json:"hash_off,omitempty"`
+	// Matches holds all match attributes of u32 filter in order
+	Matches []cU32Match `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+// Note(adrianc): u32 filter has a match attribute per u32 match, they are all kept in Matches.
+func (o *cFilterOptions) UnmarshalJSON(data []byte) error {
+	type options cFilterOptions
+	if err := json.Unmarshal(data, (*options)(o)); err != nil {
+		return err
+	}
+
+	return decodeRepeatedKey(data, "match", func(dec *json.Decoder) error {
+		var m cU32Match
+		if err := dec.Decode(&m); err != nil {
+			return err
+		}
+		o.Matches = append(o.Matches, m)
+		return nil
+	})
+}
+
+// cHandle is a filter handle, tc prints it either as a number or as a hex string (e.g "0x1") depending on
+// filter kind
+type cHandle uint32
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (h *cHandle) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var v uint32
+		if err = json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*h = cHandle(v)
+		return nil
+	}
+
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return err
+	}
+	*h = cHandle(v)
+	return nil
+}
+
+type cU32Match struct {
+	Value string `json:"value"`
+	Mask  string `json:"mask"`
+	Off   int32  `json:"off"`
 }
 
 type cFlowerKeys struct {
-	VlanEthType *string `json:"vlan_ethtype,omitempty"`
-	IPProto     *string `json:"ip_proto,omitempty"`
-	DstIP       *string `json:"dst_ip,omitempty"`
-	DstPort     *uint16 `json:"dst_port,omitempty"`
+	VlanID       *uint16   `json:"vlan_id,omitempty"`
+	VlanEthType  *string   `json:"vlan_ethtype,omitempty"`
+	CVlanID      *uint16   `json:"cvlan_id,omitempty"`
+	CVlanEthType *string   `json:"cvlan_ethtype,omitempty"`
+	SrcMAC       *string   `json:"src_mac,omitempty"`
+	DstMAC       *string   `json:"dst_mac,omitempty"`
+	IPProto      *string   `json:"ip_proto,omitempty"`
+	SrcIP        *string   `json:"src_ip,omitempty"`
+	DstIP        *string   `json:"dst_ip,omitempty"`
+	ArpSIP       *string   `json:"arp_sip,omitempty"`
+	SrcPort      *uint16   `json:"src_port,omitempty"`
+	DstPort      *uint16   `json:"dst_port,omitempty"`
+	IPFlags      *cIPFlags `json:"ip_flags,omitempty"`
+	TCPFlags     *string   `json:"tcp_flags,omitempty"`
+	ICMPType     *uint8    `json:"icmp_type,omitempty"`
+	CtState      *string   `json:"ct_state,omitempty"`
+}
+
+type cIPFlags struct {
+	Frag      *bool `json:"frag,omitempty"`
+	FirstFrag *bool `json:"firstfrag,omitempty"`
 }
 
 type cAction struct {
 	Order         uint           `json:"order"`
 	Kind          string         `json:"kind"`
 	ControlAction cControlAction `json:"control_action"`
+	// ct action specific attributes
+	CtAction *string `json:"action,omitempty"`
+	Zone     *uint16 `json:"zone,omitempty"`
+	// pedit action specific attributes
+	Keys []cPeditKey `json:"keys,omitempty"`
+	// csum action specific attributes
+	Csum *string `json:"csum,omitempty"`
+	// police action specific attributes
+	Rate  *uint64 `json:"rate,omitempty"`
+	Burst *uint32 `json:"burst,omitempty"`
+	// ControlActions holds all control_action attributes of the action in order
+	ControlActions []cControlAction `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+// Note(adrianc): police action has two control_action attributes, for exceeding traffic followed by (an optional)
+// one for conforming traffic. they are all kept in ControlActions.
+func (a *cAction) UnmarshalJSON(data []byte) error {
+	type action cAction
+	if err := json.Unmarshal(data, (*action)(a)); err != nil {
+		return err
+	}
+
+	return decodeRepeatedKey(data, "control_action", func(dec *json.Decoder) error {
+		var ca cControlAction
+		if err := dec.Decode(&ca); err != nil {
+			return err
+		}
+		a.ControlActions = append(a.ControlActions, ca)
+		return nil
+	})
+}
+
+// decodeRepeatedKey calls decode for the value of each occurrence of key in JSON object data, in order
+func decodeRepeatedKey(data []byte, key string, decode func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// consume opening delimiter
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		k, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if k != key {
+			var val json.RawMessage
+			if err = dec.Decode(&val); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = decode(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type cPeditKey struct {
+	HType  string `json:"htype"`
+	Offset int    `json:"offset"`
+	Cmd    string `json:"cmd"`
+	Val    string `json:"val"`
+	Mask   string `json:"mask"`
 }
 
 type cControlAction struct {
-	Type string `json:"type"`
+	Type  string  `json:"type"`
+	Chain *uint32 `json:"chain,omitempty"`
 }
@@ -12,7 +12,6 @@ import (
 	"k8s.io/utils/exec"
 
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
-	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 )
 
 // NewTcCmdLineImpl creates a new instance of TcCmdLineImpl
@@ -86,8 +85,8 @@ func (t *TcCmdLineImpl) QDiscList() ([]types.QDisc, error) {
 
 	var objs []types.QDisc
 	for _, q := range cQdiscs {
-		if q.Kind != string(types.QDiscIngressType) {
-			// skip non-ingress qdiscs
+		if q.Kind != string(types.QDiscIngressType) && q.Kind != string(types.QDiscClsactType) {
+			// skip non ingress or clsact qdiscs
 			continue
 		}
 		handle, err := parseMajorMinor(q.Handle)
@@ -98,7 +97,12 @@ func (t *TcCmdLineImpl) QDiscList() ([]types.QDisc, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to parse qdisc Parent")
 		}
-		qdisc := types.NewIngressQDiscBuilder().WithParent(parent).WithHandle(handle).Build()
+		var qdisc types.QDisc
+		if q.Kind == string(types.QDiscClsactType) {
+			qdisc = types.NewClsactQDiscBuilder().WithParent(parent).WithHandle(handle).Build()
+		} else {
+			qdisc = types.NewIngressQDiscBuilder().WithParent(parent).WithHandle(handle).Build()
+		}
 		objs = append(objs, qdisc)
 	}
 	return objs, nil
@@ -106,24 +110,57 @@ func (t *TcCmdLineImpl) QDiscList() ([]types.QDisc, error) {
 
 // FilterAdd implements TC interface
 func (t *TcCmdLineImpl) FilterAdd(qdisc types.QDisc, filter types.Filter) error {
+	hook, err := hookArgs(qdisc, filter.Attrs().Parent)
+	if err != nil {
+		return err
+	}
 	args := []string{"filter", "add", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
+	args = append(args, hook...)
 	args = append(args, filter.GenCmdLineArgs()...)
 	return t.execTcCmdNoOutput(args)
 }
 
 // FilterDel implements TC interface
 func (t *TcCmdLineImpl) FilterDel(qdisc types.QDisc, filterAttr *types.FilterAttrs) error {
+	hook, err := hookArgs(qdisc, filterAttr.Parent)
+	if err != nil {
+		return err
+	}
 	args := []string{"filter", "del", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
+	args = append(args, hook...)
 	args = append(args, filterAttr.GenCmdLineArgs()...)
 	return t.execTcCmdNoOutput(args)
 }
 
-// FilterList implements TC interface
+// FilterList implements TC interface. for clsact qdisc, filters of both hooks are listed with their Parent set
 func (t *TcCmdLineImpl) FilterList(qdisc types.QDisc) ([]types.Filter, error) {
+	if qdisc.Type() != types.QDiscClsactType {
+		return t.filterList(qdisc.GenCmdLineArgs())
+	}
+
+	var objs []types.Filter
+	for _, parent := range []uint32{types.ParentIngress, types.ParentEgress} {
+		p := parent
+		hook, err := hookArgs(qdisc, &p)
+		if err != nil {
+			return nil, err
+		}
+		filters, err := t.filterList(hook)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range filters {
+			f.Attrs().Parent = &p
+		}
+		objs = append(objs, filters...)
+	}
+	return objs, nil
+}
+
+// filterList lists filters of the hook identified by hook args
+func (t *TcCmdLineImpl) filterList(hook []string) ([]types.Filter, error) {
 	args := []string{"filter", "list", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
+	args = append(args, hook...)
 	out, err := t.execTcCmd(args)
 	if err != nil {
 		return nil, err
@@ -135,72 +172,109 @@ func (t *TcCmdLineImpl) FilterList(qdisc types.QDisc) ([]types.Filter, error) {
 		return nil, err
 	}
 
+	// root hash tables of u32 priorities are created by the kernel, filters are listed with no hash table
+	// if they are in a root hash table
+	rootHashTables := make(map[uint32]struct{})
+	for _, f := range cFilters {
+		if isU32RootHashTable(&f) {
+			handle, err := parseU32Handle(f.Options.U32Handle)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to parse u32 hash table handle")
+			}
+			rootHashTables[handle>>20] = struct{}{}
+		}
+	}
+
 	var objs []types.Filter
 	for _, f := range cFilters {
 		// skip filters with no Options
 		if f.Options == nil {
 			continue
 		}
-		if f.Kind != string(types.FilterKindFlower) {
-			return nil, fmt.Errorf("unexpected filter Kind: %s", f.Kind)
-		}
-
-		fb := types.NewFlowerFilterBuilder().
-			WithChain(f.Chain).
-			WithProtocol(sToFilterProtocol(f.Protocol)).
-			WithPriority(f.Priority).
-			WithHandle(f.Options.Handle)
 
-		if f.Options.Keys.VlanEthType != nil {
-			fb.WithMatchKeyVlanEthType(sToFlowerVlanEthType(*f.Options.Keys.VlanEthType))
-		}
-		if f.Options.Keys.IPProto != nil {
-			fb.WithMatchKeyIPProto(sToFlowerIPProto(*f.Options.Keys.IPProto))
-		}
-		if f.Options.Keys.DstIP != nil {
-			ipn, err := utils.IPToIPNet(*f.Options.Keys.DstIP)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse dest IP: %s", *f.Options.Keys.DstIP)
+		var filter types.Filter
+		var err error
+		switch f.Kind {
+		case string(types.FilterKindFlower):
+			filter, err = cFilterToFlowerFilter(&f)
+		case string(types.FilterKindU32):
+			if isU32RootHashTable(&f) {
+				continue
 			}
-			fb.WithMatchKeyDstIP(ipn)
-		}
-		if f.Options.Keys.DstPort != nil {
-			fb.WithMatchKeyDstPort(*f.Options.Keys.DstPort)
+			filter, err = cFilterToU32Filter(&f, rootHashTables)
+		case string(types.FilterKindMatchAll):
+			filter, err = cFilterToMatchAllFilter(&f)
+		case string(types.FilterKindBasic):
+			filter, err = cFilterToBasicFilter(&f)
+		default:
+			return nil, fmt.Errorf("unexpected filter Kind: %s", f.Kind)
 		}
-
-		for _, a := range f.Options.Actions {
-			// TODO(adrianc): sort first by Order, ATM only one action is expected
-			if a.Kind != string(types.ActionTypeGeneric) {
-				return nil, fmt.Errorf("unexpected action: %s", a.Kind)
-			}
-			act := types.NewGenericAction(types.ActionGenericType(a.ControlAction.Type))
-			fb.WithAction(act)
+		if err != nil {
+			return nil, err
 		}
-		objs = append(objs, fb.Build())
+		objs = append(objs, filter)
 	}
 	return objs, nil
 }
 
+// isU32RootHashTable returns true if f is the root hash table of a u32 priority.
+// Note(adrianc): root hash tables have a single bucket, hash tables with a single bucket are not expected otherwise
+func isU32RootHashTable(f *cFilter) bool {
+	return f.Kind == string(types.FilterKindU32) && f.Options != nil && f.Options.Divisor == 1
+}
+
 // ChainAdd implements TC interface
 func (t *TcCmdLineImpl) ChainAdd(qdisc types.QDisc, chain types.Chain) error {
+	hook, err := hookArgs(qdisc, chain.Attrs().Parent)
+	if err != nil {
+		return err
+	}
 	args := []string{"chain", "add", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
-	args = append(args, chain.GenCmdLineArgs()...)
+	args = append(args, hook...)
+	args = append(args, chainCmdLineArgs(chain)...)
 	return t.execTcCmdNoOutput(args)
 }
 
 // ChainDel implements TC interface
 func (t *TcCmdLineImpl) ChainDel(qdisc types.QDisc, chain types.Chain) error {
+	hook, err := hookArgs(qdisc, chain.Attrs().Parent)
+	if err != nil {
+		return err
+	}
 	args := []string{"chain", "del", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
-	args = append(args, chain.GenCmdLineArgs()...)
+	args = append(args, hook...)
+	args = append(args, chainCmdLineArgs(chain)...)
 	return t.execTcCmdNoOutput(args)
 }
 
-// ChainList implements TC interface
+// ChainList implements TC interface. for clsact qdisc, chains of both hooks are listed with their Parent set
+// to the hook
 func (t *TcCmdLineImpl) ChainList(qdisc types.QDisc) ([]types.Chain, error) {
+	if qdisc.Type() != types.QDiscClsactType {
+		return t.chainList(qdisc.GenCmdLineArgs(), nil)
+	}
+
+	var objs []types.Chain
+	for _, parent := range []uint32{types.ParentIngress, types.ParentEgress} {
+		p := parent
+		hook, err := hookArgs(qdisc, &p)
+		if err != nil {
+			return nil, err
+		}
+		chains, err := t.chainList(hook, &p)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, chains...)
+	}
+	return objs, nil
+}
+
+// chainList lists chains of the hook identified by hook args. if hookParent is provided, it is set as the
+// Parent of listed chains
+func (t *TcCmdLineImpl) chainList(hook []string, hookParent *uint32) ([]types.Chain, error) {
 	args := []string{"chain", "list", "dev", t.netDev}
-	args = append(args, qdisc.GenCmdLineArgs()...)
+	args = append(args, hook...)
 	out, err := t.execTcCmd(args)
 	if err != nil {
 		return nil, err
@@ -218,11 +292,45 @@ func (t *TcCmdLineImpl) ChainList(qdisc types.QDisc) ([]types.Chain, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to parse Chain Parent")
 		}
-		objs = append(objs, types.NewChainBuilder().WithChain(c.Chain).WithParent(parent).Build())
+		if hookParent != nil {
+			parent = *hookParent
+		}
+		cb := types.NewChainBuilder().WithChain(c.Chain).WithParent(parent)
+		if c.Kind != "" {
+			template, err := cChainToChainTemplate(&c)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to parse Chain template")
+			}
+			cb.WithTemplate(template.Protocol, template.Flower)
+		}
+		objs = append(objs, cb.Build())
 	}
 	return objs, nil
 }
 
+// hookArgs returns the tc command line args which identify the qdisc hook that objects with the given parent are
+// attached to. egress hook is only supported by clsact qdisc.
+func hookArgs(qdisc types.QDisc, parent *uint32) ([]string, error) {
+	switch {
+	case qdisc.Type() == types.QDiscClsactType && types.HookOf(parent) == types.ParentEgress:
+		return []string{"egress"}, nil
+	case qdisc.Type() == types.QDiscClsactType:
+		return []string{"ingress"}, nil
+	case types.HookOf(parent) == types.ParentEgress:
+		return nil, fmt.Errorf("egress hook is not supported by %s qdisc", qdisc.Type())
+	}
+	return qdisc.GenCmdLineArgs(), nil
+}
+
+// chainCmdLineArgs returns the command line args of chain without its Parent, the parent is provided by hookArgs
+// as tc does not accept both.
+func chainCmdLineArgs(chain types.Chain) []string {
+	attrs := *chain.Attrs()
+	attrs.Parent = nil
+	c := &types.ChainImpl{ChainAttrs: attrs}
+	return c.GenCmdLineArgs()
+}
+
 // parseMajorMinor parses TC string Handle and Parent. for a given format the following output is expected as depicted
 // below.
 //
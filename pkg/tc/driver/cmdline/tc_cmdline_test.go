@@ -2,6 +2,7 @@ package cmdline_test
 
 import (
 	"net"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -183,6 +184,20 @@ var _ = Describe("TC Cmdline driver tests", func() {
 
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("adds chain with template", func() {
+			fakeCmd.RunScript = append(fakeCmd.RunScript, newFakeAction(nil, nil, nil))
+			templateChain := tctypes.NewChainBuilder().WithChain(99).WithTemplate(tctypes.FilterProtocolIPv4,
+				tctypes.NewFlowerFilterBuilder().WithMatchKeyIPProto(tctypes.FlowerIPProtoUDP).Build().Flower).Build()
+			expectedArgs := []string{"tc", "-json", "chain", "add", "dev", fakeNetDev}
+			expectedArgs = append(expectedArgs, ingressQdisc.GenCmdLineArgs()...)
+			expectedArgs = append(expectedArgs, "chain", "99", "protocol", "ip", "flower", "ip_proto", "udp")
+
+			err := tcCmdLine.ChainAdd(ingressQdisc, templateChain)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeCmd.Argv).To(BeEquivalentTo(expectedArgs))
+		})
 	})
 
 	Context("ChainDel", func() {
@@ -241,6 +256,37 @@ var _ = Describe("TC Cmdline driver tests", func() {
 			Expect(chains[0]).To(BeEquivalentTo(expectedChain))
 		})
 
+		It("returns chain with template", func() {
+			out := `[{"parent": "ffff:", "chain": 99, "kind": "flower", "protocol": "ip",
+				"options": {"keys": {"ip_proto": "tcp", "dst_ip": "0.0.0.0/24", "dst_port": 0}}}]`
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(out), nil, nil))
+			_, dstIP, _ := net.ParseCIDR("0.0.0.0/24")
+			expectedChain := tctypes.NewChainBuilder().
+				WithParent(0xffff).
+				WithChain(99).
+				WithTemplate(tctypes.FilterProtocolIPv4, tctypes.NewFlowerFilterBuilder().
+					WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+					WithMatchKeyDstIP(dstIP).
+					WithMatchKeyDstPort(0).
+					Build().Flower).
+				Build()
+
+			chains, err := tcCmdLine.ChainList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chains).To(HaveLen(1))
+			Expect(chains[0].Attrs().Template.Equals(expectedChain.Template)).To(BeTrue())
+		})
+
+		It("returns error for chain with unsupported template kind", func() {
+			out := `[{"parent": "ffff:", "chain": 99, "kind": "u32"}]`
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(out), nil, nil))
+
+			_, err := tcCmdLine.ChainList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+
 		It("retuns error when underlying command errors", func() {
 			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
 				nil, nil, testError))
@@ -457,4 +503,947 @@ var _ = Describe("TC Cmdline driver tests", func() {
 			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
 		})
 	})
+
+	Context("filterList with icmpv6 filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ipv6",
+    "pref": 51,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv6",
+        "ip_proto": "icmpv6",
+        "icmp_type": 135
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv6).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoICMPv6).
+				WithMatchKeyICMPType(135).
+				WithPriority(51).
+				WithHandle(1).
+				WithChain(0).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with 802.1ad filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "802.1ad",
+    "pref": 205,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "vlan_ethtype": "802.1Q",
+        "cvlan_id": 10,
+        "cvlan_ethtype": "ip",
+        "eth_type": "ipv4"
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021AD).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthType8021Q).
+				WithMatchKeyCVlanID(10).
+				WithMatchKeyCVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
+				WithPriority(205).
+				WithHandle(1).
+				WithChain(0).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with anti-spoofing filters", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 10,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv4",
+        "src_mac": "aa:bb:cc:dd:ee:01",
+        "src_ip": "10.10.10.2"
+      },
+      "in_hw": true,
+      "in_hw_count": 1,
+      "actions": [
+        {
+          "order": 1,
+          "kind": "gact",
+          "control_action": {
+            "type": "goto",
+            "chain": 1
+          },
+          "index": 1,
+          "ref": 1,
+          "bind": 1
+        }
+      ]
+    }
+  },
+  {
+    "protocol": "arp",
+    "pref": 13,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "arp",
+        "arp_sip": "10.10.10.2"
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filters", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+			expectedIPFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(10).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeySrcMAC(mac).
+				WithMatchKeySrcIP(ipToIpNet("10.10.10.2/32")).
+				WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(1).Build()).
+				Build()
+			expectedARPFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolARP).
+				WithPriority(13).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyArpSIP(ipToIpNet("10.10.10.2/32")).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(2))
+			Expect(filters[0].Equals(expectedIPFilter)).To(BeTrue())
+			Expect(filters[1].Equals(expectedARPFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with connection tracking filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 200,
+    "kind": "flower",
+    "chain": 2,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv4",
+        "ct_state": "+trk+new",
+        "dst_ip": "10.10.10.2"
+      },
+      "in_hw": true,
+      "in_hw_count": 1,
+      "actions": [
+        {
+          "order": 2,
+          "kind": "gact",
+          "control_action": {
+            "type": "pass"
+          },
+          "index": 2,
+          "ref": 1,
+          "bind": 1
+        },
+        {
+          "order": 1,
+          "kind": "ct",
+          "action": "commit",
+          "zone": 3,
+          "control_action": {
+            "type": "pipe"
+          },
+          "index": 1,
+          "ref": 1,
+          "bind": 1
+        }
+      ]
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter with actions sorted by order", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(200).
+				WithHandle(1).
+				WithChain(2).
+				WithMatchKeyCtState(tctypes.FlowerCtStateNew).
+				WithMatchKeyDstIP(ipToIpNet("10.10.10.2/32")).
+				WithAction(tctypes.NewConnTrackActionBuilder().WithCommit().WithZone(3).Build()).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with police filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "all",
+    "pref": 4,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {},
+      "in_hw": true,
+      "in_hw_count": 1,
+      "actions": [
+        {
+          "order": 1,
+          "kind": "police",
+          "index": 1,
+          "control_action": {
+            "type": "drop"
+          },
+          "control_action": {
+            "type": "pipe"
+          },
+          "overhead": 0,
+          "rate": 1250000,
+          "burst": 65536,
+          "ref": 1,
+          "bind": 1
+        },
+        {
+          "order": 2,
+          "kind": "gact",
+          "control_action": {
+            "type": "goto",
+            "chain": 3
+          },
+          "index": 1,
+          "ref": 1,
+          "bind": 1
+        }
+      ]
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(4).
+				WithHandle(1).
+				WithChain(0).
+				WithAction(tctypes.NewPoliceAction(1250000, 65536, tctypes.PoliceControlPipe, tctypes.PoliceControlDrop)).
+				WithAction(tctypes.NewGenericGotoAction(3)).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+
+		It("returns filter with pass conform action if conform action is omitted", func() {
+			out := strings.Replace(filterListOut, `,
+          "control_action": {
+            "type": "pipe"
+          }`, "", 1)
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(out), nil, nil))
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].(*tctypes.FlowerFilter).Actions[0].Equals(
+				tctypes.NewPoliceAction(1250000, 65536, tctypes.PoliceControlPass, tctypes.PoliceControlDrop))).
+				To(BeTrue())
+		})
+
+		It("returns error if police action has no rate", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, `"rate": 1250000,`, "", 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("filterList with dscp marking filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 200,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv4",
+        "dst_ip": "10.10.10.2"
+      },
+      "in_hw": true,
+      "in_hw_count": 1,
+      "actions": [
+        {
+          "order": 1,
+          "kind": "pedit",
+          "control_action": {
+            "type": "pipe"
+          },
+          "nkeys": 1,
+          "index": 1,
+          "ref": 1,
+          "bind": 1,
+          "keys": [
+            {
+              "htype": "ipv4",
+              "offset": 0,
+              "cmd": "set",
+              "val": "b80000",
+              "mask": "ff03ffff"
+            }
+          ]
+        },
+        {
+          "order": 2,
+          "kind": "csum",
+          "csum": "iph",
+          "control_action": {
+            "type": "pipe"
+          },
+          "index": 1,
+          "ref": 1,
+          "bind": 1
+        },
+        {
+          "order": 3,
+          "kind": "gact",
+          "control_action": {
+            "type": "pass"
+          },
+          "index": 2,
+          "ref": 1,
+          "bind": 1
+        }
+      ]
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(200).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyDstIP(ipToIpNet("10.10.10.2/32")).
+				WithAction(tctypes.NewPeditDSCPAction(tctypes.PeditHeaderIPv4, 46)).
+				WithAction(tctypes.NewCsumAction(tctypes.CsumUpdateIPv4Header)).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+
+		It("returns expected filter for ipv6 dscp marking", func() {
+			out := strings.NewReplacer(`"htype": "ipv4"`, `"htype": "ipv6"`, `"b80000"`, `"0x2800000"`,
+				`"ff03ffff"`, `"f03fffff"`).Replace(filterListOut)
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(out), nil, nil))
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].(*tctypes.FlowerFilter).Actions[0].Equals(
+				tctypes.NewPeditDSCPAction(tctypes.PeditHeaderIPv6, 10))).To(BeTrue())
+		})
+
+		It("returns error if pedit action does not set dscp", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, `"ff03ffff"`, `"ff00ffff"`, 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("filterList with vlan id filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "802.1Q",
+    "pref": 202,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "vlan_id": 100,
+        "vlan_ethtype": "ip"
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021Q).
+				WithPriority(202).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyVlanID(100).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with dst mac filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 50,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "dst_mac": "01:00:00:00:00:00/01:00:00:00:00:00",
+        "eth_type": "ipv4",
+        "ip_proto": "02"
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+		mcast := net.HardwareAddr{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(50).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyDstMAC(mcast, mcast).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoIGMP).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+
+		It("returns expected filter if mask is a prefix length", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, "01:00:00:00:00:00/01:00:00:00:00:00", "33:33:00:00:00:00/16", 1)),
+				nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(50).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyDstMAC(net.HardwareAddr{0x33, 0x33, 0, 0, 0, 0}, net.HardwareAddr{0xff, 0xff, 0, 0, 0, 0}).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoIGMP).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+
+		It("returns error if dst mac is malformed", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, "/01:00:00:00:00:00", "/99", 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("filterList with ip flags filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 150,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv4",
+        "ip_flags": {
+          "frag": true,
+          "firstfrag": false
+        }
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(150).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyIPFlags(tctypes.FlowerIPFlagsNoFirstFrag).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+	})
+
+	Context("filterList with tcp flags filter", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {
+    "protocol": "ip",
+    "pref": 250,
+    "kind": "flower",
+    "chain": 0,
+    "options": {
+      "handle": 1,
+      "keys": {
+        "eth_type": "ipv4",
+        "ip_proto": "tcp",
+        "tcp_flags": "0x10/10"
+      },
+      "in_hw": true,
+      "in_hw_count": 1
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filter", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(250).
+				WithHandle(1).
+				WithChain(0).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+				WithMatchKeyTCPFlags(tctypes.TCPFlagACK, tctypes.TCPFlagACK).
+				Build()
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(expectedFilter)).To(BeTrue())
+		})
+
+		It("returns error if tcp flags are malformed", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, "0x10/10", "0x10/zz", 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("filterList with u32 filters", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {"protocol": "ip", "pref": 206, "kind": "u32", "chain": 0},
+  {"protocol": "ip", "pref": 206, "kind": "u32", "chain": 0, "options": {"fh": "100:", "ht_divisor": 256}},
+  {
+    "protocol": "ip", "pref": 206, "kind": "u32", "chain": 0,
+    "options": {
+      "fh": "100:a:800", "order": 2048, "key_ht": "100", "bkt": "a", "not_in_hw": true,
+      "match": {"value": "a00000a", "mask": "ffffffff", "offmask": "", "off": 16},
+      "actions": [{"order": 1, "kind": "gact", "control_action": {"type": "pass"}}]
+    }
+  },
+  {"protocol": "ip", "pref": 206, "kind": "u32", "chain": 0, "options": {"fh": "800:", "ht_divisor": 1}},
+  {
+    "protocol": "ip", "pref": 206, "kind": "u32", "chain": 0,
+    "options": {
+      "fh": "800::800", "order": 2048, "key_ht": "800", "bkt": "0", "link": "100:", "not_in_hw": true,
+      "match": {"value": "0", "mask": "0", "offmask": "", "off": 0},
+      "hash_mask": "ff", "hash_off": 16
+    }
+  },
+  {"protocol": "ipv6", "pref": 207, "kind": "u32", "chain": 0, "options": {"fh": "801:", "ht_divisor": 1}},
+  {
+    "protocol": "ipv6", "pref": 207, "kind": "u32", "chain": 0,
+    "options": {
+      "fh": "801::800", "order": 2048, "key_ht": "801", "bkt": "0", "skip_hw": true, "not_in_hw": true,
+      "match": {"value": "20010000", "mask": "ffffffff", "offmask": "", "off": 24},
+      "match": {"value": "0", "mask": "ffffffff", "offmask": "", "off": 28},
+      "match": {"value": "0", "mask": "ffffffff", "offmask": "", "off": 32},
+      "match": {"value": "1", "mask": "ffffffff", "offmask": "", "off": 36},
+      "actions": [{"order": 1, "kind": "gact", "control_action": {"type": "drop"}}]
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filters without root hash tables", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			u32Filter := func(proto tctypes.FilterProtocol, prio uint16) *tctypes.U32FilterBuilder {
+				return tctypes.NewU32FilterBuilder().WithProtocol(proto).WithChain(0).WithPriority(prio)
+			}
+			expectedFilters := []tctypes.Filter{
+				u32Filter(tctypes.FilterProtocolIPv4, 206).WithDivisor(0x100, 256).Build(),
+				u32Filter(tctypes.FilterProtocolIPv4, 206).
+					WithHashTable(0x100, 0xa).
+					WithMatchDstIP(ipToIpNet("10.0.0.10")).
+					WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+					Build(),
+				u32Filter(tctypes.FilterProtocolIPv4, 206).
+					WithMatch(0, 0, 0).
+					WithHashKey(0xff, 16).
+					WithLink(0x100).
+					Build(),
+				u32Filter(tctypes.FilterProtocolIPv6, 207).
+					WithMatchDstIP(ipToIpNet("2001::1")).
+					WithSkipHW().
+					WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+					Build(),
+			}
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(len(expectedFilters)))
+			for i := range expectedFilters {
+				Expect(filters[i].Equals(expectedFilters[i])).To(BeTrue())
+			}
+			Expect(*filters[1].Attrs().Handle).To(Equal(tctypes.U32Handle(0x100, 0xa, 0x800)))
+		})
+
+		It("returns error if u32 match is malformed", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, "a00000a", "zz", 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("filterList with matchall and basic filters", func() {
+		var fakeCmd *testingexec.FakeCmd
+		ingressQdisc := tctypes.NewIngressQDiscBuilder().Build()
+		filterListOut := `[
+  {"protocol": "all", "pref": 300, "kind": "matchall", "chain": 0},
+  {
+    "protocol": "all", "pref": 300, "kind": "matchall", "chain": 0,
+    "options": {
+      "handle": 1, "skip_hw": true, "not_in_hw": true,
+      "actions": [{"order": 1, "kind": "gact", "control_action": {"type": "drop"}}]
+    }
+  },
+  {"protocol": "ip", "pref": 301, "kind": "basic", "chain": 1},
+  {
+    "protocol": "ip", "pref": 301, "kind": "basic", "chain": 1,
+    "options": {
+      "handle": "0x1",
+      "actions": [{"order": 1, "kind": "gact", "control_action": {"type": "pass"}}]
+    }
+  }
+]`
+
+		BeforeEach(func() {
+			fakeCmd = fakeExec.AddFakeCmd()
+		})
+
+		It("returns expected filters", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+			expectedFilters := []tctypes.Filter{
+				tctypes.NewMatchAllFilterBuilder().
+					WithProtocol(tctypes.FilterProtocolAll).
+					WithChain(0).
+					WithPriority(300).
+					WithSkipHW().
+					WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+					Build(),
+				tctypes.NewBasicFilterBuilder().
+					WithProtocol(tctypes.FilterProtocolIPv4).
+					WithChain(1).
+					WithPriority(301).
+					WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+					Build(),
+			}
+
+			filters, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filters).To(HaveLen(len(expectedFilters)))
+			for i := range expectedFilters {
+				Expect(filters[i].Equals(expectedFilters[i])).To(BeTrue())
+				Expect(*filters[i].Attrs().Handle).To(Equal(uint32(1)))
+			}
+		})
+
+		It("returns error if handle is malformed", func() {
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction(
+				[]byte(strings.Replace(filterListOut, "0x1", "0xzz", 1)), nil, nil))
+
+			_, err := tcCmdLine.FilterList(ingressQdisc)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("adds and deletes matchall filter", func() {
+			fakeCmd.RunScript = append(fakeCmd.RunScript, newFakeAction(nil, nil, nil))
+			delCmd := fakeExec.AddFakeCmd()
+			delCmd.RunScript = append(delCmd.RunScript, newFakeAction(nil, nil, nil))
+			filter := tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithHandle(1).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build()
+
+			Expect(tcCmdLine.FilterAdd(ingressQdisc, filter)).To(Succeed())
+			Expect(tcCmdLine.FilterDel(ingressQdisc, filter.Attrs())).To(Succeed())
+
+			Expect(fakeCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "add", "dev", fakeNetDev, "ingress",
+				"protocol", "all", "handle", "1", "pref", "300", "matchall", "action", "gact", "drop"}))
+			Expect(delCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "del", "dev", fakeNetDev, "ingress",
+				"protocol", "all", "handle", "1", "pref", "300", "matchall"}))
+		})
+	})
+
+	Context("clsact qdisc", func() {
+		clsactQdisc := tctypes.NewClsactQDiscBuilder().Build()
+		egressFilter := tctypes.NewMatchAllFilterBuilder().
+			WithProtocol(tctypes.FilterProtocolAll).
+			WithPriority(300).
+			WithParent(tctypes.ParentEgress).
+			WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+			Build()
+		filterListOut := `[
+  {"protocol": "all", "pref": 300, "kind": "matchall", "chain": 0},
+  {
+    "protocol": "all", "pref": 300, "kind": "matchall", "chain": 0,
+    "options": {"handle": 1, "actions": [{"order": 1, "kind": "gact", "control_action": {"type": "drop"}}]}
+  }
+]`
+
+		It("lists clsact qdisc", func() {
+			fakeCmd := fakeExec.AddFakeCmd()
+			out := `[{"kind":"clsact","handle":"ffff:","parent":"ffff:fff1","options":{}}]`
+			fakeCmd.OutputScript = append(fakeCmd.OutputScript, newFakeAction([]byte(out), nil, nil))
+
+			qdiscs, err := tcCmdLine.QDiscList()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qdiscs).To(HaveLen(1))
+			Expect(qdiscs[0]).To(BeEquivalentTo(
+				tctypes.NewClsactQDiscBuilder().WithParent(0xfffffff1).WithHandle(0xffff).Build()))
+		})
+
+		It("adds and deletes filters on the filter hook", func() {
+			addCmd := fakeExec.AddFakeCmd()
+			addCmd.RunScript = append(addCmd.RunScript, newFakeAction(nil, nil, nil))
+			delCmd := fakeExec.AddFakeCmd()
+			delCmd.RunScript = append(delCmd.RunScript, newFakeAction(nil, nil, nil))
+			ingressCmd := fakeExec.AddFakeCmd()
+			ingressCmd.RunScript = append(ingressCmd.RunScript, newFakeAction(nil, nil, nil))
+			ingressFilter := tctypes.NewMatchAllFilterBuilder().WithProtocol(tctypes.FilterProtocolAll).Build()
+
+			Expect(tcCmdLine.FilterAdd(clsactQdisc, egressFilter)).To(Succeed())
+			Expect(tcCmdLine.FilterDel(clsactQdisc, egressFilter.Attrs())).To(Succeed())
+			Expect(tcCmdLine.FilterAdd(clsactQdisc, ingressFilter)).To(Succeed())
+
+			Expect(addCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "add", "dev", fakeNetDev, "egress",
+				"protocol", "all", "pref", "300", "matchall", "action", "gact", "drop"}))
+			Expect(delCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "del", "dev", fakeNetDev, "egress",
+				"protocol", "all", "pref", "300", "matchall"}))
+			Expect(ingressCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "add", "dev", fakeNetDev, "ingress",
+				"protocol", "all", "matchall"}))
+		})
+
+		It("returns error when adding egress filter on ingress qdisc", func() {
+			err := tcCmdLine.FilterAdd(tctypes.NewIngressQDiscBuilder().Build(), egressFilter)
+
+			Expect(err).To(HaveOccurred())
+			Expect(fakeExec.CommandCalls).To(Equal(0))
+		})
+
+		It("lists filters of both hooks", func() {
+			ingressCmd := fakeExec.AddFakeCmd()
+			ingressCmd.OutputScript = append(ingressCmd.OutputScript, newFakeAction([]byte(`[]`), nil, nil))
+			egressCmd := fakeExec.AddFakeCmd()
+			egressCmd.OutputScript = append(egressCmd.OutputScript, newFakeAction([]byte(filterListOut), nil, nil))
+
+			filters, err := tcCmdLine.FilterList(clsactQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ingressCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "list", "dev", fakeNetDev, "ingress"}))
+			Expect(egressCmd.Argv).To(Equal([]string{"tc", "-json", "filter", "list", "dev", fakeNetDev, "egress"}))
+			Expect(filters).To(HaveLen(1))
+			Expect(filters[0].Equals(egressFilter)).To(BeTrue())
+			Expect(*filters[0].Attrs().Parent).To(Equal(tctypes.ParentEgress))
+		})
+
+		It("adds chain on the chain hook without parent", func() {
+			fakeCmd := fakeExec.AddFakeCmd()
+			fakeCmd.RunScript = append(fakeCmd.RunScript, newFakeAction(nil, nil, nil))
+			chain := tctypes.NewChainBuilder().WithParent(tctypes.ParentEgress).WithChain(99).Build()
+
+			Expect(tcCmdLine.ChainAdd(clsactQdisc, chain)).To(Succeed())
+			Expect(fakeCmd.Argv).To(Equal([]string{"tc", "-json", "chain", "add", "dev", fakeNetDev, "egress",
+				"chain", "99"}))
+		})
+
+		It("lists chains of both hooks", func() {
+			ingressCmd := fakeExec.AddFakeCmd()
+			ingressCmd.OutputScript = append(ingressCmd.OutputScript, newFakeAction(
+				[]byte(`[{"parent": "ffff:fff2", "chain": 0}]`), nil, nil))
+			egressCmd := fakeExec.AddFakeCmd()
+			egressCmd.OutputScript = append(egressCmd.OutputScript, newFakeAction(
+				[]byte(`[{"parent": "ffff:fff3", "chain": 99}]`), nil, nil))
+
+			chains, err := tcCmdLine.ChainList(clsactQdisc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(egressCmd.Argv).To(Equal([]string{"tc", "-json", "chain", "list", "dev", fakeNetDev, "egress"}))
+			Expect(chains).To(HaveLen(2))
+			Expect(chains[0]).To(BeEquivalentTo(
+				tctypes.NewChainBuilder().WithParent(tctypes.ParentIngress).WithChain(0).Build()))
+			Expect(chains[1]).To(BeEquivalentTo(
+				tctypes.NewChainBuilder().WithParent(tctypes.ParentEgress).WithChain(99).Build()))
+		})
+	})
 })
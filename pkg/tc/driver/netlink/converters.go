@@ -3,7 +3,9 @@ package netlink
 import (
 	"fmt"
 	"net"
+	"strconv"
 
+	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netlink/nl"
 	"golang.org/x/sys/unix"
@@ -44,6 +46,10 @@ func filterProtoToUnixProto(protocol types.FilterProtocol) uint16 {
 		return unix.ETH_P_IPV6
 	case types.FilterProtocol8021Q:
 		return unix.ETH_P_8021Q
+	case types.FilterProtocol8021AD:
+		return unix.ETH_P_8021AD
+	case types.FilterProtocolARP:
+		return unix.ETH_P_ARP
 	case types.FilterProtocolAll:
 		return unix.ETH_P_ALL
 	}
@@ -61,6 +67,10 @@ func unixProtoToFilterProto(protocol uint16) types.FilterProtocol {
 		return types.FilterProtocolIPv6
 	case unix.ETH_P_8021Q:
 		return types.FilterProtocol8021Q
+	case unix.ETH_P_8021AD:
+		return types.FilterProtocol8021AD
+	case unix.ETH_P_ARP:
+		return types.FilterProtocolARP
 	case unix.ETH_P_ALL:
 		return types.FilterProtocolAll
 	}
@@ -76,6 +86,10 @@ func flowerVlanEthTypeToUnixProto(ethtype types.FlowerVlanEthType) uint16 {
 		return unix.ETH_P_IP
 	case types.FlowerVlanEthTypeIPv6:
 		return unix.ETH_P_IPV6
+	case types.FlowerVlanEthTypeARP:
+		return unix.ETH_P_ARP
+	case types.FlowerVlanEthType8021Q:
+		return unix.ETH_P_8021Q
 	}
 	// we should not get here
 	return 0
@@ -88,6 +102,10 @@ func unixProtoToFlowerVlanEthType(protocol uint16) types.FlowerVlanEthType {
 		return types.FlowerVlanEthTypeIPv4
 	case unix.ETH_P_IPV6:
 		return types.FlowerVlanEthTypeIPv6
+	case unix.ETH_P_ARP:
+		return types.FlowerVlanEthTypeARP
+	case unix.ETH_P_8021Q:
+		return types.FlowerVlanEthType8021Q
 	}
 
 	// we should not get here
@@ -101,6 +119,12 @@ func flowerIPProtoToNlIPProto(protocol types.FlowerIPProto) nl.IPProto {
 		return nl.IPPROTO_TCP
 	case types.FlowerIPProtoUDP:
 		return nl.IPPROTO_UDP
+	case types.FlowerIPProtoICMP:
+		return nl.IPPROTO_ICMP
+	case types.FlowerIPProtoICMPv6:
+		return nl.IPPROTO_ICMPV6
+	case types.FlowerIPProtoIGMP:
+		return nl.IPProto(unix.IPPROTO_IGMP)
 	}
 	return 0
 }
@@ -112,12 +136,21 @@ func nlIPProtoToFlowerIPProto(protocol nl.IPProto) types.FlowerIPProto {
 		return types.FlowerIPProtoTCP
 	case nl.IPPROTO_UDP:
 		return types.FlowerIPProtoUDP
+	case nl.IPPROTO_ICMP:
+		return types.FlowerIPProtoICMP
+	case nl.IPPROTO_ICMPV6:
+		return types.FlowerIPProtoICMPv6
+	case nl.IPProto(unix.IPPROTO_IGMP):
+		return types.FlowerIPProtoIGMP
 	}
 
 	// we should not get here
 	return types.FlowerIPProto(fmt.Sprintf("Unknown(%d)", protocol))
 }
 
+// tcActGotoChain is the goto chain TcAct opcode, chain index is encoded in the lower bits
+const tcActGotoChain netlink.TcAct = 2 << netlink.TC_ACT_EXT_SHIFT
+
 // actionGenericToTcAction converts ActionGenericType to netlink TcAct
 func actionGenericToTcAction(action types.ActionGenericType) netlink.TcAct {
 	switch action {
@@ -142,23 +175,62 @@ func tcActionToActionGeneric(action netlink.TcAct) types.ActionGenericType {
 	return types.ActionGenericType(fmt.Sprintf("Unknown(%d)", action))
 }
 
+// policeControlToTcPolAct converts PoliceControlType to netlink TcPolAct
+func policeControlToTcPolAct(control types.PoliceControlType) (netlink.TcPolAct, error) {
+	switch control {
+	case types.PoliceControlPass:
+		return netlink.TC_POLICE_OK, nil
+	case types.PoliceControlDrop:
+		return netlink.TC_POLICE_SHOT, nil
+	case types.PoliceControlPipe:
+		return netlink.TC_POLICE_PIPE, nil
+	case types.PoliceControlContinue:
+		return netlink.TC_POLICE_UNSPEC, nil
+	}
+	return netlink.TC_POLICE_UNSPEC, fmt.Errorf("unsupported police control action: %s", control)
+}
+
+// tcPolActToPoliceControl converts netlink TcPolAct to PoliceControlType
+func tcPolActToPoliceControl(action netlink.TcPolAct) types.PoliceControlType {
+	switch action {
+	case netlink.TC_POLICE_OK:
+		return types.PoliceControlPass
+	case netlink.TC_POLICE_SHOT:
+		return types.PoliceControlDrop
+	case netlink.TC_POLICE_PIPE:
+		return types.PoliceControlPipe
+	case netlink.TC_POLICE_UNSPEC:
+		return types.PoliceControlContinue
+	}
+
+	// we should not get here
+	return types.PoliceControlType(fmt.Sprintf("Unknown(%d)", action))
+}
+
 /*
 Converters
 */
 
 // qdiscToNlQdisc converts Qdisc to netlink Qdisc
 func qdiscToNlQdisc(qd types.QDisc, linkIdx int) netlink.Qdisc {
-	return &netlink.Ingress{
-		QdiscAttrs: netlink.QdiscAttrs{
-			LinkIndex: linkIdx,
-			Handle:    u32ValFromPtr(qd.Attrs().Handle, 0),
-			Parent:    u32ValFromPtr(qd.Attrs().Parent, netlink.HANDLE_INGRESS),
-		},
+	attrs := netlink.QdiscAttrs{
+		LinkIndex: linkIdx,
+		Handle:    u32ValFromPtr(qd.Attrs().Handle, 0),
+		Parent:    u32ValFromPtr(qd.Attrs().Parent, netlink.HANDLE_INGRESS),
+	}
+	if qd.Type() == types.QDiscClsactType {
+		return &netlink.GenericQdisc{QdiscAttrs: attrs, QdiscType: string(types.QDiscClsactType)}
 	}
+	return &netlink.Ingress{QdiscAttrs: attrs}
 }
 
 // nlQdiscToQdisc converts netlink Qdisc to QDisc
 func nlQdiscToQdisc(qd netlink.Qdisc) types.QDisc {
+	if qd.Type() == string(types.QDiscClsactType) {
+		return types.NewClsactQDiscBuilder().
+			WithParent(qd.Attrs().Parent).
+			WithHandle(qd.Attrs().Handle).Build()
+	}
 	return types.NewIngressQDiscBuilder().
 		WithParent(qd.Attrs().Parent).
 		WithHandle(qd.Attrs().Handle).Build()
@@ -179,8 +251,35 @@ func nlChainToChain(chain *netlink.Chain) types.Chain {
 		WithChain(chain.Chain).Build()
 }
 
-// flowerFilterToNlFlowerFilter converts FlowerFilter to netlink Flower
-func flowerFilterToNlFlowerFilter(filter *types.FlowerFilter, parent uint32, linkIdx int) *netlink.Flower {
+// checkUnsupportedFlowerKeys returns an error if flower contains keys which cannot be expressed via netlink lib
+func checkUnsupportedFlowerKeys(flower *types.FlowerSpec) error {
+	unsupported := []struct {
+		key   types.FlowerKey
+		isSet bool
+	}{
+		{types.FlowerKeyICMPType, flower.ICMPType != nil},
+		{types.FlowerKeyVlanID, flower.VlanID != nil},
+		{types.FlowerKeyCVlanID, flower.CVlanID != nil},
+		{types.FlowerKeySrcMAC, flower.SrcMAC != nil},
+		{types.FlowerKeyDstMAC, flower.DstMAC != nil},
+		{types.FlowerKeyArpSIP, flower.ArpSIP != nil},
+		{types.FlowerKeyTCPFlags, flower.TCPFlags != nil},
+		{types.FlowerKeyIPFlags, flower.IPFlags != nil},
+		{types.FlowerKeyCtState, flower.CtState != nil},
+	}
+
+	// check keys in a fixed order to report the same key for the same filter
+	for _, k := range unsupported {
+		if k.isSet {
+			return fmt.Errorf("unsupported flower key: %s", k.key)
+		}
+	}
+	return nil
+}
+
+// flowerFilterToNlFlowerFilter converts FlowerFilter to netlink Flower, an error is returned if filter
+// contains flower keys which cannot be expressed via netlink lib
+func flowerFilterToNlFlowerFilter(filter *types.FlowerFilter, parent uint32, linkIdx int) (*netlink.Flower, error) {
 	// ATM Generators dont utilize chains in filters and rely on default chain being 0
 
 	// Handle Filter attributes
@@ -193,15 +292,29 @@ func flowerFilterToNlFlowerFilter(filter *types.FlowerFilter, parent uint32, lin
 			Priority:  u16ValFromPtr(filter.Attrs().Priority, 0),
 			Protocol:  filterProtoToUnixProto(filter.Attrs().Protocol),
 		},
+		SkipHw: filter.SkipHW,
 	}
 
 	// Handle matches
 	if filter.Flower != nil {
+		if err := checkUnsupportedFlowerKeys(filter.Flower); err != nil {
+			return nil, err
+		}
+
+		if filter.Flower.SrcIP != nil {
+			nlFlowerFilter.SrcIP = filter.Flower.SrcIP.IP
+			nlFlowerFilter.SrcIPMask = filter.Flower.SrcIP.Mask
+		}
+
 		if filter.Flower.DstIP != nil {
 			nlFlowerFilter.DestIP = filter.Flower.DstIP.IP
 			nlFlowerFilter.DestIPMask = filter.Flower.DstIP.Mask
 		}
 
+		if filter.Flower.SrcPort != nil {
+			nlFlowerFilter.SrcPort = *filter.Flower.SrcPort
+		}
+
 		if filter.Flower.DstPort != nil {
 			nlFlowerFilter.DestPort = *filter.Flower.DstPort
 		}
@@ -211,25 +324,203 @@ func flowerFilterToNlFlowerFilter(filter *types.FlowerFilter, parent uint32, lin
 			nlFlowerFilter.IPProto = &ipp
 		}
 
-		if filter.Flower.VlanEthType != nil {
+		// Note(adrianc): netlink lib does not support vlan keys, we rely on the fact that flow dissector
+		// sets eth type to the inner most protocol for vlan tagged traffic.
+		switch {
+		case filter.Flower.CVlanEthType != nil:
+			nlFlowerFilter.EthType = flowerVlanEthTypeToUnixProto(*filter.Flower.CVlanEthType)
+		case filter.Flower.VlanEthType != nil:
 			nlFlowerFilter.EthType = flowerVlanEthTypeToUnixProto(*filter.Flower.VlanEthType)
-		} else {
+		case filter.Attrs().Protocol == types.FilterProtocolAll:
+			// Note(adrianc): eth_type key must not be set to match all protocols
+		default:
 			nlFlowerFilter.EthType = nlFlowerFilter.Protocol
 		}
 	}
 
 	// Handle action
-	for idx, act := range filter.Actions {
-		nlAct := netlink.GenericAction{
+	nlActions, err := actionsToNlActions(filter.Actions)
+	if err != nil {
+		return nil, err
+	}
+	nlFlowerFilter.Actions = nlActions
+
+	return nlFlowerFilter, nil
+}
+
+// u32FilterToNlU32Filter converts U32Filter to netlink U32, an error is returned if filter
+// cannot be expressed via netlink lib
+func u32FilterToNlU32Filter(filter *types.U32Filter, parent uint32, linkIdx int) (*netlink.U32, error) {
+	if filter.SkipHW {
+		// Note(adrianc): netlink lib does not support u32 filter flags
+		return nil, fmt.Errorf("unsupported u32 skip_hw flag")
+	}
+
+	nlU32Filter := &netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: linkIdx,
+			Handle:    u32ValFromPtr(filter.Attrs().Handle, 0),
+			Parent:    parent,
+			Chain:     filter.Attrs().Chain,
+			Priority:  u16ValFromPtr(filter.Attrs().Priority, 0),
+			Protocol:  filterProtoToUnixProto(filter.Attrs().Protocol),
+		},
+	}
+
+	if filter.U32 == nil {
+		return nlU32Filter, nil
+	}
+
+	if filter.U32.Divisor != 0 {
+		nlU32Filter.Divisor = filter.U32.Divisor
+		return nlU32Filter, nil
+	}
+
+	if filter.U32.HashTable != 0 {
+		nlU32Filter.Hash = types.U32Handle(filter.U32.HashTable, filter.U32.Bucket, 0)
+	}
+
+	sel := &netlink.TcU32Sel{}
+	for _, m := range filter.U32.Matches {
+		sel.Keys = append(sel.Keys, netlink.TcU32Key{Val: m.Value, Mask: m.Mask, Off: m.Offset})
+	}
+	if len(sel.Keys) == 0 {
+		// match all
+		sel.Keys = append(sel.Keys, netlink.TcU32Key{})
+	}
+
+	if filter.U32.Link != 0 {
+		nlU32Filter.Link = types.U32Handle(filter.U32.Link, 0, 0)
+		if filter.U32.HashKey != nil {
+			sel.Hmask = filter.U32.HashKey.Mask
+			sel.Hoff = int16(filter.U32.HashKey.Offset)
+		}
+	}
+
+	// Handle action
+	nlActions, err := actionsToNlActions(filter.Actions)
+	if err != nil {
+		return nil, err
+	}
+	nlU32Filter.Actions = nlActions
+	if len(nlActions) > 0 {
+		// Note(adrianc): u32 filter actions are only executed for terminal filters
+		sel.Flags |= nl.TC_U32_TERMINAL
+	}
+	nlU32Filter.Sel = sel
+
+	return nlU32Filter, nil
+}
+
+// matchAllFilterToNlMatchAllFilter converts MatchAllFilter to netlink MatchAll, an error is returned if filter
+// cannot be expressed via netlink lib
+func matchAllFilterToNlMatchAllFilter(filter *types.MatchAllFilter, parent uint32, linkIdx int) (*netlink.MatchAll,
+	error) {
+	if filter.SkipHW {
+		// Note(adrianc): netlink lib does not support matchall filter flags
+		return nil, fmt.Errorf("unsupported matchall skip_hw flag")
+	}
+
+	nlActions, err := actionsToNlActions(filter.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &netlink.MatchAll{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: linkIdx,
+			Handle:    u32ValFromPtr(filter.Attrs().Handle, 0),
+			Parent:    parent,
+			Chain:     filter.Attrs().Chain,
+			Priority:  u16ValFromPtr(filter.Attrs().Priority, 0),
+			Protocol:  filterProtoToUnixProto(filter.Attrs().Protocol),
+		},
+		Actions: nlActions,
+	}, nil
+}
+
+// basicFilterToNlGenericFilter converts BasicFilter to netlink GenericFilter, an error is returned if filter
+// has actions.
+// Note(adrianc): netlink lib does not support basic filter options, a GenericFilter can only be used to delete
+// basic filters
+func basicFilterToNlGenericFilter(filter *types.BasicFilter, parent uint32, linkIdx int) (*netlink.GenericFilter,
+	error) {
+	if len(filter.Actions) > 0 {
+		return nil, fmt.Errorf("unsupported basic filter actions")
+	}
+
+	return &netlink.GenericFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: linkIdx,
+			Handle:    u32ValFromPtr(filter.Attrs().Handle, 0),
+			Parent:    parent,
+			Chain:     filter.Attrs().Chain,
+			Priority:  u16ValFromPtr(filter.Attrs().Priority, 0),
+			Protocol:  filterProtoToUnixProto(filter.Attrs().Protocol),
+		},
+		FilterType: string(types.FilterKindBasic),
+	}, nil
+}
+
+// actionsToNlActions converts Actions to netlink Actions
+func actionsToNlActions(actions []types.Action) ([]netlink.Action, error) {
+	var nlActions []netlink.Action
+	for idx, act := range actions {
+		nlAct, err := actionToNlAction(act, idx)
+		if err != nil {
+			return nil, err
+		}
+		nlActions = append(nlActions, nlAct)
+	}
+	return nlActions, nil
+}
+
+// actionToNlAction converts Action to netlink Action with the given index
+func actionToNlAction(act types.Action, idx int) (netlink.Action, error) {
+	switch act.Type() {
+	case types.ActionTypeGeneric:
+		nlAct := &netlink.GenericAction{
 			ActionAttrs: netlink.ActionAttrs{
 				Index:  idx,
 				Action: actionGenericToTcAction(types.ActionGenericType(act.Spec()["control_action"])),
 			},
 		}
-		nlFlowerFilter.Actions = append(nlFlowerFilter.Actions, &nlAct)
+		if types.ActionGenericType(act.Spec()["control_action"]) == types.ActionGenericGoto {
+			chain, err := strconv.ParseUint(act.Spec()["chain"], 10, 32)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse goto chain")
+			}
+			nlAct.Chain = int32(chain)
+			nlAct.Action = tcActGotoChain | netlink.TcAct(chain)
+		}
+		return nlAct, nil
+	case types.ActionTypePolice:
+		// Note(adrianc): netlink police action rate is limited to 32bit
+		rate, err := strconv.ParseUint(act.Spec()["rate"], 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "unsupported police rate")
+		}
+		burst, err := strconv.ParseUint(act.Spec()["burst"], 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse police burst")
+		}
+		conform, err := policeControlToTcPolAct(types.PoliceControlType(act.Spec()["conform"]))
+		if err != nil {
+			return nil, err
+		}
+		exceed, err := policeControlToTcPolAct(types.PoliceControlType(act.Spec()["exceed"]))
+		if err != nil {
+			return nil, err
+		}
+		nlAct := netlink.NewPoliceAction()
+		nlAct.Index = idx
+		nlAct.Rate = uint32(rate)
+		nlAct.Burst = uint32(burst)
+		nlAct.NotExceedAction = conform
+		nlAct.ExceedAction = exceed
+		return nlAct, nil
 	}
-
-	return nlFlowerFilter
+	return nil, fmt.Errorf("unsupported action: %s", act.Type())
 }
 
 // nlFlowerFilterToFlowerFilter converts netlink Flower filter to FlowerFilter
@@ -243,6 +534,17 @@ func nlFlowerFilterToFlowerFilter(filter *netlink.Flower) *types.FlowerFilter {
 		fb.WithChain(*filter.Chain)
 	}
 
+	// Note(adrianc): netlink lib swaps skip_hw and skip_sw flags when parsing filters
+	if filter.SkipSw {
+		fb.WithSkipHW()
+	}
+
+	if filter.SrcIP != nil {
+		fb.WithMatchKeySrcIP(&net.IPNet{
+			IP:   filter.SrcIP,
+			Mask: filter.SrcIPMask})
+	}
+
 	if filter.DestIP != nil {
 		fb.WithMatchKeyDstIP(&net.IPNet{
 			IP:   filter.DestIP,
@@ -253,22 +555,124 @@ func nlFlowerFilterToFlowerFilter(filter *netlink.Flower) *types.FlowerFilter {
 		fb.WithMatchKeyIPProto(nlIPProtoToFlowerIPProto(*filter.IPProto))
 	}
 
+	if filter.SrcPort != 0 {
+		fb.WithMatchKeySrcPort(filter.SrcPort)
+	}
+
 	if filter.DestPort != 0 {
 		fb.WithMatchKeyDstPort(filter.DestPort)
 	}
 
-	if filter.Protocol == unix.ETH_P_8021Q {
+	switch filter.Protocol {
+	case unix.ETH_P_8021Q:
 		fb.WithMatchKeyVlanEthType(unixProtoToFlowerVlanEthType(filter.EthType))
+	case unix.ETH_P_8021AD:
+		// Note(adrianc): only double tagged traffic is expected for 802.1ad filters
+		fb.WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+			WithMatchKeyCVlanEthType(unixProtoToFlowerVlanEthType(filter.EthType))
 	}
 
-	for _, act := range filter.Actions {
+	for _, act := range nlActionsToActions(filter.Actions) {
+		fb.WithAction(act)
+	}
+
+	return fb.Build()
+}
+
+// nlActionsToActions converts netlink Actions to Actions, unsupported actions are skipped
+func nlActionsToActions(nlActions []netlink.Action) []types.Action {
+	var actions []types.Action
+	for _, act := range nlActions {
+		if policeAct, ok := act.(*netlink.PoliceAction); ok {
+			actions = append(actions, types.NewPoliceAction(uint64(policeAct.Rate), policeAct.Burst,
+				tcPolActToPoliceControl(policeAct.NotExceedAction), tcPolActToPoliceControl(policeAct.ExceedAction)))
+			continue
+		}
 		if act.Type() != "generic" {
 			// Note(adrianc): we should not get here
 			continue
 		}
 
-		fb.WithAction(types.NewGenericAction(tcActionToActionGeneric(act.Attrs().Action)))
+		if netlink.TcActExtCmp(int32(act.Attrs().Action), int32(tcActGotoChain)) {
+			actions = append(actions, types.NewGenericGotoAction(uint32(act.Attrs().Action)&netlink.TC_ACT_EXT_VAL_MASK))
+			continue
+		}
+		actions = append(actions, types.NewGenericAction(tcActionToActionGeneric(act.Attrs().Action)))
 	}
+	return actions
+}
 
-	return fb.Build()
+// nlU32FilterToU32Filter converts netlink U32 filter to U32Filter, rootHashTables holds the IDs of the root
+// hash tables of the listed priorities, filters added to a root hash table are converted with zero HashTable
+func nlU32FilterToU32Filter(filter *netlink.U32, rootHashTables map[uint32]struct{}) *types.U32Filter {
+	ub := types.NewU32FilterBuilder().
+		WithHandle(filter.Handle).
+		WithProtocol(unixProtoToFilterProto(filter.Protocol)).
+		WithPriority(filter.Priority)
+
+	if filter.Chain != nil {
+		ub.WithChain(*filter.Chain)
+	}
+
+	if filter.Divisor != 0 {
+		return ub.WithDivisor(filter.Handle>>20, filter.Divisor).Build()
+	}
+
+	if hashTable := filter.Hash >> 20; hashTable != 0 {
+		if _, isRoot := rootHashTables[hashTable]; !isRoot {
+			ub.WithHashTable(hashTable, (filter.Hash>>12)&0xff)
+		}
+	}
+
+	if filter.Sel != nil {
+		for _, key := range filter.Sel.Keys {
+			ub.WithMatch(key.Val, key.Mask, key.Off)
+		}
+		if filter.Link != 0 && filter.Sel.Hmask != 0 {
+			ub.WithHashKey(filter.Sel.Hmask, int32(filter.Sel.Hoff))
+		}
+	}
+
+	if filter.Link != 0 {
+		ub.WithLink(filter.Link >> 20)
+	}
+
+	for _, act := range nlActionsToActions(filter.Actions) {
+		ub.WithAction(act)
+	}
+
+	return ub.Build()
+}
+
+// nlMatchAllFilterToMatchAllFilter converts netlink MatchAll filter to MatchAllFilter
+func nlMatchAllFilterToMatchAllFilter(filter *netlink.MatchAll) *types.MatchAllFilter {
+	mb := types.NewMatchAllFilterBuilder().
+		WithHandle(filter.Handle).
+		WithProtocol(unixProtoToFilterProto(filter.Protocol)).
+		WithPriority(filter.Priority)
+
+	if filter.Chain != nil {
+		mb.WithChain(*filter.Chain)
+	}
+
+	for _, act := range nlActionsToActions(filter.Actions) {
+		mb.WithAction(act)
+	}
+
+	return mb.Build()
+}
+
+// nlGenericFilterToBasicFilter converts netlink GenericFilter of basic kind to BasicFilter.
+// Note(adrianc): netlink lib does not parse basic filter options, the returned filter has no actions
+func nlGenericFilterToBasicFilter(filter *netlink.GenericFilter) *types.BasicFilter {
+	bb := types.NewBasicFilterBuilder().
+		WithHandle(filter.Handle).
+		WithProtocol(unixProtoToFilterProto(filter.Protocol)).
+		WithPriority(filter.Priority)
+
+	if filter.Chain != nil {
+		bb.WithChain(*filter.Chain)
+	}
+
+	return bb.Build()
 }
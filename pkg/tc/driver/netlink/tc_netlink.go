@@ -32,7 +32,7 @@ type TcNetlinkImpl struct {
 func (t *TcNetlinkImpl) QDiscAdd(qdisc types.QDisc) error {
 	t.log.V(10).Info("QDiscAdd()")
 
-	if qdisc.Type() != types.QDiscIngressType {
+	if qdisc.Type() != types.QDiscIngressType && qdisc.Type() != types.QDiscClsactType {
 		return fmt.Errorf("unsupported qdisc type: %s", qdisc.Type())
 	}
 
@@ -43,7 +43,7 @@ func (t *TcNetlinkImpl) QDiscAdd(qdisc types.QDisc) error {
 func (t *TcNetlinkImpl) QDiscDel(qdisc types.QDisc) error {
 	t.log.V(10).Info("QDiscDel()")
 
-	if qdisc.Type() != types.QDiscIngressType {
+	if qdisc.Type() != types.QDiscIngressType && qdisc.Type() != types.QDiscClsactType {
 		return fmt.Errorf("unsupported qdisc type: %s", qdisc.Type())
 	}
 
@@ -61,8 +61,8 @@ func (t *TcNetlinkImpl) QDiscList() ([]types.QDisc, error) {
 
 	qdiscs := []types.QDisc{}
 	for _, nlQdisc := range nlQdiscs {
-		if nlQdisc.Type() != string(types.QDiscIngressType) {
-			// skip non ingress qdiscs
+		if nlQdisc.Type() != string(types.QDiscIngressType) && nlQdisc.Type() != string(types.QDiscClsactType) {
+			// skip non ingress or clsact qdiscs
 			continue
 		}
 
@@ -75,113 +75,226 @@ func (t *TcNetlinkImpl) QDiscList() ([]types.QDisc, error) {
 func (t *TcNetlinkImpl) FilterAdd(qdisc types.QDisc, filter types.Filter) error {
 	t.log.V(10).Info("FilterAdd()")
 
-	if filter.Attrs().Kind != types.FilterKindFlower {
-		return fmt.Errorf("unsupported filter kind")
-	}
-
-	if qdisc.Type() != types.QDiscIngressType {
-		return fmt.Errorf("unsupported qdisc type")
+	parent, err := hookParent(qdisc, filter.Attrs().Parent)
+	if err != nil {
+		return err
 	}
 
-	flowerFilter, ok := filter.(*types.FlowerFilter)
-	if !ok {
-		return fmt.Errorf("unexpected filter")
+	nlFilter, err := filterToNlFilter(filter, parent, t.link.Attrs().Index)
+	if err != nil {
+		return err
 	}
 
-	nlFlower := flowerFilterToNlFlowerFilter(
-		flowerFilter, netlink.HANDLE_INGRESS, t.link.Attrs().Index)
-
-	return t.netlinkIfc.FilterAdd(nlFlower)
+	return t.netlinkIfc.FilterAdd(nlFilter)
 }
 
 // FilterDel implements TC interface
 func (t *TcNetlinkImpl) FilterDel(qdisc types.QDisc, filterAttr *types.FilterAttrs) error {
 	t.log.V(10).Info("FilterDel()")
 
-	if filterAttr.Kind != types.FilterKindFlower {
-		return fmt.Errorf("unsupported filter kind")
+	parent, err := hookParent(qdisc, filterAttr.Parent)
+	if err != nil {
+		return err
 	}
 
-	if qdisc.Type() != types.QDiscIngressType {
-		return fmt.Errorf("unsupported qdisc type")
+	var filter types.Filter
+	switch filterAttr.Kind {
+	case types.FilterKindFlower:
+		filter = &types.FlowerFilter{FilterAttrs: *filterAttr}
+	case types.FilterKindU32:
+		filter = &types.U32Filter{FilterAttrs: *filterAttr}
+	case types.FilterKindMatchAll:
+		filter = &types.MatchAllFilter{FilterAttrs: *filterAttr}
+	case types.FilterKindBasic:
+		filter = &types.BasicFilter{FilterAttrs: *filterAttr}
+	default:
+		return fmt.Errorf("unsupported filter kind")
 	}
 
-	flowerFilter := &types.FlowerFilter{FilterAttrs: *filterAttr}
-
-	nlFlower := flowerFilterToNlFlowerFilter(flowerFilter, netlink.HANDLE_INGRESS, t.link.Attrs().Index)
+	nlFilter, err := filterToNlFilter(filter, parent, t.link.Attrs().Index)
+	if err != nil {
+		return err
+	}
 
-	return t.netlinkIfc.FilterDel(nlFlower)
+	return t.netlinkIfc.FilterDel(nlFilter)
 }
 
-// FilterList implements TC interface
+// FilterList implements TC interface. for clsact qdisc, filters of both hooks are listed with their Parent set
 func (t *TcNetlinkImpl) FilterList(qdisc types.QDisc) ([]types.Filter, error) {
 	t.log.V(10).Info("FilterList()")
 
-	if qdisc.Type() != types.QDiscIngressType {
-		return nil, fmt.Errorf("unsupported qdisc type")
+	parents, err := hookParents(qdisc)
+	if err != nil {
+		return nil, err
 	}
 
-	nlFilters, err := t.netlinkIfc.FilterList(t.link, netlink.HANDLE_INGRESS)
+	var filters []types.Filter
+	for _, parent := range parents {
+		hookFilters, err := t.filterList(parent)
+		if err != nil {
+			return nil, err
+		}
+		if qdisc.Type() == types.QDiscClsactType {
+			for _, f := range hookFilters {
+				p := parent
+				f.Attrs().Parent = &p
+			}
+		}
+		filters = append(filters, hookFilters...)
+	}
+	return filters, nil
+}
+
+// filterList lists filters of the hook identified by netlink parent
+func (t *TcNetlinkImpl) filterList(parent uint32) ([]types.Filter, error) {
+	nlFilters, err := t.netlinkIfc.FilterList(t.link, parent)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list filters")
 	}
 
-	var filters []types.Filter
+	// Note(adrianc): the kernel creates a root hash table per u32 filter priority, those are not
+	// part of the generated filters and are skipped.
+	rootHashTables := make(map[uint32]struct{})
 	for _, nlFilter := range nlFilters {
-		if nlFilter.Type() != "flower" {
-			continue
+		if nlU32Filter, ok := nlFilter.(*netlink.U32); ok && isU32RootHashTable(nlU32Filter) {
+			rootHashTables[nlU32Filter.Handle>>20] = struct{}{}
 		}
+	}
 
-		nlFlowerFilter, ok := nlFilter.(*netlink.Flower)
-		if !ok {
-			continue
+	var filters []types.Filter
+	for _, nlFilter := range nlFilters {
+		switch nlFilter := nlFilter.(type) {
+		case *netlink.Flower:
+			filters = append(filters, nlFlowerFilterToFlowerFilter(nlFilter))
+		case *netlink.U32:
+			if isU32RootHashTable(nlFilter) {
+				continue
+			}
+			filters = append(filters, nlU32FilterToU32Filter(nlFilter, rootHashTables))
+		case *netlink.MatchAll:
+			filters = append(filters, nlMatchAllFilterToMatchAllFilter(nlFilter))
+		case *netlink.GenericFilter:
+			if nlFilter.FilterType == string(types.FilterKindBasic) {
+				filters = append(filters, nlGenericFilterToBasicFilter(nlFilter))
+			}
 		}
-
-		filters = append(filters, nlFlowerFilterToFlowerFilter(nlFlowerFilter))
 	}
 	return filters, nil
 }
 
+// filterToNlFilter converts Filter to netlink Filter
+func filterToNlFilter(filter types.Filter, parent uint32, linkIdx int) (netlink.Filter, error) {
+	var nlFilter netlink.Filter
+	var err error
+
+	switch f := filter.(type) {
+	case *types.FlowerFilter:
+		nlFilter, err = flowerFilterToNlFlowerFilter(f, parent, linkIdx)
+	case *types.U32Filter:
+		nlFilter, err = u32FilterToNlU32Filter(f, parent, linkIdx)
+	case *types.MatchAllFilter:
+		nlFilter, err = matchAllFilterToNlMatchAllFilter(f, parent, linkIdx)
+	case *types.BasicFilter:
+		nlFilter, err = basicFilterToNlGenericFilter(f, parent, linkIdx)
+	default:
+		return nil, fmt.Errorf("unsupported filter kind")
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert filter")
+	}
+	return nlFilter, nil
+}
+
+// isU32RootHashTable returns true if filter is the root hash table of a u32 filter priority
+func isU32RootHashTable(filter *netlink.U32) bool {
+	return filter.Divisor == 1
+}
+
 // ChainAdd implements TC interface
 func (t *TcNetlinkImpl) ChainAdd(qdisc types.QDisc, chain types.Chain) error {
 	t.log.V(10).Info("ChainAdd()")
 
-	if qdisc.Type() != types.QDiscIngressType {
-		return fmt.Errorf("unsupported qdisc type")
+	parent, err := hookParent(qdisc, chain.Attrs().Parent)
+	if err != nil {
+		return err
+	}
+
+	if chain.Attrs().Template != nil {
+		// Note(adrianc): netlink lib does not support chain templates
+		return fmt.Errorf("unsupported chain template")
 	}
 
-	return t.netlinkIfc.ChainAdd(t.link, chainToNlChain(chain, netlink.HANDLE_INGRESS))
+	return t.netlinkIfc.ChainAdd(t.link, chainToNlChain(chain, parent))
 }
 
 // ChainDel implements TC interface
 func (t *TcNetlinkImpl) ChainDel(qdisc types.QDisc, chain types.Chain) error {
 	t.log.V(10).Info("ChainDel()")
 
-	if qdisc.Type() != types.QDiscIngressType {
-		return fmt.Errorf("unsupported qdisc type")
+	parent, err := hookParent(qdisc, chain.Attrs().Parent)
+	if err != nil {
+		return err
 	}
 
-	return t.netlinkIfc.ChainDel(t.link, chainToNlChain(chain, netlink.HANDLE_INGRESS))
+	return t.netlinkIfc.ChainDel(t.link, chainToNlChain(chain, parent))
 }
 
-// ChainList implements TC interface
+// ChainList implements TC interface. for clsact qdisc, chains of both hooks are listed with their Parent set
+// to the hook
 func (t *TcNetlinkImpl) ChainList(qdisc types.QDisc) ([]types.Chain, error) {
 	t.log.V(10).Info("ChainList()")
 
-	if qdisc.Type() != types.QDiscIngressType {
-		return nil, fmt.Errorf("unsupported qdisc type")
-	}
-
-	nlChains, err := t.netlinkIfc.ChainList(t.link, netlink.HANDLE_INGRESS)
-
+	parents, err := hookParents(qdisc)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list chains")
+		return nil, err
 	}
 
 	var chains []types.Chain
-	for idx := range nlChains {
-		chains = append(chains, nlChainToChain(&nlChains[idx]))
+	for _, parent := range parents {
+		nlChains, err := t.netlinkIfc.ChainList(t.link, parent)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list chains")
+		}
+
+		for idx := range nlChains {
+			chain := nlChainToChain(&nlChains[idx])
+			if qdisc.Type() == types.QDiscClsactType {
+				p := parent
+				chain.Attrs().Parent = &p
+			}
+			chains = append(chains, chain)
+		}
 	}
 
 	return chains, nil
 }
+
+// hookParent returns the netlink parent of the qdisc hook that objects with the given parent are attached to.
+// egress hook is only supported by clsact qdisc.
+func hookParent(qdisc types.QDisc, parent *uint32) (uint32, error) {
+	switch qdisc.Type() {
+	case types.QDiscIngressType:
+		if types.HookOf(parent) == types.ParentEgress {
+			return 0, fmt.Errorf("egress hook is not supported by %s qdisc", qdisc.Type())
+		}
+		return netlink.HANDLE_INGRESS, nil
+	case types.QDiscClsactType:
+		if types.HookOf(parent) == types.ParentEgress {
+			return netlink.HANDLE_MIN_EGRESS, nil
+		}
+		return netlink.HANDLE_MIN_INGRESS, nil
+	}
+	return 0, fmt.Errorf("unsupported qdisc type: %s", qdisc.Type())
+}
+
+// hookParents returns the netlink parents of all qdisc hooks
+func hookParents(qdisc types.QDisc) ([]uint32, error) {
+	switch qdisc.Type() {
+	case types.QDiscIngressType:
+		return []uint32{netlink.HANDLE_INGRESS}, nil
+	case types.QDiscClsactType:
+		return []uint32{netlink.HANDLE_MIN_INGRESS, netlink.HANDLE_MIN_EGRESS}, nil
+	}
+	return nil, fmt.Errorf("unsupported qdisc type: %s", qdisc.Type())
+}
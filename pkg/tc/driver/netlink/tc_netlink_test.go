@@ -147,6 +147,14 @@ var _ = Describe("TC Netlink driver tests", func() {
 			err := tcNetlink.ChainAdd(ingressQdisc, chain)
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("Fails for chain with template", func() {
+			templateChain := tctypes.NewChainBuilder().WithTemplate(tctypes.FilterProtocolIPv4,
+				tctypes.NewFlowerFilterBuilder().WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).Build().Flower).Build()
+			err := tcNetlink.ChainAdd(ingressQdisc, templateChain)
+			Expect(err).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "ChainAdd", mock.Anything, mock.Anything)
+		})
 	})
 
 	Context("Chain Del", func() {
@@ -199,6 +207,303 @@ var _ = Describe("TC Netlink driver tests", func() {
 			err := tcNetlink.FilterAdd(ingressQdisc, filter)
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("does not match on eth type for filter with protocol all", func() {
+			allFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(304).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				flower, ok := f.(*netlink.Flower)
+				if !ok {
+					return false
+				}
+				return flower.Protocol == unix.ETH_P_ALL && flower.EthType == 0
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, allFilter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("matches on inner most eth type for 802.1ad filter", func() {
+			qinqFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021AD).
+				WithPriority(205).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthType8021Q).
+				WithMatchKeyCVlanEthType(tctypes.FlowerVlanEthTypeIPv6).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				flower, ok := f.(*netlink.Flower)
+				if !ok {
+					return false
+				}
+				return flower.Protocol == unix.ETH_P_8021AD && flower.EthType == unix.ETH_P_IPV6
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, qinqFilter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Fails when filter has cvlan_id flower key", func() {
+			qinqFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021AD).
+				WithPriority(205).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthType8021Q).
+				WithMatchKeyCVlanID(10).
+				WithMatchKeyCVlanEthType(tctypes.FlowerVlanEthTypeIPv6).
+				Build()
+			err := tcNetlink.FilterAdd(ingressQdisc, qinqFilter)
+			Expect(err).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("Fails when filter has flower keys not supported by netlink", func() {
+			icmpFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv6).
+				WithPriority(51).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoICMPv6).
+				WithMatchKeyICMPType(135).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			err := tcNetlink.FilterAdd(ingressQdisc, icmpFilter)
+			Expect(err).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("Fails with the first unsupported flower key of filter", func() {
+			mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+			filter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(250).
+				WithMatchKeyDstMAC(mac, nil).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+				WithMatchKeyTCPFlags(tctypes.TCPFlagACK, tctypes.TCPFlagACK).
+				WithMatchKeyCtState(tctypes.FlowerCtStateEstablished).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			for i := 0; i < 10; i++ {
+				err := tcNetlink.FilterAdd(ingressQdisc, filter)
+				Expect(err).To(MatchError(ContainSubstring("unsupported flower key: dst_mac")))
+			}
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("Fails when filter has src_mac flower key", func() {
+			mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+			macFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(34).
+				WithMatchKeySrcMAC(mac).
+				WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(1).Build()).
+				Build()
+			err := tcNetlink.FilterAdd(ingressQdisc, macFilter)
+			Expect(err).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("Fails when filter has connection tracking key, tcp flags key or ct action", func() {
+			ctStateFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(20).
+				WithMatchKeyCtState(tctypes.FlowerCtStateEstablished).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			ctActionFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(10).
+				WithAction(tctypes.NewConnTrackActionBuilder().Build()).
+				WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(2).Build()).
+				Build()
+			tcpFlagsFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(250).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+				WithMatchKeyTCPFlags(tctypes.TCPFlagACK, tctypes.TCPFlagACK).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			ipFlagsFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(150).
+				WithMatchKeyIPFlags(tctypes.FlowerIPFlagsFrag).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build()
+			Expect(tcNetlink.FilterAdd(ingressQdisc, tcpFlagsFilter)).To(HaveOccurred())
+			Expect(tcNetlink.FilterAdd(ingressQdisc, ipFlagsFilter)).To(HaveOccurred())
+			dstMACFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(54).
+				WithMatchKeyDstMAC(net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, nil).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			Expect(tcNetlink.FilterAdd(ingressQdisc, dstMACFilter)).To(HaveOccurred())
+			vlanIDFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021Q).
+				WithPriority(202).
+				WithMatchKeyVlanID(100).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			Expect(tcNetlink.FilterAdd(ingressQdisc, vlanIDFilter)).To(HaveOccurred())
+			Expect(tcNetlink.FilterAdd(ingressQdisc, ctStateFilter)).To(HaveOccurred())
+			Expect(tcNetlink.FilterAdd(ingressQdisc, ctActionFilter)).To(HaveOccurred())
+			dscpFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(200).
+				WithAction(tctypes.NewPeditDSCPAction(tctypes.PeditHeaderIPv4, 46)).
+				WithAction(tctypes.NewCsumAction(tctypes.CsumUpdateIPv4Header)).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			Expect(tcNetlink.FilterAdd(ingressQdisc, dscpFilter)).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("sets source IP and goto chain action", func() {
+			srcIPFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(10).
+				WithMatchKeySrcIP(&net.IPNet{IP: net.ParseIP("10.10.10.2").To4(), Mask: net.CIDRMask(32, 32)}).
+				WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(1).Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				flower, ok := f.(*netlink.Flower)
+				if !ok || len(flower.Actions) != 1 {
+					return false
+				}
+				gact, ok := flower.Actions[0].(*netlink.GenericAction)
+				if !ok {
+					return false
+				}
+				return flower.SrcIP.Equal(net.ParseIP("10.10.10.2")) &&
+					netlink.TcActExtCmp(int32(gact.Attrs().Action), int32(2<<netlink.TC_ACT_EXT_SHIFT)) &&
+					gact.Chain == 1
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, srcIPFilter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets police action", func() {
+			policeFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(4).
+				WithAction(tctypes.NewPoliceAction(1250000, 65536, tctypes.PoliceControlPipe, tctypes.PoliceControlDrop)).
+				WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(3).Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				flower, ok := f.(*netlink.Flower)
+				if !ok || len(flower.Actions) != 2 {
+					return false
+				}
+				police, ok := flower.Actions[0].(*netlink.PoliceAction)
+				if !ok {
+					return false
+				}
+				return police.Rate == 1250000 && police.Burst == 65536 &&
+					police.NotExceedAction == netlink.TC_POLICE_PIPE && police.ExceedAction == netlink.TC_POLICE_SHOT
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, policeFilter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Fails when police action rate is not supported by netlink", func() {
+			policeFilter := tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(4).
+				WithAction(tctypes.NewPoliceAction(1<<33, 65536, tctypes.PoliceControlPass, tctypes.PoliceControlDrop)).
+				Build()
+			err := tcNetlink.FilterAdd(ingressQdisc, policeFilter)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("sets u32 hash table entry with terminal flag and actions", func() {
+			u32Filter := tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithHashTable(0x100, 0xa).
+				WithMatchDstIP(ipToIpNet("10.0.0.10")).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				u32, ok := f.(*netlink.U32)
+				if !ok || u32.Sel == nil || len(u32.Sel.Keys) != 1 || len(u32.Actions) != 1 {
+					return false
+				}
+				return u32.Hash == 0x1000a000 && u32.Sel.Flags&nl.TC_U32_TERMINAL != 0 &&
+					reflect.DeepEqual(u32.Sel.Keys[0], netlink.TcU32Key{Val: 0x0a00000a, Mask: 0xffffffff, Off: 16})
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, u32Filter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets u32 hash table and link filters", func() {
+			hashTableFilter := tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithDivisor(0x100, 256).
+				Build()
+			linkFilter := tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithMatch(0, 0, 0).
+				WithHashKey(0xff, 16).
+				WithLink(0x100).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				u32, ok := f.(*netlink.U32)
+				return ok && u32.Handle == 0x10000000 && u32.Divisor == 256 && u32.Sel == nil
+			})).Return(nil)
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				u32, ok := f.(*netlink.U32)
+				return ok && u32.Link == 0x10000000 && u32.Sel != nil && u32.Sel.Hmask == 0xff &&
+					u32.Sel.Hoff == 16 && u32.Sel.Flags&nl.TC_U32_TERMINAL == 0
+			})).Return(nil)
+			Expect(tcNetlink.FilterAdd(ingressQdisc, hashTableFilter)).To(Succeed())
+			Expect(tcNetlink.FilterAdd(ingressQdisc, linkFilter)).To(Succeed())
+			netlinkProviderMock.AssertNumberOfCalls(GinkgoT(), "FilterAdd", 2)
+		})
+
+		It("sets matchall filter actions", func() {
+			matchAllFilter := tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build()
+			netlinkProviderMock.On("FilterAdd", mock.MatchedBy(func(f netlink.Filter) bool {
+				matchAll, ok := f.(*netlink.MatchAll)
+				if !ok || len(matchAll.Actions) != 1 {
+					return false
+				}
+				return matchAll.Priority == 300 && matchAll.Protocol == unix.ETH_P_ALL &&
+					matchAll.Actions[0].Attrs().Action == netlink.TC_ACT_SHOT
+			})).Return(nil)
+			err := tcNetlink.FilterAdd(ingressQdisc, matchAllFilter)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Fails when matchall filter has skip_hw flag or basic filter has actions", func() {
+			matchAllFilter := tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithSkipHW().
+				Build()
+			basicFilter := tctypes.NewBasicFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build()
+			Expect(tcNetlink.FilterAdd(ingressQdisc, matchAllFilter)).ToNot(Succeed())
+			Expect(tcNetlink.FilterAdd(ingressQdisc, basicFilter)).ToNot(Succeed())
+		})
+
+		It("Fails when u32 filter has skip_hw flag", func() {
+			u32Filter := tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithMatchDstIP(ipToIpNet("10.0.0.10")).
+				WithSkipHW().
+				Build()
+			err := tcNetlink.FilterAdd(ingressQdisc, u32Filter)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Context("Filter Del", func() {
@@ -214,6 +519,34 @@ var _ = Describe("TC Netlink driver tests", func() {
 			err := tcNetlink.FilterDel(ingressQdisc, filter.Attrs())
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("deletes basic filter by handle", func() {
+			basicFilter := tctypes.NewBasicFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(301).
+				WithHandle(1).
+				Build()
+			netlinkProviderMock.On("FilterDel", mock.MatchedBy(func(f netlink.Filter) bool {
+				generic, ok := f.(*netlink.GenericFilter)
+				return ok && generic.Type() == "basic" && generic.Handle == 1 && generic.Priority == 301
+			})).Return(nil)
+			err := tcNetlink.FilterDel(ingressQdisc, basicFilter.Attrs())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes u32 filter by handle", func() {
+			u32Filter := tctypes.NewU32FilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(206).
+				WithHandle(tctypes.U32Handle(0x100, 0xa, 0x800)).
+				Build()
+			netlinkProviderMock.On("FilterDel", mock.MatchedBy(func(f netlink.Filter) bool {
+				u32, ok := f.(*netlink.U32)
+				return ok && u32.Handle == 0x1000a800 && u32.Priority == 206 && u32.Protocol == unix.ETH_P_IP
+			})).Return(nil)
+			err := tcNetlink.FilterDel(ingressQdisc, u32Filter.Attrs())
+			Expect(err).ToNot(HaveOccurred())
+		})
 	})
 
 	Context("Filter List", func() {
@@ -232,5 +565,268 @@ var _ = Describe("TC Netlink driver tests", func() {
 			Expect(fl).To(HaveLen(1))
 			Expect(fl[0].Equals(filter)).To(BeTrue())
 		})
+
+		It("returns double tagged filter for 802.1ad netlink filter", func() {
+			nlQinQFilter := &netlink.Flower{
+				FilterAttrs: netlink.FilterAttrs{
+					LinkIndex: fLink.Attrs().Index,
+					Handle:    1,
+					Parent:    netlink.HANDLE_INGRESS,
+					Priority:  205,
+					Protocol:  unix.ETH_P_8021AD,
+				},
+				EthType: unix.ETH_P_IP,
+			}
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_INGRESS)).
+				Return([]netlink.Filter{nlQinQFilter}, nil)
+			fl, err := tcNetlink.FilterList(ingressQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(1))
+			Expect(fl[0].Equals(tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocol8021AD).
+				WithPriority(205).
+				WithHandle(1).
+				WithMatchKeyVlanEthType(tctypes.FlowerVlanEthType8021Q).
+				WithMatchKeyCVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
+				Build())).To(BeTrue())
+		})
+
+		It("returns filter with source IP and goto chain action", func() {
+			gact := &netlink.GenericAction{
+				ActionAttrs: netlink.ActionAttrs{Action: netlink.TcAct(2<<netlink.TC_ACT_EXT_SHIFT | 1)},
+				Chain:       1,
+			}
+			nlSrcIPFilter := &netlink.Flower{
+				FilterAttrs: netlink.FilterAttrs{
+					LinkIndex: fLink.Attrs().Index,
+					Handle:    1,
+					Parent:    netlink.HANDLE_INGRESS,
+					Priority:  10,
+					Protocol:  unix.ETH_P_IP,
+				},
+				EthType:   unix.ETH_P_IP,
+				SrcIP:     net.ParseIP("10.10.10.2").To4(),
+				SrcIPMask: net.CIDRMask(32, 32),
+				Actions:   []netlink.Action{gact},
+			}
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_INGRESS)).
+				Return([]netlink.Filter{nlSrcIPFilter}, nil)
+			fl, err := tcNetlink.FilterList(ingressQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(1))
+			Expect(fl[0].Equals(tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolIPv4).
+				WithPriority(10).
+				WithHandle(1).
+				WithMatchKeySrcIP(&net.IPNet{IP: net.ParseIP("10.10.10.2"), Mask: net.CIDRMask(32, 32)}).
+				WithAction(tctypes.NewGenericGotoAction(1)).
+				Build())).To(BeTrue())
+		})
+
+		It("returns filter with police action", func() {
+			police := netlink.NewPoliceAction()
+			police.Rate = 1250000
+			police.Burst = 65536
+			police.NotExceedAction = netlink.TC_POLICE_PIPE
+			police.ExceedAction = netlink.TC_POLICE_SHOT
+			nlPoliceFilter := &netlink.Flower{
+				FilterAttrs: netlink.FilterAttrs{
+					LinkIndex: fLink.Attrs().Index,
+					Handle:    1,
+					Parent:    netlink.HANDLE_INGRESS,
+					Priority:  4,
+					Protocol:  unix.ETH_P_ALL,
+				},
+				Actions: []netlink.Action{police},
+			}
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_INGRESS)).
+				Return([]netlink.Filter{nlPoliceFilter}, nil)
+			fl, err := tcNetlink.FilterList(ingressQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(1))
+			Expect(fl[0].Equals(tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(4).
+				WithHandle(1).
+				WithAction(tctypes.NewPoliceAction(1250000, 65536, tctypes.PoliceControlPipe, tctypes.PoliceControlDrop)).
+				Build())).To(BeTrue())
+		})
+
+		It("returns u32 filters without root hash tables", func() {
+			u32Attrs := func(handle uint32) netlink.FilterAttrs {
+				return netlink.FilterAttrs{
+					LinkIndex: fLink.Attrs().Index,
+					Handle:    handle,
+					Parent:    netlink.HANDLE_INGRESS,
+					Priority:  206,
+					Protocol:  unix.ETH_P_IP,
+				}
+			}
+			nlU32Filters := []netlink.Filter{
+				&netlink.U32{FilterAttrs: u32Attrs(0x10000000), Divisor: 256},
+				&netlink.U32{
+					FilterAttrs: u32Attrs(0x1000a800),
+					Hash:        0x1000a000,
+					Sel: &netlink.TcU32Sel{
+						Flags: nl.TC_U32_TERMINAL,
+						Keys:  []netlink.TcU32Key{{Val: 0x0a00000a, Mask: 0xffffffff, Off: 16}},
+					},
+					Actions: []netlink.Action{&netlink.GenericAction{
+						ActionAttrs: netlink.ActionAttrs{Action: netlink.TC_ACT_OK},
+					}},
+				},
+				&netlink.U32{FilterAttrs: u32Attrs(0x80000000), Divisor: 1},
+				&netlink.U32{
+					FilterAttrs: u32Attrs(0x80000800),
+					Hash:        0x80000000,
+					Link:        0x10000000,
+					Sel: &netlink.TcU32Sel{
+						Hmask: 0xff,
+						Hoff:  16,
+						Keys:  []netlink.TcU32Key{{}},
+					},
+				},
+			}
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_INGRESS)).
+				Return(nlU32Filters, nil)
+			fl, err := tcNetlink.FilterList(ingressQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(3))
+			u32Filter := func() *tctypes.U32FilterBuilder {
+				return tctypes.NewU32FilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(206)
+			}
+			Expect(fl[0].Equals(u32Filter().WithDivisor(0x100, 256).Build())).To(BeTrue())
+			Expect(fl[1].Equals(u32Filter().
+				WithHandle(0x1000a800).
+				WithHashTable(0x100, 0xa).
+				WithMatchDstIP(ipToIpNet("10.0.0.10")).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build())).To(BeTrue())
+			Expect(fl[2].Equals(u32Filter().
+				WithHandle(0x80000800).
+				WithMatch(0, 0, 0).
+				WithHashKey(0xff, 16).
+				WithLink(0x100).
+				Build())).To(BeTrue())
+		})
+
+		It("returns matchall and basic filters", func() {
+			attrs := func(prio uint16) netlink.FilterAttrs {
+				return netlink.FilterAttrs{
+					LinkIndex: fLink.Attrs().Index,
+					Handle:    1,
+					Parent:    netlink.HANDLE_INGRESS,
+					Priority:  prio,
+					Protocol:  unix.ETH_P_ALL,
+				}
+			}
+			nlFilters := []netlink.Filter{
+				&netlink.MatchAll{
+					FilterAttrs: attrs(300),
+					Actions: []netlink.Action{&netlink.GenericAction{
+						ActionAttrs: netlink.ActionAttrs{Action: netlink.TC_ACT_SHOT},
+					}},
+				},
+				&netlink.GenericFilter{FilterAttrs: attrs(301), FilterType: "basic"},
+				&netlink.GenericFilter{FilterAttrs: attrs(302), FilterType: "fw"},
+			}
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_INGRESS)).
+				Return(nlFilters, nil)
+			fl, err := tcNetlink.FilterList(ingressQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(2))
+			Expect(fl[0].Equals(tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build())).To(BeTrue())
+			Expect(fl[1].Equals(tctypes.NewBasicFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(301).
+				Build())).To(BeTrue())
+		})
+	})
+
+	Context("clsact qdisc", func() {
+		clsactQdisc := tctypes.NewClsactQDiscBuilder().Build()
+		nlClsactQdisc := &netlink.GenericQdisc{
+			QdiscAttrs: netlink.QdiscAttrs{
+				LinkIndex: fLink.Attrs().Index,
+				Parent:    netlink.HANDLE_CLSACT,
+			},
+			QdiscType: "clsact",
+		}
+		egressFilter := tctypes.NewMatchAllFilterBuilder().
+			WithProtocol(tctypes.FilterProtocolAll).
+			WithPriority(300).
+			WithHandle(1).
+			WithParent(tctypes.ParentEgress).
+			Build()
+		nlEgressFilter := &netlink.MatchAll{
+			FilterAttrs: netlink.FilterAttrs{
+				LinkIndex: fLink.Attrs().Index,
+				Handle:    1,
+				Parent:    netlink.HANDLE_MIN_EGRESS,
+				Priority:  300,
+				Protocol:  unix.ETH_P_ALL,
+			},
+		}
+
+		It("adds, deletes and lists clsact qdisc", func() {
+			netlinkProviderMock.On("QdiscAdd", nlClsactQdisc).Return(nil)
+			netlinkProviderMock.On("QdiscDel", nlClsactQdisc).Return(nil)
+			netlinkProviderMock.On("QdiscList", fLink).Return([]netlink.Qdisc{nlClsactQdisc}, nil)
+
+			Expect(tcNetlink.QDiscAdd(clsactQdisc)).To(Succeed())
+			Expect(tcNetlink.QDiscDel(clsactQdisc)).To(Succeed())
+			qds, err := tcNetlink.QDiscList()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qds).To(HaveLen(1))
+			Expect(qds[0].Type()).To(Equal(tctypes.QDiscClsactType))
+		})
+
+		It("adds and deletes filters on the filter hook", func() {
+			netlinkProviderMock.On("FilterAdd", nlEgressFilter).Return(nil)
+			netlinkProviderMock.On("FilterDel", nlEgressFilter).Return(nil)
+
+			Expect(tcNetlink.FilterAdd(clsactQdisc, egressFilter)).To(Succeed())
+			Expect(tcNetlink.FilterDel(clsactQdisc, egressFilter.Attrs())).To(Succeed())
+		})
+
+		It("fails to add egress filter on ingress qdisc", func() {
+			err := tcNetlink.FilterAdd(ingressQdisc, egressFilter)
+			Expect(err).To(HaveOccurred())
+			netlinkProviderMock.AssertNotCalled(GinkgoT(), "FilterAdd", mock.Anything)
+		})
+
+		It("lists filters of both hooks", func() {
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_MIN_INGRESS)).
+				Return([]netlink.Filter{nlFilter}, nil)
+			netlinkProviderMock.On("FilterList", fLink, uint32(netlink.HANDLE_MIN_EGRESS)).
+				Return([]netlink.Filter{nlEgressFilter}, nil)
+
+			fl, err := tcNetlink.FilterList(clsactQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fl).To(HaveLen(2))
+			Expect(fl[0].Equals(filter)).To(BeTrue())
+			Expect(*fl[0].Attrs().Parent).To(Equal(tctypes.ParentIngress))
+			Expect(fl[1].Equals(egressFilter)).To(BeTrue())
+		})
+
+		It("adds and lists chains of both hooks", func() {
+			egressChain := tctypes.NewChainBuilder().WithParent(tctypes.ParentEgress).WithChain(44).Build()
+			nlEgressChain := netlink.Chain{Chain: 44, Parent: netlink.HANDLE_MIN_EGRESS}
+			netlinkProviderMock.On("ChainAdd", fLink, nlEgressChain).Return(nil)
+			netlinkProviderMock.On("ChainList", fLink, uint32(netlink.HANDLE_MIN_INGRESS)).
+				Return([]netlink.Chain{}, nil)
+			netlinkProviderMock.On("ChainList", fLink, uint32(netlink.HANDLE_MIN_EGRESS)).
+				Return([]netlink.Chain{nlEgressChain}, nil)
+
+			Expect(tcNetlink.ChainAdd(clsactQdisc, egressChain)).To(Succeed())
+			chains, err := tcNetlink.ChainList(clsactQdisc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chains).To(HaveLen(1))
+			Expect(chains[0]).To(BeEquivalentTo(egressChain))
+		})
 	})
 })
@@ -121,3 +121,119 @@ func (f *FilterSetImpl) Equals(other FilterSet) bool {
 func (f *FilterSetImpl) List() []types.Filter {
 	return f.items
 }
+
+// NewFilterHashSetImpl returns a new *FilterHashSetImpl
+func NewFilterHashSetImpl() *FilterHashSetImpl {
+	return &FilterHashSetImpl{
+		items: make([]types.Filter, 0),
+		keys:  make([]string, 0),
+		index: make(map[string]int),
+	}
+}
+
+// FilterHashSetImpl implements FilterSet, filters are indexed by their key (see types.Filter.Key) hence
+// set operations are linear in the number of filters.
+// Note(adrianc): filters are kept in insertion order (until removed) as in FilterSetImpl.
+type FilterHashSetImpl struct {
+	items []types.Filter
+	// keys are the keys of items
+	keys []string
+	// index maps filter key to filter index in items
+	index map[string]int
+}
+
+// Add implements FilterSet
+func (f *FilterHashSetImpl) Add(filter types.Filter) {
+	f.add(filter, filter.Key())
+}
+
+// Remove implements FilterSet
+func (f *FilterHashSetImpl) Remove(filter types.Filter) {
+	key := filter.Key()
+	foundIdx, ok := f.index[key]
+	if !ok {
+		return
+	}
+
+	lastIdx := len(f.items) - 1
+	f.items[foundIdx], f.keys[foundIdx] = f.items[lastIdx], f.keys[lastIdx]
+	f.index[f.keys[foundIdx]] = foundIdx
+	f.items, f.keys = f.items[:lastIdx], f.keys[:lastIdx]
+	delete(f.index, key)
+}
+
+// Has implements FilterSet
+func (f *FilterHashSetImpl) Has(filter types.Filter) bool {
+	_, ok := f.index[filter.Key()]
+	return ok
+}
+
+// Len implements FilterSet
+func (f *FilterHashSetImpl) Len() int {
+	return len(f.items)
+}
+
+// In implements FilterSet
+func (f *FilterHashSetImpl) In(other FilterSet) bool {
+	if f.Len() > other.Len() {
+		return false
+	}
+
+	for idx := range f.items {
+		if !f.inOther(idx, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect implements FilterSet
+func (f *FilterHashSetImpl) Intersect(other FilterSet) FilterSet {
+	fs := NewFilterHashSetImpl()
+	for idx := range f.items {
+		if f.inOther(idx, other) {
+			fs.add(f.items[idx], f.keys[idx])
+		}
+	}
+	return fs
+}
+
+// Difference implements FilterSet
+func (f *FilterHashSetImpl) Difference(other FilterSet) FilterSet {
+	fs := NewFilterHashSetImpl()
+	for idx := range f.items {
+		if !f.inOther(idx, other) {
+			fs.add(f.items[idx], f.keys[idx])
+		}
+	}
+	return fs
+}
+
+// Equals implements FilterSet
+func (f *FilterHashSetImpl) Equals(other FilterSet) bool {
+	return f.Len() == other.Len() && f.In(other)
+}
+
+// List implements FilterSet
+func (f *FilterHashSetImpl) List() []types.Filter {
+	return f.items
+}
+
+// add adds filter with the given key to set
+func (f *FilterHashSetImpl) add(filter types.Filter, key string) {
+	if _, ok := f.index[key]; !ok {
+		f.index[key] = len(f.items)
+		f.items = append(f.items, filter)
+		f.keys = append(f.keys, key)
+	}
+}
+
+// inOther returns true if filter at idx is an element of other set. filter key is reused if other is
+// a FilterHashSetImpl
+func (f *FilterHashSetImpl) inOther(idx int, other FilterSet) bool {
+	if otherHashSet, ok := other.(*FilterHashSetImpl); ok {
+		_, found := otherHashSet.index[f.keys[idx]]
+		return found
+	}
+	return other.Has(f.items[idx])
+}
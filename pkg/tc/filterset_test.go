@@ -1,21 +1,65 @@
 package tc_test
 
 import (
+	"fmt"
+	"net"
+	"testing"
+
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"net"
 
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc"
 	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 )
 
 var _ = Describe("FilterSetImpl tests", func() {
+	filterSetTests(func() tc.FilterSet { return tc.NewFilterSetImpl() })
+})
+
+var _ = Describe("FilterHashSetImpl tests", func() {
+	filterSetTests(func() tc.FilterSet { return tc.NewFilterHashSetImpl() })
+
+	It("removes filter and keeps remaining filters indexed", func() {
+		filterSet := tc.NewFilterHashSetImpl()
+		filters := []tctypes.Filter{
+			tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(100).Build(),
+			tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(200).Build(),
+			tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).WithPriority(300).Build(),
+		}
+		for _, f := range filters {
+			filterSet.Add(f)
+		}
+
+		filterSet.Remove(filters[0])
+
+		Expect(filterSet.Has(filters[0])).To(BeFalse())
+		Expect(filterSet.Has(filters[1])).To(BeTrue())
+		Expect(filterSet.Has(filters[2])).To(BeTrue())
+		filterSet.Remove(filters[2])
+		Expect(filterSet.List()).To(ConsistOf(filters[1]))
+	})
+
+	It("treats filters with different IP representation and nil or default chain as equal", func() {
+		_, ipNet, err := net.ParseCIDR("10.0.0.0/24")
+		Expect(err).ToNot(HaveOccurred())
+		ip16Net := &net.IPNet{IP: ipNet.IP.To16(), Mask: ipNet.Mask}
+		filterSet := tc.NewFilterHashSetImpl()
+		filterSet.Add(tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).
+			WithMatchKeyDstIP(ipNet).Build())
+
+		Expect(filterSet.Has(tctypes.NewFlowerFilterBuilder().WithProtocol(tctypes.FilterProtocolIPv4).
+			WithChain(0).WithMatchKeyDstIP(ip16Net).Build())).To(BeTrue())
+	})
+})
+
+// filterSetTests are FilterSet tests for the FilterSet implementation created by newFilterSet
+func filterSetTests(newFilterSet func() tc.FilterSet) {
 	var filterSet tc.FilterSet
 	ipToIpNet := func(ip string) *net.IPNet { ipn, _ := utils.IPToIPNet(ip); return ipn }
 
 	BeforeEach(func() {
-		filterSet = tc.NewFilterSetImpl()
+		filterSet = newFilterSet()
 	})
 
 	Context("FilterSet.Add()", func() {
@@ -61,6 +105,53 @@ var _ = Describe("FilterSetImpl tests", func() {
 		})
 	})
 
+	Context("FilterSet with filters of different kinds", func() {
+		drop := tctypes.NewGenericActionBuiler().WithDrop().Build()
+		filters := []tctypes.Filter{
+			tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(drop).
+				Build(),
+			tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(drop).
+				Build(),
+			tctypes.NewBasicFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithAction(drop).
+				Build(),
+		}
+
+		It("holds filters of different kinds with the same attributes", func() {
+			for i := range filters {
+				filterSet.Add(filters[i])
+			}
+
+			Expect(filterSet.Len()).To(Equal(3))
+			Expect(filterSet.List()).To(ContainElements(filters))
+		})
+
+		It("returns filters of other kinds in difference", func() {
+			other := newFilterSet()
+			for i := range filters {
+				filterSet.Add(filters[i])
+			}
+			other.Add(tctypes.NewMatchAllFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(300).
+				WithHandle(1).
+				WithAction(drop).
+				Build())
+
+			diff := filterSet.Difference(other).List()
+			Expect(diff).To(HaveLen(2))
+			Expect(diff).To(ContainElements(filters[0], filters[2]))
+		})
+	})
+
 	Context("FilterSet.Remove()", func() {
 		It("removes filter from set if exists", func() {
 			filter := tctypes.NewFlowerFilterBuilder().
@@ -174,7 +265,7 @@ var _ = Describe("FilterSetImpl tests", func() {
 
 		BeforeEach(func() {
 			this = filterSet
-			other = tc.NewFilterSetImpl()
+			other = newFilterSet()
 		})
 
 		It("returns true if this set in other", func() {
@@ -275,7 +366,7 @@ var _ = Describe("FilterSetImpl tests", func() {
 
 		BeforeEach(func() {
 			this = filterSet
-			other = tc.NewFilterSetImpl()
+			other = newFilterSet()
 		})
 
 		It("returns this if this is in other", func() {
@@ -370,7 +461,7 @@ var _ = Describe("FilterSetImpl tests", func() {
 
 		BeforeEach(func() {
 			this = filterSet
-			other = tc.NewFilterSetImpl()
+			other = newFilterSet()
 		})
 
 		It("returns set with filters that this filter has and not in other", func() {
@@ -382,7 +473,7 @@ var _ = Describe("FilterSetImpl tests", func() {
 
 			diff := this.Difference(other)
 
-			expected := tc.NewFilterSetImpl()
+			expected := newFilterSet()
 			expected.Add(filters[2])
 			expected.Add(filters[3])
 
@@ -463,7 +554,7 @@ var _ = Describe("FilterSetImpl tests", func() {
 
 		BeforeEach(func() {
 			this = filterSet
-			other = tc.NewFilterSetImpl()
+			other = newFilterSet()
 		})
 
 		It("returns true if both sets are empty", func() {
@@ -510,4 +601,61 @@ var _ = Describe("FilterSetImpl tests", func() {
 			Expect(filterSet.Len()).To(Equal(2))
 		})
 	})
-})
+}
+
+// benchmarkFilters returns n distinct flower filters, similar to filters generated for policy rules
+func benchmarkFilters(n int) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0, n)
+	for i := 0; i < n; i++ {
+		dstIP, _ := utils.IPToIPNet(fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff))
+		filters = append(filters, tctypes.NewFlowerFilterBuilder().
+			WithProtocol(tctypes.FilterProtocolIPv4).
+			WithPriority(uint16(200+i%8)).
+			WithMatchKeyIPProto(tctypes.FlowerIPProtoTCP).
+			WithMatchKeyDstIP(dstIP).
+			WithMatchKeyDstPort(uint16(i%1000)).
+			WithAction(tctypes.NewGenericAction(tctypes.ActionGenericPass)).
+			Build())
+	}
+	return filters
+}
+
+// benchmarkReconcile benchmarks the filter set operations of actuator reconciliation of n existing filters
+// with n new filters, half of which exist
+func benchmarkReconcile(b *testing.B, newFilterSet func() tc.FilterSet, n int) {
+	filters := benchmarkFilters(n + n/2)
+	existing, desired := filters[:n], filters[n/2:]
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		existingSet, desiredSet := newFilterSet(), newFilterSet()
+		for _, f := range existing {
+			existingSet.Add(f)
+		}
+		for _, f := range desired {
+			desiredSet.Add(f)
+		}
+		if existingSet.Equals(desiredSet) {
+			b.Fatal("expected different filter sets")
+		}
+		_ = existingSet.Difference(desiredSet).List()
+		_ = desiredSet.Difference(existingSet).List()
+	}
+}
+
+func BenchmarkFilterSetReconcile(b *testing.B) {
+	impls := []struct {
+		name         string
+		newFilterSet func() tc.FilterSet
+	}{
+		{"FilterSetImpl", func() tc.FilterSet { return tc.NewFilterSetImpl() }},
+		{"FilterHashSetImpl", func() tc.FilterSet { return tc.NewFilterHashSetImpl() }},
+	}
+	for _, n := range []int{100, 1000, 5000} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("%s/%d", impl.name, n), func(b *testing.B) {
+				benchmarkReconcile(b, impl.newFilterSet, n)
+			})
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"net"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+)
+
+// PolicyChain is the chain which holds policy filters when anti-spoofing is enabled.
+// anti-spoofing filters in chain 0 jump to this chain for traffic with valid source address.
+const PolicyChain uint32 = 1
+
+var (
+	// ipv6LinkLocal is the IPv6 link local network used as source address for neighbor discovery
+	ipv6LinkLocal = &net.IPNet{IP: net.ParseIP("fe80::"), Mask: net.CIDRMask(10, 128)}
+	// ipv6Unspecified is the IPv6 unspecified address used as source address during duplicate address detection
+	ipv6Unspecified = &net.IPNet{IP: net.IPv6unspecified, Mask: net.CIDRMask(128, 128)}
+	// ipv4Unspecified is the IPv4 unspecified address used as source address by DHCP clients and ARP probes
+	ipv4Unspecified = &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(32, 32)}
+
+	antiSpoofProtocols = [...]tctypes.FilterProtocol{
+		tctypes.FilterProtocolIPv4,
+		tctypes.FilterProtocolIPv6,
+		tctypes.FilterProtocolARP,
+	}
+)
+
+// genAntiSpoofFilters generates anti-spoofing filters for the provided interface in chain 0.
+// traffic with a valid source address is sent to PolicyChain, the rest is dropped. filters are generated as follows:
+//  1. goto PolicyChain for ipv4, ipv6 and arp traffic with (one of) interface IPs as source address
+//     (or arp sender address) at priority 1x. traffic sourced from IPv4/IPv6 unspecified address
+//     (DHCP, ARP probes, DAD) and from IPv6 link local address is allowed as well.
+//  2. drop ipv4, ipv6 and arp traffic at priority 2x
+//  3. goto PolicyChain for all traffic with interface MAC as source MAC at priority 3x
+//  4. drop all traffic at priority 4x
+//
+// if interface MAC is known, all filters in (1) match on source MAC as well. if interface MAC is unknown
+// (3) matches all traffic and (4) is omitted. if interface has no IPs, (1) and (2) are omitted.
+// all ip and arp filters are generated for untagged, 802.1Q tagged and 802.1ad tagged traffic.
+func genAntiSpoofFilters(ifcInfo policyrules.InterfaceInfo) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+	gotoPolicy := tctypes.NewGenericActionBuiler().WithGotoChain(PolicyChain).Build()
+	drop := tctypes.NewGenericActionBuiler().WithDrop().Build()
+	mac := ifcInfo.MAC
+
+	if len(ifcInfo.IPs) > 0 {
+		for _, srcMatch := range genAntiSpoofSourceMatches(ifcInfo.IPs) {
+			filters = append(filters, genFiltersForProto(srcMatch.proto, BasePrioSpoofPass, gotoPolicy,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					if mac != nil {
+						fb.WithMatchKeySrcMAC(mac)
+					}
+					srcMatch.withMatches(fb)
+				})...)
+		}
+		for _, proto := range antiSpoofProtocols {
+			filters = append(filters, genFiltersForProto(proto, BasePrioSpoofDrop, drop,
+				func(fb *tctypes.FlowerFilterBuilder) {})...)
+		}
+	}
+
+	macPass := tctypes.NewFlowerFilterBuilder().
+		WithProtocol(tctypes.FilterProtocolAll).
+		WithPriority(PrioFromBaseAndProtcol(BasePrioSpoofMACPass, tctypes.FilterProtocolAll)).
+		WithAction(gotoPolicy)
+	if mac == nil {
+		// source MAC cannot be enforced
+		return append(filters, macPass.Build())
+	}
+	filters = append(filters, macPass.WithMatchKeySrcMAC(mac).Build())
+	filters = append(filters, tctypes.NewFlowerFilterBuilder().
+		WithProtocol(tctypes.FilterProtocolAll).
+		WithPriority(PrioFromBaseAndProtcol(BasePrioSpoofMACDrop, tctypes.FilterProtocolAll)).
+		WithAction(drop).
+		Build())
+
+	return filters
+}
+
+// antiSpoofSourceMatch is a valid source address match for a given protocol
+type antiSpoofSourceMatch struct {
+	proto       tctypes.FilterProtocol
+	withMatches func(fb *tctypes.FlowerFilterBuilder)
+}
+
+// genAntiSpoofSourceMatches returns the valid source address matches for the provided interface IPs
+func genAntiSpoofSourceMatches(ips []net.IP) []antiSpoofSourceMatch {
+	matches := make([]antiSpoofSourceMatch, 0)
+
+	for _, ip := range ips {
+		ipNet := ipToHostIPNet(ip)
+		if utils.IsIPv4(ip) {
+			matches = append(matches,
+				antiSpoofSourceMatch{tctypes.FilterProtocolIPv4, func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeySrcIP(ipNet)
+				}},
+				antiSpoofSourceMatch{tctypes.FilterProtocolARP, func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyArpSIP(ipNet)
+				}})
+		} else {
+			matches = append(matches,
+				antiSpoofSourceMatch{tctypes.FilterProtocolIPv6, func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeySrcIP(ipNet)
+				}})
+		}
+	}
+
+	// unspecified and link local sources
+	matches = append(matches,
+		antiSpoofSourceMatch{tctypes.FilterProtocolIPv4, func(fb *tctypes.FlowerFilterBuilder) {
+			fb.WithMatchKeySrcIP(ipv4Unspecified).
+				WithMatchKeyIPProto(tctypes.FlowerIPProtoUDP).
+				WithMatchKeyDstPort(dhcpv4ServerPort)
+		}},
+		antiSpoofSourceMatch{tctypes.FilterProtocolARP, func(fb *tctypes.FlowerFilterBuilder) {
+			fb.WithMatchKeyArpSIP(ipv4Unspecified)
+		}},
+		antiSpoofSourceMatch{tctypes.FilterProtocolIPv6, func(fb *tctypes.FlowerFilterBuilder) {
+			fb.WithMatchKeySrcIP(ipv6Unspecified)
+		}},
+		antiSpoofSourceMatch{tctypes.FilterProtocolIPv6, func(fb *tctypes.FlowerFilterBuilder) {
+			fb.WithMatchKeySrcIP(ipv6LinkLocal)
+		}})
+
+	return matches
+}
+
+// genPolicyChainFilters moves the provided policy filters in chain 0 to PolicyChain. if no policy filters are provided
+// a filter which passes all traffic is generated in PolicyChain.
+func genPolicyChainFilters(policyFilters []tctypes.Filter) []tctypes.Filter {
+	if len(policyFilters) == 0 {
+		return []tctypes.Filter{
+			tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithChain(PolicyChain).
+				WithPriority(PrioFromBaseAndProtcol(BasePrioDefault, tctypes.FilterProtocolAll)).
+				WithAction(tctypes.NewGenericActionBuiler().WithPass().Build()).
+				Build(),
+		}
+	}
+
+	for _, f := range policyFilters {
+		if f.Attrs().Chain != nil && *f.Attrs().Chain != tctypes.ChainDefaultChain {
+			// filter already in a dedicated chain
+			continue
+		}
+		chain := PolicyChain
+		f.Attrs().Chain = &chain
+	}
+	return policyFilters
+}
+
+// ipToHostIPNet returns a host IPNet (/32 for IPv4, /128 for IPv6) for the provided IP
+func ipToHostIPNet(ip net.IP) *net.IPNet {
+	if utils.IsIPv4(ip) {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip.To16(), Mask: net.CIDRMask(128, 128)}
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"math"
+
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// RateLimitChain is the chain which holds filters otherwise generated in chain 0 when interface egress bandwidth
+// is limited. traffic which conforms to the rate limit jumps to this chain.
+const RateLimitChain uint32 = 3
+
+const (
+	// rateLimitBurstDivisor is used to calculate police burst as the amount of bytes sent at the
+	// rate limit in 10 milliseconds
+	rateLimitBurstDivisor = 100
+	// rateLimitMinBurst is the minimal police burst in bytes
+	rateLimitMinBurst = 64 * 1024
+)
+
+// genRateLimitFilters limits the traffic of the provided filters to the given bandwidth (in bits per second).
+// filters in chain 0 are moved to RateLimitChain and a filter which polices all traffic in chain 0, priority 4
+// is generated. conforming traffic jumps to RateLimitChain (or is passed if there are no filters), exceeding
+// traffic is dropped.
+// Note(adrianc): police action is followed by goto (rather than using continue as conform action) as this
+// is the form supported by hardware offload.
+func genRateLimitFilters(filters []tctypes.Filter, bandwidth uint64) []tctypes.Filter {
+	rate := bandwidth / 8
+	burst := rate / rateLimitBurstDivisor
+	if burst < rateLimitMinBurst {
+		burst = rateLimitMinBurst
+	}
+	if burst > math.MaxUint32 {
+		burst = math.MaxUint32
+	}
+
+	next := tctypes.NewGenericActionBuiler().WithGotoChain(RateLimitChain).Build()
+	if len(filters) == 0 {
+		next = tctypes.NewGenericActionBuiler().WithPass().Build()
+	}
+
+	rateLimitFilters := []tctypes.Filter{
+		tctypes.NewFlowerFilterBuilder().
+			WithProtocol(tctypes.FilterProtocolAll).
+			WithPriority(PrioFromBaseAndProtcol(BasePrioRateLimit, tctypes.FilterProtocolAll)).
+			WithAction(tctypes.NewPoliceActionBuilder().
+				WithRate(rate).
+				WithBurst(uint32(burst)).
+				WithConformExceed(tctypes.PoliceControlPipe, tctypes.PoliceControlDrop).
+				Build()).
+			WithAction(next).
+			Build(),
+	}
+
+	for _, f := range filters {
+		if f.Attrs().Chain != nil && *f.Attrs().Chain != tctypes.ChainDefaultChain {
+			// filter already in a dedicated chain
+			continue
+		}
+		chain := RateLimitChain
+		f.Attrs().Chain = &chain
+	}
+
+	return append(rateLimitFilters, filters...)
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+)
+
+// PortChainBase is the first chain which holds port filters generated by ChainTCGenerator.
+// port chains are allocated sequentially starting from this chain.
+const PortChainBase uint32 = 100
+
+// NewChainTCGenerator creates a new ChainTCGenerator instance
+func NewChainTCGenerator(opts Options) *ChainTCGenerator {
+	s := NewSimpleTCGenerator(opts)
+	s.portChains = true
+	return &ChainTCGenerator{*s}
+}
+
+// ChainTCGenerator is an implementation for Generator interface which uses goto chain actions to avoid
+// generating a filter per CIDR X Port of pass rules.
+// Filters are generated as in SimpleTCGenerator, except for pass rules with IPs and ports (which are not Global and
+// have no DSCP), for which filters are generated as follows:
+//  1. goto port chain rules per destination CIDR at chain 0, priority 290
+//  2. accept rules per Port at port chain
+//  3. drop rule for all traffic at port chain, priority 304
+//
+// destination CIDRs of such rules are split to disjoint CIDRs, a port chain is allocated starting from PortChainBase
+// for every set of ports allowed for a disjoint CIDR.
+// Note(adrianc): goto port chain rules are evaluated after all other pass rules in chain 0. as classification
+// does not return from port chain, traffic which does not match on the allowed ports is dropped there.
+type ChainTCGenerator struct {
+	SimpleTCGenerator
+}
+
+// isPortChainRule returns true if filters for rule are generated in port chains
+func isPortChainRule(rule policyrules.Rule) bool {
+	return rule.Action == policyrules.PolicyActionPass && !rule.Global && rule.DSCP == nil &&
+		len(rule.MACs) == 0 && len(rule.IPCidrs) > 0 && len(rule.Ports) > 0
+}
+
+// genPortChainFilters generates filters for the provided pass rules with IPs and ports. goto port chain filters
+// are generated at BasePrioPortChains per disjoint destination CIDR, accept filters per port and a drop filter are
+// generated in the port chain of each set of ports. in stateful mode, accept filters commit the connection in ctZone.
+func (s *SimpleTCGenerator) genPortChainFilters(rules []policyrules.Rule, ctZone uint16) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+
+	var ipCidrs []*net.IPNet
+	for _, rule := range rules {
+		ipCidrs = append(ipCidrs, rule.IPCidrs...)
+	}
+	disjoint := disjointCIDRs(ipCidrs)
+
+	// ports allowed per disjoint CIDR, identified by port set key
+	portSets := make(map[string][]policyrules.Port)
+	cidrPortSet := make([]string, len(disjoint))
+	for idx, ipCidr := range disjoint {
+		var ports []policyrules.Port
+		for _, rule := range rules {
+			for _, ruleCidr := range rule.IPCidrs {
+				if cidrContains(ruleCidr, ipCidr) {
+					ports = append(ports, rule.Ports...)
+					break
+				}
+			}
+		}
+		ports = uniquePorts(ports)
+		key := portSetKey(ports)
+		portSets[key] = ports
+		cidrPortSet[idx] = key
+	}
+
+	// allocate port chains in port set key order
+	keys := make([]string, 0, len(portSets))
+	for key := range portSets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+	portSetChain := make(map[string]uint32, len(keys))
+	for idx, key := range keys {
+		chain := PortChainBase + uint32(idx)
+		portSetChain[key] = chain
+
+		chainFilters := s.genFiltersWithPorts(portSets[key], BasePrioPass, pass)
+		if s.opts.Stateful {
+			chainFilters = withConnTrackCommit(chainFilters, ctZone)
+		}
+		chainFilters = append(chainFilters, tctypes.NewFlowerFilterBuilder().
+			WithProtocol(tctypes.FilterProtocolAll).
+			WithPriority(PrioFromBaseAndProtcol(BasePrioDefault, tctypes.FilterProtocolAll)).
+			WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+			Build())
+		for _, f := range chainFilters {
+			chain := chain
+			f.Attrs().Chain = &chain
+		}
+		filters = append(filters, chainFilters...)
+	}
+
+	for idx, ipCidr := range disjoint {
+		ipCidr := ipCidr
+		proto := tctypes.FilterProtocolIPv6
+		if utils.IsIPv4(ipCidr.IP) {
+			proto = tctypes.FilterProtocolIPv4
+		}
+		gotoPortChain := tctypes.NewGenericActionBuiler().WithGotoChain(portSetChain[cidrPortSet[idx]]).Build()
+		filters = append(filters, genFiltersForProto(proto, BasePrioPortChains, gotoPortChain,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				s.withMatchDstIP(fb, ipCidr)
+			})...)
+	}
+
+	return filters
+}
+
+// disjointCIDRs splits the provided CIDRs to disjoint CIDRs which cover the same addresses. each of the returned
+// CIDRs is contained in every provided CIDR it overlaps with. returned CIDRs are sorted by address.
+func disjointCIDRs(ipCidrs []*net.IPNet) []*net.IPNet {
+	sorted := make([]*net.IPNet, 0, len(ipCidrs))
+	for _, ipCidr := range ipCidrs {
+		sorted = append(sorted, &net.IPNet{IP: ipCidr.IP.Mask(ipCidr.Mask), Mask: ipCidr.Mask})
+	}
+	// less specific CIDRs first, a CIDR may then only be contained in (and not contain) previous disjoint CIDRs
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iOnes, _ := sorted[i].Mask.Size()
+		jOnes, _ := sorted[j].Mask.Size()
+		return iOnes < jOnes
+	})
+
+	disjoint := make([]*net.IPNet, 0, len(sorted))
+	for _, ipCidr := range sorted {
+		containing := -1
+		for idx, d := range disjoint {
+			if cidrContains(d, ipCidr) {
+				containing = idx
+				break
+			}
+		}
+		if containing < 0 {
+			disjoint = append(disjoint, ipCidr)
+			continue
+		}
+		if cidrEqual(disjoint[containing], ipCidr) {
+			continue
+		}
+		outer := disjoint[containing]
+		disjoint = append(disjoint[:containing], disjoint[containing+1:]...)
+		disjoint = append(disjoint, ipCidr)
+		disjoint = append(disjoint, cidrExclude(outer, ipCidr)...)
+	}
+
+	sort.Slice(disjoint, func(i, j int) bool {
+		if c := bytes.Compare(disjoint[i].IP, disjoint[j].IP); c != 0 {
+			return c < 0
+		}
+		iOnes, _ := disjoint[i].Mask.Size()
+		jOnes, _ := disjoint[j].Mask.Size()
+		return iOnes < jOnes
+	})
+	return disjoint
+}
+
+// uniquePorts returns the unique ports of the provided ports sorted by protocol and number
+func uniquePorts(ports []policyrules.Port) []policyrules.Port {
+	res := make([]policyrules.Port, 0, len(ports))
+	seen := make(map[policyrules.Port]struct{}, len(ports))
+	for _, port := range ports {
+		if _, ok := seen[port]; ok {
+			continue
+		}
+		seen[port] = struct{}{}
+		res = append(res, port)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Protocol != res[j].Protocol {
+			return res[i].Protocol < res[j].Protocol
+		}
+		return res[i].Number < res[j].Number
+	})
+	return res
+}
+
+// portSetKey returns a key which identifies the provided (unique, sorted) ports
+func portSetKey(ports []policyrules.Port) string {
+	keys := make([]string, 0, len(ports))
+	for _, port := range ports {
+		keys = append(keys, fmt.Sprintf("%s/%d", port.Protocol, port.Number))
+	}
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+)
+
+// AggregateCIDRs returns the minimal set of CIDRs which covers the same addresses as the provided CIDRs.
+// CIDRs covered by other CIDRs are removed and adjacent CIDRs are merged into their supernet. if canMerge is not nil,
+// two adjacent CIDRs are merged only if canMerge returns true for their supernet and each of them.
+// returned CIDRs are disjoint and sorted by address.
+func AggregateCIDRs(ipCidrs []*net.IPNet, canMerge func(supernet, ipCidr *net.IPNet) bool) []*net.IPNet {
+	sorted := make([]*net.IPNet, 0, len(ipCidrs))
+	for _, ipCidr := range ipCidrs {
+		ones, bits := ipCidr.Mask.Size()
+		ip := ipCidr.IP.To16()
+		if bits == 8*net.IPv4len {
+			ip = ipCidr.IP.To4()
+		}
+		mask := net.CIDRMask(ones, bits)
+		sorted = append(sorted, &net.IPNet{IP: ip.Mask(mask), Mask: mask})
+	}
+	// ipv4 first, then by address, less specific CIDRs first
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].IP) != len(sorted[j].IP) {
+			return len(sorted[i].IP) < len(sorted[j].IP)
+		}
+		if c := bytes.Compare(sorted[i].IP, sorted[j].IP); c != 0 {
+			return c < 0
+		}
+		iOnes, _ := sorted[i].Mask.Size()
+		jOnes, _ := sorted[j].Mask.Size()
+		return iOnes < jOnes
+	})
+
+	// a CIDR covered by another CIDR follows it or another covered CIDR, hence it is covered by the last CIDR kept
+	res := make([]*net.IPNet, 0, len(sorted))
+	for _, ipCidr := range sorted {
+		if len(res) > 0 && cidrContains(res[len(res)-1], ipCidr) {
+			continue
+		}
+		// merge with previous CIDRs while adjacent
+		for len(res) > 0 {
+			prev := res[len(res)-1]
+			supernet := cidrSupernet(prev)
+			if supernet == nil || !cidrEqual(supernet, cidrSupernet(ipCidr)) || cidrEqual(prev, ipCidr) {
+				break
+			}
+			if canMerge != nil && (!canMerge(supernet, prev) || !canMerge(supernet, ipCidr)) {
+				break
+			}
+			res = res[:len(res)-1]
+			ipCidr = supernet
+		}
+		res = append(res, ipCidr)
+	}
+	return res
+}
+
+// cidrSupernet returns the CIDR with prefix one bit shorter than ipCidr which contains it, nil if ipCidr
+// has zero prefix length
+func cidrSupernet(ipCidr *net.IPNet) *net.IPNet {
+	ones, bits := ipCidr.Mask.Size()
+	if ones == 0 {
+		return nil
+	}
+	mask := net.CIDRMask(ones-1, bits)
+	return &net.IPNet{IP: ipCidr.IP.Mask(mask), Mask: mask}
+}
+
+// cidrContains returns true if inner CIDR is contained in outer CIDR
+func cidrContains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+// cidrEqual returns true if both CIDRs are the same network
+func cidrEqual(a, b *net.IPNet) bool {
+	return cidrContains(a, b) && cidrContains(b, a)
+}
+
+// cidrExclude returns the disjoint CIDRs which cover the addresses of outer CIDR which are not in inner CIDR.
+// inner CIDR is expected to be contained in outer CIDR.
+func cidrExclude(outer, inner *net.IPNet) []*net.IPNet {
+	outerOnes, bits := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	res := make([]*net.IPNet, 0, innerOnes-outerOnes)
+	for ones := outerOnes + 1; ones <= innerOnes; ones++ {
+		mask := net.CIDRMask(ones, bits)
+		// sibling of inner CIDR prefix at this prefix length
+		ip := inner.IP.Mask(mask)
+		ip[(ones-1)/8] ^= 0x80 >> ((ones - 1) % 8)
+		res = append(res, &net.IPNet{IP: ip, Mask: mask})
+	}
+	return res
+}
+
+// aggregateRules merges rules with IPs (and no MACs) which have the same action, ports, DSCP and Global attributes
+// into a single rule whose CIDRs are aggregated (see AggregateCIDRs). merged rule is placed at the position
+// of the first rule. if MulticastMAC is set in Options, CIDRs which match on a destination MAC are not merged with
+// CIDRs which do not.
+// Note(adrianc): drop and pass rules are never merged, as drop filters are evaluated before pass filters
+// the traffic dropped (e.g by ipBlock except) is not changed.
+func (s *SimpleTCGenerator) aggregateRules(rules []policyrules.Rule) []policyrules.Rule {
+	var canMerge func(supernet, ipCidr *net.IPNet) bool
+	if s.opts.MulticastMAC {
+		canMerge = func(supernet, ipCidr *net.IPNet) bool {
+			return (dstMACForIPNet(supernet) == nil) == (dstMACForIPNet(ipCidr) == nil)
+		}
+	}
+
+	res := make([]policyrules.Rule, 0, len(rules))
+	groupIdx := make(map[string]int)
+	for _, rule := range rules {
+		if len(rule.IPCidrs) == 0 || len(rule.MACs) > 0 {
+			res = append(res, rule)
+			continue
+		}
+		key := fmt.Sprintf("%s/%t/%s", rule.Action, rule.Global, portSetKey(uniquePorts(rule.Ports)))
+		if rule.DSCP != nil {
+			key = fmt.Sprintf("%s/%d", key, *rule.DSCP)
+		}
+		idx, ok := groupIdx[key]
+		if !ok {
+			groupIdx[key] = len(res)
+			rule.IPCidrs = append([]*net.IPNet{}, rule.IPCidrs...)
+			res = append(res, rule)
+			continue
+		}
+		res[idx].IPCidrs = append(res[idx].IPCidrs, rule.IPCidrs...)
+	}
+
+	for idx := range res {
+		if len(res[idx].IPCidrs) > 0 && len(res[idx].MACs) == 0 {
+			res[idx].IPCidrs = AggregateCIDRs(res[idx].IPCidrs, canMerge)
+		}
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"hash/fnv"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// ConnTrackChain is the chain which holds policy filters when stateful mode is enabled.
+// connection tracked traffic jumps to this chain.
+const ConnTrackChain uint32 = 2
+
+// ConnTrackZone returns the connection tracking zone of the interface of ifcInfo. each interface uses its own zone
+// (1 - 65535) derived from the interface identity so connections committed on one interface are not established
+// on another. as zones are hashed, two interfaces may share a zone on rare occasions.
+func ConnTrackZone(ifcInfo policyrules.InterfaceInfo) uint16 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ifcKey(ifcInfo)))
+	return uint16(h.Sum32()%0xffff) + 1
+}
+
+// genConnTrackFilters generates connection tracking filters in zone for the provided policy filters and moves
+// policy filters in chain 0 to ConnTrackChain. filters are generated as follows:
+//  1. send ipv4 and ipv6 traffic through connection tracking and goto ConnTrackChain at chain 0, priority 1x
+//  2. goto ConnTrackChain for all other traffic at chain 0, priority 1x
+//  3. accept established ipv4 and ipv6 traffic at ConnTrackChain, priority 2x
+//
+// ip filters are generated for untagged, 802.1Q tagged and 802.1ad tagged traffic.
+// Note: policy pass filters are expected to commit the connection in zone (see withConnTrackCommit)
+// Note(adrianc): only traffic sent from the interface is connection tracked, established traffic is traffic of
+// connections originated by the pod. replies of the pod to connections originated by its peers are subject to policy.
+func genConnTrackFilters(policyFilters []tctypes.Filter, zone uint16) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+	ct := tctypes.NewConnTrackActionBuilder().WithZone(zone).Build()
+	gotoConnTrack := tctypes.NewGenericActionBuiler().WithGotoChain(ConnTrackChain).Build()
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+
+	for _, proto := range ipProtocols {
+		// ct action is followed by goto action
+		filters = append(filters, genFiltersForProto(proto, BasePrioConnTrack, ct,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				fb.WithAction(gotoConnTrack)
+			})...)
+		filters = append(filters, genFiltersForProto(proto, BasePrioEstablished, pass,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				fb.WithChain(ConnTrackChain).WithMatchKeyCtState(tctypes.FlowerCtStateEstablished)
+			})...)
+	}
+
+	filters = append(filters, tctypes.NewFlowerFilterBuilder().
+		WithProtocol(tctypes.FilterProtocolAll).
+		WithPriority(PrioFromBaseAndProtcol(BasePrioConnTrack, tctypes.FilterProtocolAll)).
+		WithAction(gotoConnTrack).
+		Build())
+
+	for _, f := range policyFilters {
+		if f.Attrs().Chain != nil && *f.Attrs().Chain != tctypes.ChainDefaultChain {
+			// filter already in a dedicated chain
+			filters = append(filters, f)
+			continue
+		}
+		chain := ConnTrackChain
+		f.Attrs().Chain = &chain
+		filters = append(filters, f)
+	}
+
+	return filters
+}
+
+// withConnTrackCommit prepends a connection tracking commit action in zone to the actions of the provided filters
+func withConnTrackCommit(filters []tctypes.Filter, zone uint16) []tctypes.Filter {
+	commit := tctypes.NewConnTrackActionBuilder().WithCommit().WithZone(zone).Build()
+	for _, f := range filters {
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if !ok {
+			continue
+		}
+		flowerFilter.Actions = append([]tctypes.Action{commit}, flowerFilter.Actions...)
+	}
+	return filters
+}
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+const (
+	// ControlTrafficND is IPv6 Neighbor Discovery traffic (router/neighbor solicitation and advertisement)
+	ControlTrafficND ControlTrafficType = "nd"
+	// ControlTrafficDHCP is DHCPv4 and DHCPv6 client traffic
+	ControlTrafficDHCP ControlTrafficType = "dhcp"
+	// ControlTrafficARP is ARP traffic
+	ControlTrafficARP ControlTrafficType = "arp"
+	// ControlTrafficIGMP is IPv4 multicast group management (IGMP) traffic
+	ControlTrafficIGMP ControlTrafficType = "igmp"
+	// ControlTrafficMLD is IPv6 multicast group management (MLD) traffic
+	ControlTrafficMLD ControlTrafficType = "mld"
+	// ControlTrafficBroadcast is ARP and IPv4 limited broadcast traffic sent to the broadcast MAC address
+	ControlTrafficBroadcast ControlTrafficType = "broadcast"
+)
+
+const (
+	icmpv6TypeRouterSolicitation    uint8 = 133
+	icmpv6TypeRouterAdvertisement   uint8 = 134
+	icmpv6TypeNeighborSolicitation  uint8 = 135
+	icmpv6TypeNeighborAdvertisement uint8 = 136
+	icmpv6TypeMLDQuery              uint8 = 130
+	icmpv6TypeMLDReport             uint8 = 131
+	icmpv6TypeMLDDone               uint8 = 132
+	icmpv6TypeMLDv2Report           uint8 = 143
+
+	dhcpv4ServerPort uint16 = 67
+	dhcpv6ServerPort uint16 = 547
+)
+
+var (
+	ndICMPv6Types = [...]uint8{
+		icmpv6TypeRouterSolicitation,
+		icmpv6TypeRouterAdvertisement,
+		icmpv6TypeNeighborSolicitation,
+		icmpv6TypeNeighborAdvertisement,
+	}
+	mldICMPv6Types = [...]uint8{
+		icmpv6TypeMLDQuery,
+		icmpv6TypeMLDReport,
+		icmpv6TypeMLDDone,
+		icmpv6TypeMLDv2Report,
+	}
+)
+
+// ControlTrafficType is a type of essential L2/L3 control traffic
+type ControlTrafficType string
+
+// ControlTrafficTypeFromString returns ControlTrafficType from its string representation,
+// an error is returned if string does not represent a known ControlTrafficType
+func ControlTrafficTypeFromString(s string) (ControlTrafficType, error) {
+	ct := ControlTrafficType(strings.ToLower(strings.TrimSpace(s)))
+	switch ct {
+	case ControlTrafficND, ControlTrafficDHCP, ControlTrafficARP, ControlTrafficIGMP, ControlTrafficMLD,
+		ControlTrafficBroadcast:
+		return ct, nil
+	}
+	return "", fmt.Errorf("unknown control traffic type: %s", s)
+}
+
+// genControlFilters generates filters with pass action for the provided control traffic types
+// at BasePrioControl. each match is generated for untagged, 802.1Q tagged and 802.1ad tagged traffic.
+// broadcast traffic is matched by broadcast destination MAC for ARP and by broadcast destination MAC and IPv4 limited
+// broadcast destination IP for IPv4, traffic of other protocols sent to the broadcast MAC is subject to policy.
+func genControlFilters(controlTraffic []ControlTrafficType) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+	seen := make(map[ControlTrafficType]struct{})
+
+	for _, ct := range controlTraffic {
+		if _, ok := seen[ct]; ok {
+			continue
+		}
+		seen[ct] = struct{}{}
+
+		switch ct {
+		case ControlTrafficND:
+			filters = append(filters, genICMPv6ControlFilters(ndICMPv6Types[:], pass)...)
+		case ControlTrafficDHCP:
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolIPv4, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyIPProto(tctypes.FlowerIPProtoUDP).WithMatchKeyDstPort(dhcpv4ServerPort)
+				})...)
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolIPv6, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyIPProto(tctypes.FlowerIPProtoUDP).WithMatchKeyDstPort(dhcpv6ServerPort)
+				})...)
+		case ControlTrafficARP:
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolARP, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {})...)
+		case ControlTrafficIGMP:
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolIPv4, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyIPProto(tctypes.FlowerIPProtoIGMP)
+				})...)
+		case ControlTrafficMLD:
+			filters = append(filters, genICMPv6ControlFilters(mldICMPv6Types[:], pass)...)
+		case ControlTrafficBroadcast:
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolARP, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyDstMAC(macBroadcast, nil)
+				})...)
+			filters = append(filters, genFiltersForProto(tctypes.FilterProtocolIPv4, BasePrioControl, pass,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyDstMAC(macBroadcast, nil).WithMatchKeyDstIP(ipv4Broadcast)
+				})...)
+		}
+	}
+
+	return filters
+}
+
+// genICMPv6ControlFilters generates filters with the given action for the provided ICMPv6 types at BasePrioControl
+func genICMPv6ControlFilters(icmpTypes []uint8, action tctypes.Action) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+	for _, icmpType := range icmpTypes {
+		icmpType := icmpType
+		filters = append(filters, genFiltersForProto(tctypes.FilterProtocolIPv6, BasePrioControl, action,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				fb.WithMatchKeyIPProto(tctypes.FlowerIPProtoICMPv6).WithMatchKeyICMPType(icmpType)
+			})...)
+	}
+	return filters
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// withDSCPMarking prepends actions which set the given DSCP value to the actions of the provided ip filters.
+// ipv4 header checksum is updated after DSCP is set. filters which do not match ipv4 or ipv6 traffic are not modified.
+// Note: double tagged (802.1ad) filters are not modified as the inner VLAN tag is part of the packet data
+// at the time it is classified, hence ip header cannot be edited.
+func withDSCPMarking(filters []tctypes.Filter, dscp uint8) []tctypes.Filter {
+	for _, f := range filters {
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if !ok {
+			continue
+		}
+
+		var header tctypes.PeditHeaderType
+		switch ipProtocolOf(flowerFilter) {
+		case tctypes.FilterProtocolIPv4:
+			header = tctypes.PeditHeaderIPv4
+		case tctypes.FilterProtocolIPv6:
+			header = tctypes.PeditHeaderIPv6
+		default:
+			continue
+		}
+
+		actions := []tctypes.Action{tctypes.NewPeditDSCPActionBuilder().WithHeader(header).WithDSCP(dscp).Build()}
+		if header == tctypes.PeditHeaderIPv4 {
+			actions = append(actions, tctypes.NewCsumAction(tctypes.CsumUpdateIPv4Header))
+		}
+		flowerFilter.Actions = append(actions, flowerFilter.Actions...)
+	}
+	return filters
+}
+
+// ipProtocolOf returns the ip protocol (ipv4 or ipv6) of untagged or single tagged (802.1Q) traffic
+// matched by filter, "" is returned if filter does not match such traffic
+func ipProtocolOf(filter *tctypes.FlowerFilter) tctypes.FilterProtocol {
+	switch filter.Protocol {
+	case tctypes.FilterProtocolIPv4, tctypes.FilterProtocolIPv6:
+		return filter.Protocol
+	case tctypes.FilterProtocol8021Q:
+		if filter.Flower == nil || filter.Flower.VlanEthType == nil {
+			return ""
+		}
+		switch *filter.Flower.VlanEthType {
+		case tctypes.FlowerVlanEthTypeIPv4:
+			return tctypes.FilterProtocolIPv4
+		case tctypes.FlowerVlanEthTypeIPv6:
+			return tctypes.FilterProtocolIPv6
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+)
+
+const (
+	// FragmentsPolicyAllow allows non first IP fragments matching the destination of pass rules with ports
+	FragmentsPolicyAllow FragmentsPolicy = "allow"
+	// FragmentsPolicyDrop drops non first IP fragments, first fragments carry the L4 header and are subject to policy
+	FragmentsPolicyDrop FragmentsPolicy = "drop"
+)
+
+// FragmentsPolicy is the policy applied to IP fragments on an isolated interface.
+// flower filters matching on ports do not match non first IP fragments (which do not carry L4 header),
+// empty FragmentsPolicy leaves these fragments to be dropped by default filters.
+type FragmentsPolicy string
+
+// FragmentsPolicyFromString returns FragmentsPolicy from its string representation,
+// an error is returned if string does not represent a known FragmentsPolicy
+func FragmentsPolicyFromString(s string) (FragmentsPolicy, error) {
+	fp := FragmentsPolicy(strings.ToLower(strings.TrimSpace(s)))
+	switch fp {
+	case "", FragmentsPolicyAllow, FragmentsPolicyDrop:
+		return fp, nil
+	}
+	return "", fmt.Errorf("unknown fragments policy: %s", s)
+}
+
+// genFragmentsFilters generates filters for IP fragments according to the provided FragmentsPolicy as follows:
+//
+//	FragmentsPolicyAllow: pass non first fragments for every destination of pass rules with ports
+//	at priority 2xx
+//	FragmentsPolicyDrop: drop non first fragments at priority 15x
+//
+// each match is generated for untagged, 802.1Q tagged and 802.1ad tagged traffic.
+func genFragmentsFilters(policy FragmentsPolicy, rules []policyrules.Rule) []tctypes.Filter {
+	switch policy {
+	case FragmentsPolicyAllow:
+		return genAllowFragmentsFilters(rules)
+	case FragmentsPolicyDrop:
+		filters := make([]tctypes.Filter, 0)
+		drop := tctypes.NewGenericActionBuiler().WithDrop().Build()
+		for _, proto := range ipProtocols {
+			filters = append(filters, genFiltersForProto(proto, BasePrioFragments, drop,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyIPFlags(tctypes.FlowerIPFlagsNoFirstFrag)
+				})...)
+		}
+		return filters
+	}
+	return nil
+}
+
+// genAllowFragmentsFilters generates filters which pass non first fragments for every destination of pass rules
+// with ports. if a pass rule with ports has no IPs (or MACs), non first fragments are passed for all destinations.
+func genAllowFragmentsFilters(rules []policyrules.Rule) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+	seen := make(map[string]struct{})
+
+	genForProto := func(proto tctypes.FilterProtocol, ipCidr *net.IPNet, mac net.HardwareAddr) {
+		filters = append(filters, genFiltersForProto(proto, BasePrioPass, pass,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				if ipCidr != nil {
+					fb.WithMatchKeyDstIP(ipCidr)
+				}
+				if mac != nil {
+					fb.WithMatchKeyDstMAC(mac, nil)
+				}
+				fb.WithMatchKeyIPFlags(tctypes.FlowerIPFlagsNoFirstFrag)
+			})...)
+	}
+
+	for _, rule := range rules {
+		if rule.Action != policyrules.PolicyActionPass || len(rule.Ports) == 0 {
+			continue
+		}
+
+		for _, mac := range rule.MACs {
+			if _, ok := seen[mac.String()]; ok {
+				continue
+			}
+			seen[mac.String()] = struct{}{}
+			for _, proto := range ipProtocols {
+				genForProto(proto, nil, mac)
+			}
+		}
+
+		if len(rule.IPCidrs) == 0 && len(rule.MACs) == 0 {
+			if _, ok := seen[""]; ok {
+				continue
+			}
+			seen[""] = struct{}{}
+			for _, proto := range ipProtocols {
+				genForProto(proto, nil, nil)
+			}
+			continue
+		}
+
+		for _, ipCidr := range rule.IPCidrs {
+			if _, ok := seen[ipCidr.String()]; ok {
+				continue
+			}
+			seen[ipCidr.String()] = struct{}{}
+			proto := tctypes.FilterProtocolIPv6
+			if utils.IsIPv4(ipCidr.IP) {
+				proto = tctypes.FilterProtocolIPv4
+			}
+			genForProto(proto, ipCidr, nil)
+		}
+	}
+
+	return filters
+}
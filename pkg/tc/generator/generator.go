@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
 	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 )
@@ -9,12 +10,49 @@ import (
 type Objects struct {
 	// QDisc is the TC QDisc where rules should be applied
 	QDisc tctypes.QDisc
-	// Filters are the TC filters that should be applied
+	// Filters are the TC filters that should be applied. filters are attached to the QDisc hook set in their
+	// Parent (ingress hook if not set), egress hook filters require a clsact QDisc.
 	Filters []tctypes.Filter
+	// Chains are the TC chains with templates that should be created before Filters are applied, on the QDisc hook
+	// set in their Parent
+	Chains []tctypes.Chain
+}
+
+// Options holds generator options
+type Options struct {
+	// ControlTraffic is the set of essential control traffic types which are always allowed
+	// on an isolated interface
+	ControlTraffic []ControlTrafficType
+	// StrictMode if set, all traffic (not only IP traffic) is dropped by default on an isolated interface.
+	// strict mode may also be enabled per network.
+	StrictMode bool
+	// Stateful if set, traffic on an isolated interface is connection tracked. connections allowed by policy
+	// are committed and established traffic is allowed.
+	Stateful bool
+	// Fragments is the policy applied to IP fragments on an isolated interface
+	Fragments FragmentsPolicy
+	// MulticastMAC if set, rules with ipv4/ipv6 multicast or ipv4 broadcast destination CIDRs match on the
+	// corresponding multicast or broadcast destination MAC as well
+	MulticastMAC bool
+	// RulePriorities if set, filters of each policy rule are generated at a dedicated priority allocated in
+	// the priority band of the rule action (see PrioAllocator). allocations are kept across generations
+	// for the same interface.
+	RulePriorities bool
+	// VlanMode is the VLAN mode of networks which do not configure a VLAN mode. empty VlanMode is
+	// controllers.VlanModeTagged.
+	VlanMode controllers.VlanMode
+	// ChainTemplates if set, policy filters which match on the same mask are grouped in dedicated chains declared
+	// with a chain template of that mask (see genTemplateChains)
+	ChainTemplates bool
+	// Clsact if set, objects are generated on a clsact qdisc instead of an ingress qdisc. generated filters and
+	// chains are attached to the clsact ingress hook, an existing ingress qdisc is replaced.
+	Clsact bool
 }
 
 // Generator is an interface to generate Objects from PolicyRuleSet
 type Generator interface {
 	// GenerateFromPolicyRuleSet creates Objects that correspond to the provided ruleSet
 	GenerateFromPolicyRuleSet(ruleSet policyrules.PolicyRuleSet) (*Objects, error)
+	// RetainInterfaces releases the state kept across generations for interfaces which are not in ifcs
+	RetainInterfaces(ifcs []policyrules.InterfaceInfo)
 }
@@ -1,11 +1,18 @@
 package generator_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
 	"net"
+	"sort"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
 
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
@@ -33,6 +40,39 @@ func filtersEqual(actualFilters, expectedFilters tc.FilterSet) {
 	ExpectWithOffset(1, expectedFilters.Difference(actualFilters).List()).To(BeEmpty())
 }
 
+// withDoubleTaggedFilters returns a new FilterSet with the filters in fs and
+// for each 802.1Q filter in fs, its double tagged (802.1ad) counterpart
+func withDoubleTaggedFilters(fs tc.FilterSet) tc.FilterSet {
+	res := tc.NewFilterSetImpl()
+	prioDiff := generator.PrioFromBaseAndProtcol(0, types.FilterProtocol8021AD) -
+		generator.PrioFromBaseAndProtcol(0, types.FilterProtocol8021Q)
+
+	for _, f := range fs.List() {
+		res.Add(f)
+
+		flowerFilter, ok := f.(*types.FlowerFilter)
+		ExpectWithOffset(1, ok).To(BeTrue())
+		if flowerFilter.Protocol != types.FilterProtocol8021Q {
+			continue
+		}
+
+		flower := *flowerFilter.Flower
+		flower.CVlanEthType = flower.VlanEthType
+		vlanEthType := types.FlowerVlanEthType8021Q
+		flower.VlanEthType = &vlanEthType
+		prio := *flowerFilter.Priority + prioDiff
+
+		res.Add(&types.FlowerFilter{
+			FilterAttrs: *types.NewFilterAttrs(flowerFilter.Kind, types.FilterProtocol8021AD, flowerFilter.Chain,
+				flowerFilter.Handle, &prio),
+			Flower:  &flower,
+			Actions: flowerFilter.Actions,
+		})
+	}
+
+	return res
+}
+
 func ipToProto(ip net.IP) types.FilterProtocol {
 	proto := types.FilterProtocolIPv6
 	if utils.IsIPv4(ip) {
@@ -51,6 +91,127 @@ func filterSetFromFilters(filters []types.Filter) tc.FilterSet {
 	return fs
 }
 
+var (
+	passAction = types.NewGenericActionBuiler().WithPass().Build()
+	dropAction = types.NewGenericActionBuiler().WithDrop().Build()
+	ipProtos   = []types.FilterProtocol{types.FilterProtocolIPv4, types.FilterProtocolIPv6}
+)
+
+func gotoChainAction(chain uint32) types.Action {
+	return types.NewGenericActionBuiler().WithGotoChain(chain).Build()
+}
+
+// egressRuleSet returns an egress PolicyRuleSet with rules for an interface with no info
+func egressRuleSet(rules []policyrules.Rule) policyrules.PolicyRuleSet {
+	return policyrules.PolicyRuleSet{
+		IfcInfo: policyrules.InterfaceInfo{},
+		Type:    policyrules.PolicyTypeEgress,
+		Rules:   rules,
+	}
+}
+
+// untaggedRuleSet returns an egress PolicyRuleSet with rules for an interface of an untagged network
+func untaggedRuleSet(rules []policyrules.Rule) policyrules.PolicyRuleSet {
+	rs := egressRuleSet(rules)
+	rs.IfcInfo.NetworkConfig.VlanMode = string(controllers.VlanModeUntagged)
+	return rs
+}
+
+// ipRule returns a rule with action for ipCidrs
+func ipRule(action policyrules.PolicyAction, ipCidrs ...string) policyrules.Rule {
+	r := policyrules.Rule{Action: action}
+	for _, ipCidr := range ipCidrs {
+		r.IPCidrs = append(r.IPCidrs, ipnetFromStr(ipCidr))
+	}
+	return r
+}
+
+// tcpRule returns a rule with action for ipCidr and tcp port, rules with different ports are not aggregated
+func tcpRule(action policyrules.PolicyAction, ipCidr string, port uint16) policyrules.Rule {
+	return withPorts(ipRule(action, ipCidr), tcpPort(port))
+}
+
+func withPorts(rule policyrules.Rule, ports ...policyrules.Port) policyrules.Rule {
+	rule.Ports = ports
+	return rule
+}
+
+func tcpPort(number uint16) policyrules.Port {
+	return policyrules.Port{Protocol: policyrules.ProtocolTCP, Number: number}
+}
+
+// genObjects generates objects for ruleSet with a SimpleTCGenerator with opts
+func genObjects(opts generator.Options, ruleSet policyrules.PolicyRuleSet) *generator.Objects {
+	return genObjectsWith(generator.NewSimpleTCGenerator(opts), ruleSet)
+}
+
+// genObjectsWith generates objects for ruleSet with gen
+func genObjectsWith(gen generator.Generator, ruleSet policyrules.PolicyRuleSet) *generator.Objects {
+	tcObj, err := gen.GenerateFromPolicyRuleSet(ruleSet)
+	ensureCallAndQdisc(tcObj, err)
+	return tcObj
+}
+
+// protoFilter returns a flower filter builder for traffic of proto at basePrio, for 802.1Q tagged traffic of proto
+// if tagged
+func protoFilter(proto types.FilterProtocol, basePrio generator.BasePrio, tagged bool) *types.FlowerFilterBuilder {
+	if tagged {
+		return types.NewFlowerFilterBuilder().
+			WithProtocol(types.FilterProtocol8021Q).
+			WithPriority(generator.PrioFromBaseAndProtcol(basePrio, types.FilterProtocol8021Q)).
+			WithMatchKeyVlanEthType(types.ProtoToFlowerVlanEthType(proto))
+	}
+	return types.NewFlowerFilterBuilder().
+		WithProtocol(proto).
+		WithPriority(generator.PrioFromBaseAndProtcol(basePrio, proto))
+}
+
+// protoFilters returns the untagged and 802.1Q tagged filters of proto at basePrio with action as last action.
+// matches and other actions are added by with, if not nil
+func protoFilters(proto types.FilterProtocol, basePrio generator.BasePrio, action types.Action,
+	with func(fb *types.FlowerFilterBuilder)) []types.Filter {
+	filters := make([]types.Filter, 0, 2)
+	for _, tagged := range []bool{false, true} {
+		fb := protoFilter(proto, basePrio, tagged)
+		if with != nil {
+			with(fb)
+		}
+		filters = append(filters, fb.WithAction(action).Build())
+	}
+	return filters
+}
+
+// filtersBelowPrio returns the filters with priority lower than basePrio
+func filtersBelowPrio(filters []types.Filter, basePrio generator.BasePrio) tc.FilterSet {
+	fs := tc.NewFilterSetImpl()
+	for _, f := range filters {
+		if *f.Attrs().Priority < uint16(basePrio) {
+			fs.Add(f)
+		}
+	}
+	return fs
+}
+
+// prioOf returns the priority of the untagged ipv4 flower filter matching on ipCidr
+func prioOf(filters []types.Filter, ipCidr string) uint16 {
+	for _, f := range filters {
+		flowerFilter, ok := f.(*types.FlowerFilter)
+		if ok && flowerFilter.Protocol == types.FilterProtocolIPv4 && flowerFilter.Flower != nil &&
+			flowerFilter.Flower.DstIP != nil && flowerFilter.Flower.DstIP.String() == ipCidr {
+			return *f.Attrs().Priority
+		}
+	}
+	Fail("no filter for " + ipCidr)
+	return 0
+}
+
+func chainOf(f types.Filter) uint32 {
+	if f.Attrs().Chain == nil {
+		return types.ChainDefaultChain
+	}
+	return *f.Attrs().Chain
+}
+
 var _ = Describe("filter priority tests", func() {
 	DescribeTable("returns expected priority for BasePrio and Protocol",
 		func(basePrio generator.BasePrio, proto types.FilterProtocol, expectedPrio int) {
@@ -65,6 +226,16 @@ var _ = Describe("filter priority tests", func() {
 		Entry("Drop priority IPv4 = 100", generator.BasePrioDrop, types.FilterProtocolIPv4, 100),
 		Entry("Drop priority IPv6 = 101", generator.BasePrioDrop, types.FilterProtocolIPv6, 101),
 		Entry("Drop priority 802.1Q = 102", generator.BasePrioDrop, types.FilterProtocol8021Q, 102),
+		Entry("Control priority IPv4 = 50", generator.BasePrioControl, types.FilterProtocolIPv4, 50),
+		Entry("Control priority IPv6 = 51", generator.BasePrioControl, types.FilterProtocolIPv6, 51),
+		Entry("Control priority 802.1Q = 52", generator.BasePrioControl, types.FilterProtocol8021Q, 52),
+		Entry("Control priority ARP = 53", generator.BasePrioControl, types.FilterProtocolARP, 53),
+		Entry("Default priority all = 304", generator.BasePrioDefault, types.FilterProtocolAll, 304),
+		Entry("Default priority 802.1ad = 305", generator.BasePrioDefault, types.FilterProtocol8021AD, 305),
+		Entry("Pass priority 802.1ad = 205", generator.BasePrioPass, types.FilterProtocol8021AD, 205),
+		Entry("Drop priority 802.1ad = 105", generator.BasePrioDrop, types.FilterProtocol8021AD, 105),
+		Entry("Global pass priority IPv4 = 80", generator.BasePrioGlobalPass, types.FilterProtocolIPv4, 80),
+		Entry("Port chains priority IPv4 = 290", generator.BasePrioPortChains, types.FilterProtocolIPv4, 290),
 	)
 })
 
@@ -93,10 +264,24 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 			WithMatchKeyVlanEthType(types.FlowerVlanEthTypeIPv6).
 			WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
 			Build(),
+		types.NewFlowerFilterBuilder().
+			WithPriority(generator.PrioFromBaseAndProtcol(generator.BasePrioDefault, types.FilterProtocol8021AD)).
+			WithProtocol(types.FilterProtocol8021AD).
+			WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+			WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv4).
+			WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
+			Build(),
+		types.NewFlowerFilterBuilder().
+			WithPriority(generator.PrioFromBaseAndProtcol(generator.BasePrioDefault, types.FilterProtocol8021AD)).
+			WithProtocol(types.FilterProtocol8021AD).
+			WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+			WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv6).
+			WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
+			Build(),
 	}
 
 	BeforeEach(func() {
-		generatorInst = generator.NewSimpleTCGenerator()
+		generatorInst = generator.NewSimpleTCGenerator(generator.Options{})
 	})
 
 	Context("GenerateFromPolicyRuleSet() Basic", func() {
@@ -124,7 +309,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 			Expect(tcObj.Filters).To(HaveLen(len(defaultFilters)))
 			expectedFilters := filterSetFromFilters(defaultFilters)
 			actualFilters := filterSetFromFilters(tcObj.Filters)
-			filtersEqual(actualFilters, expectedFilters)
+			filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 		})
 
 		It("fails to generate objects if PolicyRuleSet is Ingress", func() {
@@ -200,7 +385,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 							Build())
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for pass rule with Port", func() {
@@ -258,7 +443,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 							Build())
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for pass rule with IP and port", func() {
@@ -303,7 +488,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 					}
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for pass rule with no IP and Port", func() {
@@ -342,7 +527,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 					WithAction(types.NewGenericActionBuiler().WithPass().Build()).
 					Build())
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for drop rule with IP", func() {
@@ -380,7 +565,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 							Build())
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for drop rule with Port", func() {
@@ -438,7 +623,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 							Build())
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for drop rule with IP and port", func() {
@@ -483,7 +668,7 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 					}
 				}
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 
 			It("generates tc objects for drop rule with no IP and Port", func() {
@@ -522,7 +707,46 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 					WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
 					Build())
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
+			})
+
+			It("generates tc objects matching double tagged traffic", func() {
+				rules := []policyrules.Rule{{
+					IPCidrs: []*net.IPNet{ips[0]},
+					Ports:   []policyrules.Port{ports[0]},
+					Action:  policyrules.PolicyActionPass,
+				}}
+				rs.Rules = rules
+
+				tcObj, err := generatorInst.GenerateFromPolicyRuleSet(rs)
+				ensureCallAndQdisc(tcObj, err)
+				for i := range tcObj.Filters {
+					actualFilters.Add(tcObj.Filters[i])
+				}
+
+				Expect(actualFilters.Has(
+					types.NewFlowerFilterBuilder().
+						WithPriority(generator.PrioFromBaseAndProtcol(generator.BasePrioPass,
+							types.FilterProtocol8021AD)).
+						WithProtocol(types.FilterProtocol8021AD).
+						WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+						WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv4).
+						WithMatchKeyDstIP(ips[0]).
+						WithMatchKeyIPProto(types.PortProtocolToFlowerIPProto(ports[0].Protocol)).
+						WithMatchKeyDstPort(ports[0].Number).
+						WithAction(types.NewGenericActionBuiler().WithPass().Build()).
+						Build())).To(BeTrue())
+				for _, ethType := range []types.FlowerVlanEthType{types.FlowerVlanEthTypeIPv4, types.FlowerVlanEthTypeIPv6} {
+					Expect(actualFilters.Has(
+						types.NewFlowerFilterBuilder().
+							WithPriority(generator.PrioFromBaseAndProtcol(generator.BasePrioDefault,
+								types.FilterProtocol8021AD)).
+							WithProtocol(types.FilterProtocol8021AD).
+							WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+							WithMatchKeyCVlanEthType(ethType).
+							WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
+							Build())).To(BeTrue())
+				}
 			})
 
 			It("generates tc objects for multiple rules", func() {
@@ -590,8 +814,1724 @@ var _ = Describe("SimpleTCGenerator tests", func() {
 						WithAction(types.NewGenericActionBuiler().WithDrop().Build()).
 						Build())
 
-				filtersEqual(actualFilters, expectedFilters)
+				filtersEqual(actualFilters, withDoubleTaggedFilters(expectedFilters))
 			})
 		})
 	})
 })
+
+var _ = Describe("SimpleTCGenerator control traffic tests", func() {
+	controlFilters := func(proto types.FilterProtocol, with func(fb *types.FlowerFilterBuilder)) []types.Filter {
+		return protoFilters(proto, generator.BasePrioControl, passAction, with)
+	}
+
+	icmpv6Filters := func(icmpTypes ...uint8) []types.Filter {
+		filters := make([]types.Filter, 0)
+		for _, icmpType := range icmpTypes {
+			filters = append(filters, controlFilters(types.FilterProtocolIPv6, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyIPProto(types.FlowerIPProtoICMPv6).WithMatchKeyICMPType(icmpType)
+			})...)
+		}
+		return filters
+	}
+
+	ndFilters := func() []types.Filter {
+		return icmpv6Filters(133, 134, 135, 136)
+	}
+
+	mldFilters := func() []types.Filter {
+		return icmpv6Filters(130, 131, 132, 143)
+	}
+
+	igmpFilters := func() []types.Filter {
+		return controlFilters(types.FilterProtocolIPv4, func(fb *types.FlowerFilterBuilder) {
+			fb.WithMatchKeyIPProto(types.FlowerIPProtoIGMP)
+		})
+	}
+
+	broadcastFilters := func() []types.Filter {
+		broadcastMAC := net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+		return append(
+			controlFilters(types.FilterProtocolARP, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyDstMAC(broadcastMAC, nil)
+			}),
+			controlFilters(types.FilterProtocolIPv4, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyDstMAC(broadcastMAC, nil).WithMatchKeyDstIP(ipnetFromStr("255.255.255.255/32"))
+			})...)
+	}
+
+	dhcpFilters := func() []types.Filter {
+		return append(
+			controlFilters(types.FilterProtocolIPv4, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyIPProto(types.FlowerIPProtoUDP).WithMatchKeyDstPort(67)
+			}),
+			controlFilters(types.FilterProtocolIPv6, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyIPProto(types.FlowerIPProtoUDP).WithMatchKeyDstPort(547)
+			})...)
+	}
+
+	arpFilters := func() []types.Filter {
+		return controlFilters(types.FilterProtocolARP, nil)
+	}
+
+	genControlObjects := func(controlTraffic []generator.ControlTrafficType,
+		rules []policyrules.Rule) *generator.Objects {
+		return genObjects(generator.Options{ControlTraffic: controlTraffic}, egressRuleSet(rules))
+	}
+
+	It("generates no control traffic filters if PolicyRuleSet with nil rules", func() {
+		tcObj := genControlObjects([]generator.ControlTrafficType{generator.ControlTrafficND}, nil)
+		Expect(tcObj.Filters).To(BeEmpty())
+	})
+
+	DescribeTable("generates control traffic filters on isolated interface",
+		func(controlTraffic []generator.ControlTrafficType, expected func() []types.Filter) {
+			tcObj := genControlObjects(controlTraffic, make([]policyrules.Rule, 0))
+
+			// default drop filters are always generated, filter them out
+			filtersEqual(filtersBelowPrio(tcObj.Filters, generator.BasePrioPass),
+				withDoubleTaggedFilters(filterSetFromFilters(expected())))
+		},
+		Entry("ND", []generator.ControlTrafficType{generator.ControlTrafficND}, ndFilters),
+		Entry("DHCP", []generator.ControlTrafficType{generator.ControlTrafficDHCP}, dhcpFilters),
+		Entry("ARP", []generator.ControlTrafficType{generator.ControlTrafficARP}, arpFilters),
+		Entry("IGMP", []generator.ControlTrafficType{generator.ControlTrafficIGMP}, igmpFilters),
+		Entry("MLD", []generator.ControlTrafficType{generator.ControlTrafficMLD}, mldFilters),
+		Entry("broadcast", []generator.ControlTrafficType{generator.ControlTrafficBroadcast}, broadcastFilters),
+		Entry("all, with duplicates",
+			[]generator.ControlTrafficType{
+				generator.ControlTrafficND, generator.ControlTrafficDHCP,
+				generator.ControlTrafficARP, generator.ControlTrafficND},
+			func() []types.Filter {
+				return append(append(ndFilters(), dhcpFilters()...), arpFilters()...)
+			}),
+	)
+
+	It("does not pass broadcast MAC traffic to unicast IP addresses", func() {
+		unicastIP := net.ParseIP("10.10.10.1")
+		tcObj := genControlObjects([]generator.ControlTrafficType{generator.ControlTrafficBroadcast},
+			make([]policyrules.Rule, 0))
+
+		ipDropped := false
+		for _, f := range tcObj.Filters {
+			flowerFilter := f.(*types.FlowerFilter)
+			if flowerFilter.Protocol != types.FilterProtocolIPv4 && flowerFilter.Protocol != types.FilterProtocolAll {
+				continue
+			}
+			if *f.Attrs().Priority >= uint16(generator.BasePrioPass) {
+				// default filters
+				Expect(flowerFilter.Actions).To(ConsistOf(dropAction))
+				ipDropped = true
+				continue
+			}
+			Expect(flowerFilter.Flower.DstIP).ToNot(BeNil())
+			Expect(flowerFilter.Flower.DstIP.Contains(unicastIP)).To(BeFalse())
+		}
+		Expect(ipDropped).To(BeTrue())
+	})
+
+	DescribeTable("ControlTrafficTypeFromString",
+		func(s string, expected generator.ControlTrafficType, shouldFail bool) {
+			ct, err := generator.ControlTrafficTypeFromString(s)
+			if shouldFail {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ct).To(Equal(expected))
+		},
+		Entry("nd", "nd", generator.ControlTrafficND, false),
+		Entry("DHCP", "DHCP", generator.ControlTrafficDHCP, false),
+		Entry("arp", " arp ", generator.ControlTrafficARP, false),
+		Entry("igmp", "igmp", generator.ControlTrafficIGMP, false),
+		Entry("mld", "MLD", generator.ControlTrafficMLD, false),
+		Entry("broadcast", "broadcast", generator.ControlTrafficBroadcast, false),
+		Entry("unknown", "stp", generator.ControlTrafficType(""), true),
+	)
+})
+
+var _ = Describe("SimpleTCGenerator strict mode tests", func() {
+	strictDefaultFilter := protoFilter(types.FilterProtocolAll, generator.BasePrioDefault, false).
+		WithAction(dropAction).
+		Build()
+
+	ruleSetWithConfig := func(conf policyrules.NetworkConfig) policyrules.PolicyRuleSet {
+		rs := egressRuleSet(make([]policyrules.Rule, 0))
+		rs.IfcInfo.NetworkConfig = conf
+		return rs
+	}
+
+	It("generates drop all filter if strict mode is enabled in options", func() {
+		tcObj := genObjects(generator.Options{StrictMode: true}, ruleSetWithConfig(policyrules.NetworkConfig{}))
+		Expect(tcObj.Filters).To(HaveLen(1))
+		Expect(tcObj.Filters[0].Equals(strictDefaultFilter)).To(BeTrue())
+	})
+
+	It("generates drop all filter if strict mode is enabled for network", func() {
+		tcObj := genObjects(generator.Options{}, ruleSetWithConfig(policyrules.NetworkConfig{StrictMode: true}))
+		Expect(tcObj.Filters).To(HaveLen(1))
+		Expect(tcObj.Filters[0].Equals(strictDefaultFilter)).To(BeTrue())
+	})
+
+	It("generates no filters in strict mode if PolicyRuleSet with nil rules", func() {
+		tcObj := genObjects(generator.Options{StrictMode: true}, egressRuleSet(nil))
+		Expect(tcObj.Filters).To(BeEmpty())
+	})
+
+	It("generates control traffic filters in strict mode", func() {
+		tcObj := genObjects(generator.Options{
+			StrictMode:     true,
+			ControlTraffic: []generator.ControlTrafficType{generator.ControlTrafficARP},
+		}, ruleSetWithConfig(policyrules.NetworkConfig{}))
+
+		expectedFilters := filterSetFromFilters(append(
+			protoFilters(types.FilterProtocolARP, generator.BasePrioControl, passAction, nil), strictDefaultFilter))
+		filtersEqual(filterSetFromFilters(tcObj.Filters), withDoubleTaggedFilters(expectedFilters))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator anti-spoofing tests", func() {
+	gotoPolicy := gotoChainAction(generator.PolicyChain)
+	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+
+	ruleSetWithIfc := func(ips []net.IP, mac net.HardwareAddr, rules []policyrules.Rule) policyrules.PolicyRuleSet {
+		rs := egressRuleSet(rules)
+		rs.IfcInfo = policyrules.InterfaceInfo{
+			IPs:           ips,
+			MAC:           mac,
+			NetworkConfig: policyrules.NetworkConfig{AntiSpoofing: true},
+		}
+		return rs
+	}
+
+	// ipSpoofFilters returns the expected anti-spoofing filters for an interface with IPv4 10.10.10.2
+	ipSpoofFilters := func(mac net.HardwareAddr) []types.Filter {
+		spoofPass := func(proto types.FilterProtocol, with func(fb *types.FlowerFilterBuilder)) []types.Filter {
+			return protoFilters(proto, generator.BasePrioSpoofPass, gotoPolicy, func(fb *types.FlowerFilterBuilder) {
+				if mac != nil {
+					fb.WithMatchKeySrcMAC(mac)
+				}
+				with(fb)
+			})
+		}
+		filters := make([]types.Filter, 0)
+		for _, fs := range [][]types.Filter{
+			spoofPass(types.FilterProtocolIPv4, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeySrcIP(ipnetFromStr("10.10.10.2/32"))
+			}),
+			spoofPass(types.FilterProtocolARP, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyArpSIP(ipnetFromStr("10.10.10.2/32"))
+			}),
+			spoofPass(types.FilterProtocolIPv4, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeySrcIP(ipnetFromStr("0.0.0.0/32")).
+					WithMatchKeyIPProto(types.FlowerIPProtoUDP).
+					WithMatchKeyDstPort(67)
+			}),
+			spoofPass(types.FilterProtocolARP, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyArpSIP(ipnetFromStr("0.0.0.0/32"))
+			}),
+			spoofPass(types.FilterProtocolIPv6, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeySrcIP(ipnetFromStr("::/128"))
+			}),
+			spoofPass(types.FilterProtocolIPv6, func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeySrcIP(ipnetFromStr("fe80::/10"))
+			}),
+			protoFilters(types.FilterProtocolIPv4, generator.BasePrioSpoofDrop, dropAction, nil),
+			protoFilters(types.FilterProtocolIPv6, generator.BasePrioSpoofDrop, dropAction, nil),
+			protoFilters(types.FilterProtocolARP, generator.BasePrioSpoofDrop, dropAction, nil),
+		} {
+			filters = append(filters, fs...)
+		}
+		return filters
+	}
+
+	macPassFilter := func(mac net.HardwareAddr) types.Filter {
+		fb := protoFilter(types.FilterProtocolAll, generator.BasePrioSpoofMACPass, false).WithAction(gotoPolicy)
+		if mac != nil {
+			fb.WithMatchKeySrcMAC(mac)
+		}
+		return fb.Build()
+	}
+
+	macDropFilter := protoFilter(types.FilterProtocolAll, generator.BasePrioSpoofMACDrop, false).
+		WithAction(dropAction).
+		Build()
+
+	policyChainPassFilter := protoFilter(types.FilterProtocolAll, generator.BasePrioDefault, false).
+		WithChain(generator.PolicyChain).
+		WithAction(passAction).
+		Build()
+
+	It("generates anti-spoofing filters and pass all in policy chain if PolicyRuleSet with nil rules", func() {
+		tcObj := genObjects(generator.Options{}, ruleSetWithIfc([]net.IP{net.ParseIP("10.10.10.2")}, mac, nil))
+
+		expectedFilters := filterSetFromFilters(
+			append(ipSpoofFilters(mac), macPassFilter(mac), macDropFilter, policyChainPassFilter))
+		filtersEqual(filterSetFromFilters(tcObj.Filters), withDoubleTaggedFilters(expectedFilters))
+	})
+
+	It("generates anti-spoofing filters without source MAC if interface MAC is unknown", func() {
+		tcObj := genObjects(generator.Options{}, ruleSetWithIfc([]net.IP{net.ParseIP("10.10.10.2")}, nil, nil))
+
+		expectedFilters := filterSetFromFilters(
+			append(ipSpoofFilters(nil), macPassFilter(nil), policyChainPassFilter))
+		filtersEqual(filterSetFromFilters(tcObj.Filters), withDoubleTaggedFilters(expectedFilters))
+	})
+
+	It("generates only source MAC anti-spoofing filters if interface has no IPs", func() {
+		tcObj := genObjects(generator.Options{}, ruleSetWithIfc(nil, mac, nil))
+
+		expectedFilters := filterSetFromFilters(
+			[]types.Filter{macPassFilter(mac), macDropFilter, policyChainPassFilter})
+		filtersEqual(filterSetFromFilters(tcObj.Filters), expectedFilters)
+	})
+
+	It("generates source IPv6 anti-spoofing filters for IPv6 interface IPs", func() {
+		tcObj := genObjects(generator.Options{}, ruleSetWithIfc([]net.IP{net.ParseIP("2001::2")}, mac, nil))
+
+		expected := protoFilter(types.FilterProtocolIPv6, generator.BasePrioSpoofPass, false).
+			WithMatchKeySrcMAC(mac).
+			WithMatchKeySrcIP(ipnetFromStr("2001::2/128")).
+			WithAction(gotoPolicy).
+			Build()
+		Expect(filterSetFromFilters(tcObj.Filters).Has(expected)).To(BeTrue())
+	})
+
+	It("generates policy filters in policy chain", func() {
+		rs := ruleSetWithIfc([]net.IP{net.ParseIP("10.10.10.2")}, mac, make([]policyrules.Rule, 0))
+		tcObj := genObjects(generator.Options{}, rs)
+
+		rs.IfcInfo.NetworkConfig.AntiSpoofing = false
+		policyObj := genObjects(generator.Options{}, rs)
+
+		expectedFilters := tc.NewFilterSetImpl()
+		for _, f := range policyObj.Filters {
+			Expect(f.Attrs().Chain).To(BeNil())
+			chain := generator.PolicyChain
+			f.Attrs().Chain = &chain
+			expectedFilters.Add(f)
+		}
+		for _, f := range append(ipSpoofFilters(mac), macPassFilter(mac), macDropFilter) {
+			expectedFilters.Add(f)
+		}
+		filtersEqual(filterSetFromFilters(tcObj.Filters), withDoubleTaggedFilters(expectedFilters))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator stateful tests", func() {
+	ctZone := generator.ConnTrackZone(policyrules.InterfaceInfo{})
+	ctAction := types.NewConnTrackActionBuilder().WithZone(ctZone).Build()
+	ctCommitAction := types.NewConnTrackActionBuilder().WithCommit().WithZone(ctZone).Build()
+	gotoConnTrack := gotoChainAction(generator.ConnTrackChain)
+	statefulOpts := generator.Options{Stateful: true}
+
+	// connTrackFilters returns the expected connection tracking filters with entry filters in entryChain
+	connTrackFilters := func(entryChain uint32) []types.Filter {
+		filters := make([]types.Filter, 0)
+		for _, proto := range ipProtos {
+			filters = append(filters, protoFilters(proto, generator.BasePrioConnTrack, gotoConnTrack,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithChain(entryChain).WithAction(ctAction)
+				})...)
+			filters = append(filters, protoFilters(proto, generator.BasePrioEstablished, passAction,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithChain(generator.ConnTrackChain).WithMatchKeyCtState(types.FlowerCtStateEstablished)
+				})...)
+			filters = append(filters, protoFilters(proto, generator.BasePrioDefault, dropAction,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithChain(generator.ConnTrackChain)
+				})...)
+		}
+		return append(filters, protoFilter(types.FilterProtocolAll, generator.BasePrioConnTrack, false).
+			WithChain(entryChain).
+			WithAction(gotoConnTrack).
+			Build())
+	}
+
+	It("generates no filters if PolicyRuleSet with nil rules", func() {
+		tcObj := genObjects(statefulOpts, egressRuleSet(nil))
+		Expect(tcObj.Filters).To(BeEmpty())
+	})
+
+	It("generates connection tracking filters if PolicyRuleSet with zero rules", func() {
+		tcObj := genObjects(statefulOpts, egressRuleSet(make([]policyrules.Rule, 0)))
+		filtersEqual(filterSetFromFilters(tcObj.Filters),
+			withDoubleTaggedFilters(filterSetFromFilters(connTrackFilters(types.ChainDefaultChain))))
+	})
+
+	It("generates pass filters which commit the connection", func() {
+		tcObj := genObjects(statefulOpts, egressRuleSet([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.10.10.0/24")}))
+
+		expectedFilters := append(connTrackFilters(types.ChainDefaultChain),
+			protoFilters(types.FilterProtocolIPv4, generator.BasePrioPass, passAction,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithChain(generator.ConnTrackChain).
+						WithMatchKeyDstIP(ipnetFromStr("10.10.10.0/24")).
+						WithAction(ctCommitAction)
+				})...)
+		filtersEqual(filterSetFromFilters(tcObj.Filters),
+			withDoubleTaggedFilters(filterSetFromFilters(expectedFilters)))
+	})
+
+	It("generates connection tracking filters in policy chain if anti-spoofing is enabled", func() {
+		rs := egressRuleSet(make([]policyrules.Rule, 0))
+		rs.IfcInfo.NetworkConfig.AntiSpoofing = true
+		tcObj := genObjects(statefulOpts, rs)
+
+		actualFilters := tc.NewFilterSetImpl()
+		for _, f := range tcObj.Filters {
+			if chainOf(f) != types.ChainDefaultChain {
+				actualFilters.Add(f)
+			}
+		}
+		filtersEqual(actualFilters,
+			withDoubleTaggedFilters(filterSetFromFilters(connTrackFilters(generator.PolicyChain))))
+	})
+
+	It("generates connection tracking filters in a dedicated zone per interface", func() {
+		ifcZone := func(ifcName string) []string {
+			rs := egressRuleSet([]policyrules.Rule{ipRule(policyrules.PolicyActionPass, "10.10.10.0/24")})
+			rs.IfcInfo = policyrules.InterfaceInfo{Network: "default/net1", InterfaceName: ifcName, DeviceID: "0000:03:00.2"}
+			tcObj := genObjects(statefulOpts, rs)
+
+			zones := make([]string, 0)
+			for _, f := range tcObj.Filters {
+				for _, a := range f.(*types.FlowerFilter).Actions {
+					if a.Type() == types.ActionTypeConnTrack {
+						zones = append(zones, a.Spec()["zone"])
+					}
+				}
+			}
+			Expect(zones).ToNot(BeEmpty())
+			Expect(zones).To(HaveEach(fmt.Sprint(generator.ConnTrackZone(rs.IfcInfo))))
+			return zones
+		}
+
+		Expect(ifcZone("net1")[0]).ToNot(Equal("0"))
+		Expect(ifcZone("net1")[0]).ToNot(Equal(ifcZone("net2")[0]))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator clsact tests", func() {
+	It("generates clsact qdisc with filters on its ingress hook", func() {
+		ruleSet := egressRuleSet(make([]policyrules.Rule, 0))
+		ingressObj := genObjects(generator.Options{}, ruleSet)
+
+		tcObj, err := generator.NewSimpleTCGenerator(generator.Options{Clsact: true}).GenerateFromPolicyRuleSet(ruleSet)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tcObj.QDisc.Type()).To(Equal(types.QDiscClsactType))
+		Expect(tcObj.Filters).ToNot(BeEmpty())
+		for _, f := range tcObj.Filters {
+			Expect(types.HookOf(f.Attrs().Parent)).To(Equal(types.ParentIngress))
+		}
+		filtersEqual(filterSetFromFilters(tcObj.Filters), filterSetFromFilters(ingressObj.Filters))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator ip fragments tests", func() {
+	ports := []policyrules.Port{tcpPort(6666)}
+	portsRule := withPorts(ipRule(policyrules.PolicyActionPass), ports...)
+
+	// fragmentFilters returns the filters in tcObj which match on ip flags
+	fragmentFilters := func(tcObj *generator.Objects) tc.FilterSet {
+		fs := tc.NewFilterSetImpl()
+		for _, f := range tcObj.Filters {
+			flowerFilter, ok := f.(*types.FlowerFilter)
+			ExpectWithOffset(1, ok).To(BeTrue())
+			if flowerFilter.Flower.IPFlags != nil {
+				fs.Add(f)
+			}
+		}
+		return fs
+	}
+
+	// expectedFragmentFilters returns the untagged and 802.1Q tagged filters of each of protos which match on
+	// ipFlags, and on ipCidr if not nil
+	expectedFragmentFilters := func(protos []types.FilterProtocol, basePrio generator.BasePrio, action types.Action,
+		ipCidr *net.IPNet, ipFlags types.FlowerIPFlags) tc.FilterSet {
+		filters := make([]types.Filter, 0)
+		for _, proto := range protos {
+			filters = append(filters, protoFilters(proto, basePrio, action, func(fb *types.FlowerFilterBuilder) {
+				if ipCidr != nil {
+					fb.WithMatchKeyDstIP(ipCidr)
+				}
+				fb.WithMatchKeyIPFlags(ipFlags)
+			})...)
+		}
+		return withDoubleTaggedFilters(filterSetFromFilters(filters))
+	}
+
+	It("generates no ip fragments filters if policy is not set", func() {
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{portsRule}))
+		Expect(fragmentFilters(tcObj).List()).To(BeEmpty())
+	})
+
+	It("generates ip fragments drop filters", func() {
+		tcObj := genObjects(generator.Options{Fragments: generator.FragmentsPolicyDrop},
+			egressRuleSet([]policyrules.Rule{portsRule}))
+
+		filtersEqual(fragmentFilters(tcObj), expectedFragmentFilters(ipProtos, generator.BasePrioFragments,
+			dropAction, nil, types.FlowerIPFlagsNoFirstFrag))
+	})
+
+	It("generates ip fragments drop filters which pass first fragments to allowed ports", func() {
+		tcObj := genObjects(generator.Options{Fragments: generator.FragmentsPolicyDrop},
+			egressRuleSet([]policyrules.Rule{
+				withPorts(ipRule(policyrules.PolicyActionPass, "10.100.1.0/24"), ports...),
+				ipRule(policyrules.PolicyActionPass, "10.100.2.0/24")}))
+
+		// a first fragment to 10.100.1.1 tcp port 6666 is matched by the first matching filter in priority order
+		var matching []*types.FlowerFilter
+		for _, f := range tcObj.Filters {
+			flowerFilter := f.(*types.FlowerFilter)
+			spec := flowerFilter.Flower
+			if flowerFilter.Protocol != types.FilterProtocolIPv4 ||
+				(spec.DstIP != nil && !spec.DstIP.Contains(net.ParseIP("10.100.1.1"))) ||
+				(spec.DstPort != nil && *spec.DstPort != 6666) ||
+				(spec.IPFlags != nil && *spec.IPFlags != types.FlowerIPFlagsFrag &&
+					*spec.IPFlags != types.FlowerIPFlagsFirstFrag) {
+				continue
+			}
+			matching = append(matching, flowerFilter)
+		}
+		sort.Slice(matching, func(i, j int) bool { return *matching[i].Priority < *matching[j].Priority })
+		Expect(matching).ToNot(BeEmpty())
+		Expect(matching[0].Actions).To(ConsistOf(passAction))
+		Expect(*matching[0].Flower.DstPort).To(Equal(uint16(6666)))
+	})
+
+	It("generates ip fragments allow filters for pass rules with ports", func() {
+		tcObj := genObjects(generator.Options{Fragments: generator.FragmentsPolicyAllow},
+			egressRuleSet([]policyrules.Rule{
+				withPorts(ipRule(policyrules.PolicyActionPass, "10.100.1.1/24", "2001::1/128"), ports...),
+				// duplicate destination
+				withPorts(ipRule(policyrules.PolicyActionPass, "10.100.1.1/24"), ports...),
+				// no ports
+				ipRule(policyrules.PolicyActionPass, "192.168.1.0/24"),
+			}))
+
+		expectedFilters := expectedFragmentFilters([]types.FilterProtocol{types.FilterProtocolIPv4},
+			generator.BasePrioPass, passAction, ipnetFromStr("10.100.1.1/24"), types.FlowerIPFlagsNoFirstFrag)
+		for _, f := range expectedFragmentFilters([]types.FilterProtocol{types.FilterProtocolIPv6},
+			generator.BasePrioPass, passAction, ipnetFromStr("2001::1/128"), types.FlowerIPFlagsNoFirstFrag).List() {
+			expectedFilters.Add(f)
+		}
+		filtersEqual(fragmentFilters(tcObj), expectedFilters)
+	})
+
+	It("generates ip fragments allow filters for pass rules with ports and no IPs", func() {
+		tcObj := genObjects(generator.Options{Fragments: generator.FragmentsPolicyAllow},
+			egressRuleSet([]policyrules.Rule{portsRule}))
+
+		filtersEqual(fragmentFilters(tcObj), expectedFragmentFilters(ipProtos, generator.BasePrioPass,
+			passAction, nil, types.FlowerIPFlagsNoFirstFrag))
+	})
+
+	DescribeTable("FragmentsPolicyFromString",
+		func(s string, expected generator.FragmentsPolicy, shouldFail bool) {
+			fp, err := generator.FragmentsPolicyFromString(s)
+			if shouldFail {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fp).To(Equal(expected))
+		},
+		Entry("empty", "", generator.FragmentsPolicy(""), false),
+		Entry("allow", "allow", generator.FragmentsPolicyAllow, false),
+		Entry("drop", " DROP ", generator.FragmentsPolicyDrop, false),
+		Entry("unknown", "reassemble", generator.FragmentsPolicy(""), true),
+	)
+})
+
+var _ = Describe("SimpleTCGenerator multicast tests", func() {
+	multicastMAC := net.HardwareAddr{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+	broadcastMAC := net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	ipCidrs := []string{
+		"239.1.1.1/32",
+		"224.0.0.0/24",
+		"255.255.255.255/32",
+		"ff3e::/16",
+		// not contained in multicast networks
+		"192.168.1.0/24",
+		"10.0.0.0/8",
+		"2001::/64",
+	}
+	dstMACs := []*types.FlowerMAC{
+		{Addr: multicastMAC, Mask: multicastMAC},
+		{Addr: multicastMAC, Mask: multicastMAC},
+		{Addr: broadcastMAC},
+		{Addr: multicastMAC, Mask: multicastMAC},
+		nil,
+		nil,
+		nil,
+	}
+
+	genPassFilters := func(multicastMAC bool) tc.FilterSet {
+		tcObj := genObjects(generator.Options{MulticastMAC: multicastMAC},
+			egressRuleSet([]policyrules.Rule{ipRule(policyrules.PolicyActionPass, ipCidrs...)}))
+		return filtersBelowPrio(tcObj.Filters, generator.BasePrioDefault)
+	}
+
+	expectedPassFilters := func(withDstMAC bool) tc.FilterSet {
+		filters := make([]types.Filter, 0)
+		for i, ipCidr := range ipCidrs {
+			ip := ipnetFromStr(ipCidr)
+			dstMAC := dstMACs[i]
+			filters = append(filters, protoFilters(ipToProto(ip.IP), generator.BasePrioPass, passAction,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithMatchKeyDstIP(ip)
+					if withDstMAC && dstMAC != nil {
+						fb.WithMatchKeyDstMAC(dstMAC.Addr, dstMAC.Mask)
+					}
+				})...)
+		}
+		return withDoubleTaggedFilters(filterSetFromFilters(filters))
+	}
+
+	It("matches destination IP only if MulticastMAC is not set", func() {
+		filtersEqual(genPassFilters(false), expectedPassFilters(false))
+	})
+
+	It("matches multicast and broadcast destination MAC if MulticastMAC is set", func() {
+		filtersEqual(genPassFilters(true), expectedPassFilters(true))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator vlan tests", func() {
+	ipCidr := "10.100.1.0/24"
+
+	genFilters := func(vlanIDs []uint16) []types.Filter {
+		rs := egressRuleSet([]policyrules.Rule{ipRule(policyrules.PolicyActionPass, ipCidr)})
+		rs.IfcInfo.NetworkConfig.VlanIDs = vlanIDs
+		return genObjects(generator.Options{}, rs).Filters
+	}
+
+	passFilter := func(tagged bool) *types.FlowerFilterBuilder {
+		return protoFilter(types.FilterProtocolIPv4, generator.BasePrioPass, tagged).
+			WithMatchKeyDstIP(ipnetFromStr(ipCidr)).
+			WithAction(passAction)
+	}
+
+	It("matches any vlan if network has no vlan IDs", func() {
+		for _, f := range genFilters(nil) {
+			Expect(f.(*types.FlowerFilter).Flower.VlanID).To(BeNil())
+		}
+	})
+
+	It("matches network vlan IDs in tagged pass filters", func() {
+		filters := genFilters([]uint16{100, 200})
+		for _, f := range filters {
+			if *f.Attrs().Priority >= uint16(generator.BasePrioDefault) {
+				// default drop filters match any vlan
+				Expect(f.(*types.FlowerFilter).Flower.VlanID).To(BeNil())
+				Expect(f.(*types.FlowerFilter).Actions).To(ConsistOf(dropAction))
+			}
+		}
+
+		expectedFilters := tc.NewFilterSetImpl()
+		expectedFilters.Add(passFilter(false).Build())
+		for _, vlanID := range []uint16{100, 200} {
+			expectedFilters.Add(passFilter(true).WithMatchKeyVlanID(vlanID).Build())
+		}
+		filtersEqual(filtersBelowPrio(filters, generator.BasePrioDefault), withDoubleTaggedFilters(expectedFilters))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator vlan mode tests", func() {
+	ipCidr := "10.100.1.0/24"
+
+	genFilters := func(opts generator.Options, vlanMode controllers.VlanMode, strict bool) []types.Filter {
+		rs := egressRuleSet([]policyrules.Rule{tcpRule(policyrules.PolicyActionPass, ipCidr, 80)})
+		rs.IfcInfo.NetworkConfig = policyrules.NetworkConfig{VlanMode: string(vlanMode), StrictMode: strict}
+		return genObjects(opts, rs).Filters
+	}
+
+	passFilter := protoFilter(types.FilterProtocolIPv4, generator.BasePrioPass, false).
+		WithMatchKeyDstIP(ipnetFromStr(ipCidr)).
+		WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+		WithMatchKeyDstPort(80).
+		WithAction(passAction).
+		Build()
+
+	defaultFilter := func(proto types.FilterProtocol) types.Filter {
+		return protoFilter(proto, generator.BasePrioDefault, false).WithAction(dropAction).Build()
+	}
+
+	untaggedFilters := func() tc.FilterSet {
+		return filterSetFromFilters([]types.Filter{
+			passFilter,
+			defaultFilter(types.FilterProtocolIPv4),
+			defaultFilter(types.FilterProtocolIPv6),
+			defaultFilter(types.FilterProtocol8021Q),
+			defaultFilter(types.FilterProtocol8021AD),
+		})
+	}
+
+	It("generates untagged filters and drops all tagged traffic on untagged network", func() {
+		filtersEqual(filterSetFromFilters(genFilters(generator.Options{}, controllers.VlanModeUntagged, false)),
+			untaggedFilters())
+	})
+
+	It("uses VLAN mode in Options if network has no VLAN mode", func() {
+		opts := generator.Options{VlanMode: controllers.VlanModeUntagged}
+		filtersEqual(filterSetFromFilters(genFilters(opts, "", false)), untaggedFilters())
+		Expect(filterSetFromFilters(genFilters(opts, controllers.VlanModeTagged, false)).Equals(untaggedFilters())).
+			To(BeFalse())
+	})
+
+	It("generates a single drop filter on untagged network in strict mode", func() {
+		filtersEqual(filterSetFromFilters(genFilters(generator.Options{}, controllers.VlanModeUntagged, true)),
+			filterSetFromFilters([]types.Filter{passFilter, defaultFilter(types.FilterProtocolAll)}))
+	})
+
+	It("generates no tagged filters of specific protocol on untagged network", func() {
+		opts := generator.Options{
+			ControlTraffic: []generator.ControlTrafficType{generator.ControlTrafficARP, generator.ControlTrafficDHCP},
+			Stateful:       true,
+			Fragments:      generator.FragmentsPolicyAllow,
+		}
+		for _, f := range genFilters(opts, controllers.VlanModeUntagged, false) {
+			flowerFilter := f.(*types.FlowerFilter)
+			if flowerFilter.Protocol == types.FilterProtocol8021Q || flowerFilter.Protocol == types.FilterProtocol8021AD {
+				Expect(flowerFilter.Flower.VlanEthType).To(BeNil())
+				Expect(flowerFilter.Actions).To(ConsistOf(dropAction))
+			}
+		}
+	})
+})
+
+var _ = Describe("SimpleTCGenerator mac peers tests", func() {
+	macs := []net.HardwareAddr{{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x01}, {0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x02}}
+
+	genPassFilters := func(rule policyrules.Rule) tc.FilterSet {
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{rule}))
+		return filtersBelowPrio(tcObj.Filters, generator.BasePrioDefault)
+	}
+
+	It("generates filters matching all traffic to peer MACs for rule without ports", func() {
+		expectedFilters := tc.NewFilterSetImpl()
+		for _, mac := range macs {
+			expectedFilters.Add(protoFilter(types.FilterProtocolAll, generator.BasePrioPass, false).
+				WithMatchKeyDstMAC(mac, nil).
+				WithAction(passAction).
+				Build())
+		}
+
+		filtersEqual(genPassFilters(policyrules.Rule{MACs: macs, Action: policyrules.PolicyActionPass}),
+			expectedFilters)
+	})
+
+	It("generates filters matching ip traffic to peer MACs on ports for rule with ports", func() {
+		port := policyrules.Port{Protocol: policyrules.ProtocolUDP, Number: 5000}
+		expectedFilters := make([]types.Filter, 0)
+		for _, mac := range macs {
+			for _, proto := range ipProtos {
+				expectedFilters = append(expectedFilters, protoFilters(proto, generator.BasePrioPass, passAction,
+					func(fb *types.FlowerFilterBuilder) {
+						fb.WithMatchKeyDstMAC(mac, nil).
+							WithMatchKeyIPProto(types.FlowerIPProtoUDP).
+							WithMatchKeyDstPort(port.Number)
+					})...)
+			}
+		}
+
+		filtersEqual(genPassFilters(policyrules.Rule{
+			MACs: macs, Ports: []policyrules.Port{port}, Action: policyrules.PolicyActionPass}),
+			withDoubleTaggedFilters(filterSetFromFilters(expectedFilters)))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator dscp tests", func() {
+	dscp := uint8(46)
+
+	dscpRule := func(ipCidr string) policyrules.Rule {
+		r := ipRule(policyrules.PolicyActionPass, ipCidr)
+		r.DSCP = &dscp
+		return r
+	}
+
+	It("generates dscp marking actions for pass rule with DSCP", func() {
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{
+			dscpRule("10.100.1.0/24"), dscpRule("2001::/64")}))
+
+		csum := types.NewCsumAction(types.CsumUpdateIPv4Header)
+		markedFilters := 0
+		for _, f := range tcObj.Filters {
+			flowerFilter := f.(*types.FlowerFilter)
+			if *f.Attrs().Priority >= uint16(generator.BasePrioDefault) {
+				Expect(flowerFilter.Actions).To(HaveLen(1))
+				continue
+			}
+			switch {
+			case flowerFilter.Protocol == types.FilterProtocol8021AD:
+				// double tagged traffic is not marked
+				Expect(flowerFilter.Actions).To(Equal([]types.Action{passAction}))
+			case flowerFilter.Flower.DstIP.String() == "10.100.1.0/24":
+				Expect(flowerFilter.Actions).To(Equal([]types.Action{
+					types.NewPeditDSCPAction(types.PeditHeaderIPv4, dscp), csum, passAction}))
+				markedFilters++
+			default:
+				Expect(flowerFilter.Actions).To(Equal([]types.Action{
+					types.NewPeditDSCPAction(types.PeditHeaderIPv6, dscp), passAction}))
+				markedFilters++
+			}
+		}
+		// untagged and 802.1Q tagged ipv4 and ipv6 filters
+		Expect(markedFilters).To(Equal(4))
+	})
+
+	It("generates filters of pass rule with DSCP ahead of other pass rules", func() {
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.0/16"), dscpRule("10.0.1.0/24")}))
+
+		Expect(prioOf(tcObj.Filters, "10.0.1.0/24")).To(Equal(
+			generator.PrioFromBaseAndProtcol(generator.BasePrioDSCPPass, types.FilterProtocolIPv4)))
+		Expect(prioOf(tcObj.Filters, "10.0.0.0/16")).To(Equal(
+			generator.PrioFromBaseAndProtcol(generator.BasePrioPass, types.FilterProtocolIPv4)))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator rate limit tests", func() {
+	// 10Mbit/s
+	bandwidth := uint64(10000000)
+	police := types.NewPoliceAction(bandwidth/8, 64*1024, types.PoliceControlPipe, types.PoliceControlDrop)
+	gotoRateLimit := gotoChainAction(generator.RateLimitChain)
+
+	rateLimitFilter := func(next types.Action) types.Filter {
+		return protoFilter(types.FilterProtocolAll, generator.BasePrioRateLimit, false).
+			WithAction(police).
+			WithAction(next).
+			Build()
+	}
+
+	ruleSet := func(rules []policyrules.Rule) policyrules.PolicyRuleSet {
+		rs := egressRuleSet(rules)
+		rs.IfcInfo.EgressBandwidth = bandwidth
+		return rs
+	}
+
+	It("generates rate limit filter which passes traffic if PolicyRuleSet with nil rules", func() {
+		tcObj := genObjects(generator.Options{}, ruleSet(nil))
+		Expect(tcObj.Filters).To(HaveLen(1))
+		Expect(tcObj.Filters[0].Equals(rateLimitFilter(passAction))).To(BeTrue())
+	})
+
+	It("generates rate limit filter and policy filters in rate limit chain", func() {
+		tcObj := genObjects(generator.Options{}, ruleSet(make([]policyrules.Rule, 0)))
+
+		expected := []types.Filter{rateLimitFilter(gotoRateLimit)}
+		for _, proto := range ipProtos {
+			expected = append(expected, protoFilters(proto, generator.BasePrioDefault, dropAction,
+				func(fb *types.FlowerFilterBuilder) {
+					fb.WithChain(generator.RateLimitChain)
+				})...)
+		}
+		filtersEqual(filterSetFromFilters(tcObj.Filters), withDoubleTaggedFilters(filterSetFromFilters(expected)))
+	})
+
+	It("generates anti-spoofing filters in rate limit chain", func() {
+		rs := ruleSet(nil)
+		rs.IfcInfo.NetworkConfig.AntiSpoofing = true
+		tcObj := genObjects(generator.Options{}, rs)
+
+		chains := make(map[uint32]int)
+		for _, f := range tcObj.Filters {
+			chains[chainOf(f)]++
+		}
+		// rate limit filter in chain 0, anti-spoofing filter in rate limit chain and pass filter in policy chain
+		Expect(chains).To(Equal(map[uint32]int{
+			types.ChainDefaultChain: 1, generator.RateLimitChain: 1, generator.PolicyChain: 1}))
+		Expect(tcObj.Filters[0].Equals(rateLimitFilter(gotoRateLimit))).To(BeTrue())
+	})
+
+	It("generates burst according to bandwidth", func() {
+		rs := ruleSet(nil)
+		// 100Gbit/s
+		rs.IfcInfo.EgressBandwidth = 100000000000
+		tcObj := genObjects(generator.Options{}, rs)
+		Expect(tcObj.Filters[0].(*types.FlowerFilter).Actions[0].Spec()).To(Equal(map[string]string{
+			"rate": "12500000000", "burst": "125000000", "conform": "pipe", "exceed": "drop"}))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator allowlist tests", func() {
+	It("generates pass filters for global rules before drop filters", func() {
+		globalRule := withPorts(ipRule(policyrules.PolicyActionPass, "10.0.0.1/32"),
+			policyrules.Port{Protocol: policyrules.ProtocolUDP, Number: 53})
+		globalRule.Global = true
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionDrop, "10.0.0.0/24"), globalRule}))
+
+		expected := protoFilters(types.FilterProtocolIPv4, generator.BasePrioGlobalPass, passAction,
+			func(fb *types.FlowerFilterBuilder) {
+				fb.WithMatchKeyDstIP(ipnetFromStr("10.0.0.1/32")).
+					WithMatchKeyIPProto(types.FlowerIPProtoUDP).
+					WithMatchKeyDstPort(53)
+			})
+
+		actual := tc.NewFilterSetImpl()
+		for _, f := range filtersBelowPrio(tcObj.Filters, generator.BasePrioDrop).List() {
+			if *f.Attrs().Priority >= uint16(generator.BasePrioGlobalPass) {
+				actual.Add(f)
+			}
+		}
+		filtersEqual(actual, withDoubleTaggedFilters(filterSetFromFilters(expected)))
+	})
+})
+
+var _ = Describe("ChainTCGenerator tests", func() {
+	ctCommitAction := types.NewConnTrackActionBuilder().WithCommit().
+		WithZone(generator.ConnTrackZone(policyrules.InterfaceInfo{})).Build()
+	tcp80 := tcpPort(80)
+	tcp443 := tcpPort(443)
+
+	genChainObjects := func(opts generator.Options, rules ...policyrules.Rule) *generator.Objects {
+		return genObjectsWith(generator.NewChainTCGenerator(opts), egressRuleSet(rules))
+	}
+
+	// entryFilters returns the expected goto port chain filters for ipCidr in entryChain
+	entryFilters := func(ipCidr string, entryChain, portChain uint32) tc.FilterSet {
+		ipn := ipnetFromStr(ipCidr)
+		return withDoubleTaggedFilters(filterSetFromFilters(protoFilters(ipToProto(ipn.IP),
+			generator.BasePrioPortChains, gotoChainAction(portChain), func(fb *types.FlowerFilterBuilder) {
+				fb.WithChain(entryChain).WithMatchKeyDstIP(ipn)
+			})))
+	}
+
+	// portChainFilters returns the expected filters of portChain which allows ports
+	portChainFilters := func(portChain uint32, stateful bool, ports ...policyrules.Port) tc.FilterSet {
+		filters := make([]types.Filter, 0)
+		for _, port := range ports {
+			for _, proto := range ipProtos {
+				filters = append(filters, protoFilters(proto, generator.BasePrioPass, passAction,
+					func(fb *types.FlowerFilterBuilder) {
+						fb.WithChain(portChain).
+							WithMatchKeyIPProto(types.PortProtocolToFlowerIPProto(port.Protocol)).
+							WithMatchKeyDstPort(port.Number)
+						if stateful {
+							fb.WithAction(ctCommitAction)
+						}
+					})...)
+			}
+		}
+		fs := withDoubleTaggedFilters(filterSetFromFilters(filters))
+		fs.Add(protoFilter(types.FilterProtocolAll, generator.BasePrioDefault, false).
+			WithChain(portChain).
+			WithAction(dropAction).
+			Build())
+		return fs
+	}
+
+	// filtersInChains returns the filters in the given chains, if prio is non zero only filters
+	// with priority in [prio, prio + 10) are returned
+	filtersInChains := func(filters []types.Filter, prio generator.BasePrio, chains ...uint32) tc.FilterSet {
+		fs := tc.NewFilterSetImpl()
+		for _, f := range filters {
+			p := *f.Attrs().Priority
+			if prio != 0 && (p < uint16(prio) || p >= uint16(prio)+10) {
+				continue
+			}
+			for _, c := range chains {
+				if c == chainOf(f) {
+					fs.Add(f)
+				}
+			}
+		}
+		return fs
+	}
+
+	union := func(sets ...tc.FilterSet) tc.FilterSet {
+		fs := tc.NewFilterSetImpl()
+		for _, s := range sets {
+			for _, f := range s.List() {
+				fs.Add(f)
+			}
+		}
+		return fs
+	}
+
+	It("generates goto port chain filter per CIDR and a port chain per set of ports", func() {
+		tcObj := genChainObjects(generator.Options{},
+			withPorts(ipRule(policyrules.PolicyActionPass, "10.0.0.0/24", "10.0.2.0/24"), tcp80, tcp443),
+			withPorts(ipRule(policyrules.PolicyActionPass, "10.0.4.0/24", "2001::/64"), tcp443, tcp80))
+
+		filtersEqual(filtersInChains(tcObj.Filters, generator.BasePrioPortChains, types.ChainDefaultChain),
+			union(
+				entryFilters("10.0.0.0/24", types.ChainDefaultChain, generator.PortChainBase),
+				entryFilters("10.0.2.0/24", types.ChainDefaultChain, generator.PortChainBase),
+				entryFilters("10.0.4.0/24", types.ChainDefaultChain, generator.PortChainBase),
+				entryFilters("2001::/64", types.ChainDefaultChain, generator.PortChainBase)))
+		filtersEqual(filtersInChains(tcObj.Filters, 0, generator.PortChainBase),
+			portChainFilters(generator.PortChainBase, false, tcp80, tcp443))
+		// no pass filters are generated in chain 0
+		Expect(filtersInChains(tcObj.Filters, generator.BasePrioPass, types.ChainDefaultChain).List()).To(BeEmpty())
+	})
+
+	It("splits overlapping CIDRs and allows ports of all containing CIDRs", func() {
+		tcObj := genChainObjects(generator.Options{},
+			withPorts(ipRule(policyrules.PolicyActionPass, "10.0.0.0/22"), tcp80),
+			withPorts(ipRule(policyrules.PolicyActionPass, "10.0.1.0/24"), tcp443))
+
+		// port chains are allocated in port set order: {tcp 80}, {tcp 80, tcp 443}
+		filtersEqual(filtersInChains(tcObj.Filters, generator.BasePrioPortChains, types.ChainDefaultChain),
+			union(
+				entryFilters("10.0.0.0/24", types.ChainDefaultChain, generator.PortChainBase),
+				entryFilters("10.0.1.0/24", types.ChainDefaultChain, generator.PortChainBase+1),
+				entryFilters("10.0.2.0/23", types.ChainDefaultChain, generator.PortChainBase)))
+		filtersEqual(filtersInChains(tcObj.Filters, 0, generator.PortChainBase),
+			portChainFilters(generator.PortChainBase, false, tcp80))
+		filtersEqual(filtersInChains(tcObj.Filters, 0, generator.PortChainBase+1),
+			portChainFilters(generator.PortChainBase+1, false, tcp80, tcp443))
+	})
+
+	It("generates other rules as SimpleTCGenerator", func() {
+		rules := []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.0/24"),
+			withPorts(ipRule(policyrules.PolicyActionPass), tcp80),
+			withPorts(ipRule(policyrules.PolicyActionDrop, "10.0.1.0/24"), tcp80),
+		}
+		simpleObj := genObjects(generator.Options{}, egressRuleSet(rules))
+		chainObj := genChainObjects(generator.Options{}, rules...)
+
+		filtersEqual(filterSetFromFilters(chainObj.Filters), filterSetFromFilters(simpleObj.Filters))
+	})
+
+	It("generates port chain pass filters which commit the connection if stateful", func() {
+		tcObj := genChainObjects(generator.Options{Stateful: true},
+			withPorts(ipRule(policyrules.PolicyActionPass, "10.0.0.0/24"), tcp80))
+
+		filtersEqual(filtersInChains(tcObj.Filters, generator.BasePrioPortChains, generator.ConnTrackChain),
+			entryFilters("10.0.0.0/24", generator.ConnTrackChain, generator.PortChainBase))
+		filtersEqual(filtersInChains(tcObj.Filters, 0, generator.PortChainBase),
+			portChainFilters(generator.PortChainBase, true, tcp80))
+	})
+
+	It("generates less filters than SimpleTCGenerator for rules with many CIDRs and ports", func() {
+		rule := policyrules.Rule{Action: policyrules.PolicyActionPass}
+		for i := 0; i < 10; i++ {
+			rule.IPCidrs = append(rule.IPCidrs, ipnetFromStr(fmt.Sprintf("10.0.%d.0/24", 2*i)))
+			rule.Ports = append(rule.Ports, tcpPort(uint16(1000+i)))
+		}
+		simpleObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{rule}))
+		chainObj := genChainObjects(generator.Options{}, rule)
+
+		// 6 default filters and 10 CIDRs X 10 ports X 3 vs. 10 CIDRs X 3 + 10 ports X 2 X 3 + 1
+		Expect(simpleObj.Filters).To(HaveLen(6 + 300))
+		Expect(chainObj.Filters).To(HaveLen(6 + 91))
+	})
+})
+
+var _ = Describe("PrioAllocator tests", func() {
+	var allocator *generator.PrioAllocator
+	band := generator.PrioBand{Name: "test", Start: 1000, Slots: 3}
+
+	BeforeEach(func() {
+		allocator = generator.NewPrioAllocator()
+	})
+
+	It("allocates slots in order", func() {
+		prios, err := allocator.Allocate("ifc", band, []string{"a", "b", "c"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"a": 1000, "b": 1008, "c": 1016}))
+	})
+
+	It("keeps slots of existing keys and allocates lowest free slot to new keys", func() {
+		_, err := allocator.Allocate("ifc", band, []string{"a", "b", "c"})
+		Expect(err).ToNot(HaveOccurred())
+
+		prios, err := allocator.Allocate("ifc", band, []string{"d", "c"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"d": 1000, "c": 1016}))
+	})
+
+	It("allocates slots per interface and band", func() {
+		_, err := allocator.Allocate("ifc", band, []string{"a", "b"})
+		Expect(err).ToNot(HaveOccurred())
+
+		prios, err := allocator.Allocate("other-ifc", band, []string{"b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"b": 1000}))
+
+		prios, err = allocator.Allocate("ifc", generator.PrioBandPass, []string{"b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"b": generator.PrioBandPass.Prio(0)}))
+	})
+
+	It("allocates lowest slots after release", func() {
+		_, err := allocator.Allocate("ifc", band, []string{"a", "b"})
+		Expect(err).ToNot(HaveOccurred())
+		allocator.Release("ifc")
+
+		prios, err := allocator.Allocate("ifc", band, []string{"b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"b": 1000}))
+	})
+
+	It("releases slots of interfaces which are not retained", func() {
+		_, err := allocator.Allocate("ifc", band, []string{"a", "b"})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = allocator.Allocate("other-ifc", band, []string{"a", "b"})
+		Expect(err).ToNot(HaveOccurred())
+		allocator.Retain(map[string]struct{}{"other-ifc": {}})
+
+		prios, err := allocator.Allocate("ifc", band, []string{"b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"b": 1000}))
+		prios, err = allocator.Allocate("other-ifc", band, []string{"b"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prios).To(Equal(map[string]generator.BasePrio{"b": 1008}))
+	})
+
+	It("fails if band is exhausted", func() {
+		_, err := allocator.Allocate("ifc", band, []string{"a", "b", "c", "d"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("bands are ordered", func() {
+		Expect(generator.PrioBandAdminDeny.Prio(generator.PrioBandAdminDeny.Slots)).
+			To(BeNumerically("<=", generator.PrioBandExcept.Start))
+		Expect(generator.PrioBandExcept.Prio(generator.PrioBandExcept.Slots)).
+			To(BeNumerically("<=", generator.PrioBandDSCPPass.Start))
+		Expect(generator.PrioBandDSCPPass.Prio(generator.PrioBandDSCPPass.Slots)).
+			To(BeNumerically("<=", generator.PrioBandPass.Start))
+		Expect(generator.BasePrioControl).To(BeNumerically("<", generator.PrioBandAdminDeny.Start))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator rule priorities tests", func() {
+	ruleSet := func(rules ...policyrules.Rule) policyrules.PolicyRuleSet {
+		rs := egressRuleSet(rules)
+		rs.IfcInfo = policyrules.InterfaceInfo{Network: "default/net1", InterfaceName: "net1", DeviceID: "0000:03:00.2"}
+		return rs
+	}
+	noRules := ruleSet([]policyrules.Rule{}...)
+	passRule := func(ipCidr string, port uint16) policyrules.Rule {
+		return tcpRule(policyrules.PolicyActionPass, ipCidr, port)
+	}
+
+	It("generates filters of each rule at a dedicated priority in the band of the rule action", func() {
+		tcObj := genObjects(generator.Options{RulePriorities: true}, ruleSet(
+			passRule("10.0.0.0/16", 1001),
+			tcpRule(policyrules.PolicyActionDrop, "10.0.1.0/24", 1002),
+			passRule("10.2.0.0/16", 1003)))
+
+		Expect(prioOf(tcObj.Filters, "10.0.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+		Expect(prioOf(tcObj.Filters, "10.0.1.0/24")).To(Equal(uint16(generator.PrioBandExcept.Prio(0))))
+
+		// default filters are generated after pass band, no two filters with different protocols share a priority
+		protoOfPrio := make(map[uint16]types.FilterProtocol)
+		for _, f := range tcObj.Filters {
+			prio := *f.Attrs().Priority
+			if f.Attrs().Protocol == types.FilterProtocolIPv4 && f.(*types.FlowerFilter).Flower.DstIP == nil {
+				Expect(prio).To(BeNumerically(">", generator.PrioBandPass.Prio(generator.PrioBandPass.Slots)))
+			}
+			if proto, ok := protoOfPrio[prio]; ok {
+				Expect(proto).To(Equal(f.Attrs().Protocol))
+			}
+			protoOfPrio[prio] = f.Attrs().Protocol
+		}
+
+		// pass rules with DSCP marking are allocated ahead of other pass rules
+		dscp := uint8(10)
+		dscpRule := passRule("10.3.0.0/16", 1007)
+		dscpRule.DSCP = &dscp
+		tcObj = genObjects(generator.Options{RulePriorities: true},
+			ruleSet(passRule("10.0.0.0/16", 1001), dscpRule))
+		Expect(prioOf(tcObj.Filters, "10.3.0.0/16")).To(Equal(uint16(generator.PrioBandDSCPPass.Prio(0))))
+		Expect(prioOf(tcObj.Filters, "10.0.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+	})
+
+	It("keeps priorities of unchanged rules across generations", func() {
+		gen := generator.NewSimpleTCGenerator(generator.Options{RulePriorities: true})
+		genObjectsWith(gen, ruleSet(passRule("10.0.0.0/16", 1004), passRule("10.2.0.0/16", 1005)))
+
+		tcObj := genObjectsWith(gen, ruleSet(passRule("10.4.0.0/16", 1006), passRule("10.2.0.0/16", 1005)))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+		Expect(prioOf(tcObj.Filters, "10.4.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+	})
+
+	It("keeps priorities of rules whose peers change across generations", func() {
+		gen := generator.NewSimpleTCGenerator(generator.Options{RulePriorities: true})
+		genObjectsWith(gen, ruleSet(passRule("10.0.0.0/16", 1004), passRule("10.2.0.0/16", 1005)))
+
+		tcObj := genObjectsWith(gen, ruleSet(passRule("10.3.0.0/16", 1005), passRule("10.4.0.0/16", 1006)))
+		Expect(prioOf(tcObj.Filters, "10.3.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+		Expect(prioOf(tcObj.Filters, "10.4.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+	})
+
+	It("keeps priorities of rules across generations with no rules", func() {
+		gen := generator.NewSimpleTCGenerator(generator.Options{RulePriorities: true})
+		genObjectsWith(gen, ruleSet(passRule("10.0.0.0/16", 1004), passRule("10.2.0.0/16", 1005)))
+
+		// e.g default deny on filter budget overflow
+		genObjectsWith(gen, noRules)
+
+		tcObj := genObjectsWith(gen, ruleSet(passRule("10.0.0.0/16", 1004), passRule("10.2.0.0/16", 1005)))
+		Expect(prioOf(tcObj.Filters, "10.0.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+
+		// only 10.2.0.0/16 rule after default deny
+		genObjectsWith(gen, noRules)
+		tcObj = genObjectsWith(gen, ruleSet(passRule("10.2.0.0/16", 1005)))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+	})
+
+	It("releases priorities of interfaces which are not retained", func() {
+		gen := generator.NewSimpleTCGenerator(generator.Options{RulePriorities: true})
+		genObjectsWith(gen, ruleSet(passRule("10.0.0.0/16", 1004), passRule("10.2.0.0/16", 1005)))
+		gen.RetainInterfaces([]policyrules.InterfaceInfo{ruleSet().IfcInfo})
+
+		tcObj := genObjectsWith(gen, ruleSet(passRule("10.2.0.0/16", 1005)))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(1))))
+
+		// interface removed
+		gen.RetainInterfaces(nil)
+		tcObj = genObjectsWith(gen, ruleSet(passRule("10.2.0.0/16", 1005)))
+		Expect(prioOf(tcObj.Filters, "10.2.0.0/16")).To(Equal(uint16(generator.PrioBandPass.Prio(0))))
+	})
+})
+
+var _ = Describe("CIDR aggregation tests", func() {
+	// randomCIDRs returns n random ipv4 CIDRs in 10.0.0.0/24 with prefix length 24 - 32
+	randomCIDRs := func(r *rand.Rand, n int) []*net.IPNet {
+		ipCidrs := make([]*net.IPNet, 0, n)
+		for i := 0; i < n; i++ {
+			ones := 24 + r.Intn(9)
+			mask := net.CIDRMask(ones, 32)
+			ip := net.IPv4(10, 0, 0, byte(r.Intn(256))).To4().Mask(mask)
+			ipCidrs = append(ipCidrs, &net.IPNet{IP: ip, Mask: mask})
+		}
+		return ipCidrs
+	}
+	// anyContains returns true if ip is contained in any of ipCidrs
+	anyContains := func(ipCidrs []*net.IPNet, ip net.IP) bool {
+		for _, ipCidr := range ipCidrs {
+			if ipCidr.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	// allAddresses returns the addresses of 10.0.0.0/23
+	allAddresses := func() []net.IP {
+		ips := make([]net.IP, 0, 512)
+		for i := 0; i < 512; i++ {
+			ips = append(ips, net.IPv4(10, 0, byte(i/256), byte(i%256)).To4())
+		}
+		return ips
+	}
+
+	It("aggregates overlapping and adjacent CIDRs", func() {
+		aggregated := generator.AggregateCIDRs([]*net.IPNet{
+			ipnetFromStr("10.0.0.1/32"),
+			ipnetFromStr("10.0.0.0/32"),
+			ipnetFromStr("10.0.0.2/31"),
+			ipnetFromStr("10.0.0.3/32"),
+			ipnetFromStr("192.168.0.0/16"),
+			ipnetFromStr("192.168.1.0/24"),
+			ipnetFromStr("2001::/65"),
+			ipnetFromStr("2001:0:0:0:8000::/65"),
+		}, nil)
+		Expect(aggregated).To(Equal([]*net.IPNet{
+			ipnetFromStr("10.0.0.0/30"),
+			ipnetFromStr("192.168.0.0/16"),
+			ipnetFromStr("2001::/64"),
+		}))
+	})
+
+	It("does not merge CIDRs if canMerge returns false", func() {
+		aggregated := generator.AggregateCIDRs([]*net.IPNet{
+			ipnetFromStr("10.0.0.0/32"),
+			ipnetFromStr("10.0.0.1/32"),
+		}, func(supernet, ipCidr *net.IPNet) bool { return false })
+		Expect(aggregated).To(Equal([]*net.IPNet{ipnetFromStr("10.0.0.0/32"), ipnetFromStr("10.0.0.1/32")}))
+	})
+
+	It("keeps the matched address set and returns minimal disjoint CIDRs", func() {
+		r := rand.New(rand.NewSource(1))
+		for iteration := 0; iteration < 500; iteration++ {
+			ipCidrs := randomCIDRs(r, 1+r.Intn(20))
+			aggregated := generator.AggregateCIDRs(ipCidrs, nil)
+
+			for _, ip := range allAddresses() {
+				Expect(anyContains(aggregated, ip)).To(Equal(anyContains(ipCidrs, ip)),
+					"address %s, CIDRs %v, aggregated %v", ip, ipCidrs, aggregated)
+			}
+			for i := range aggregated {
+				for j := range aggregated {
+					if i == j {
+						continue
+					}
+					// disjoint
+					Expect(aggregated[i].Contains(aggregated[j].IP)).To(BeFalse())
+					// no adjacent CIDRs of the same supernet
+					iOnes, _ := aggregated[i].Mask.Size()
+					jOnes, _ := aggregated[j].Mask.Size()
+					if iOnes == jOnes {
+						mask := net.CIDRMask(iOnes-1, 32)
+						Expect(aggregated[i].IP.Mask(mask).Equal(aggregated[j].IP.Mask(mask))).To(BeFalse())
+					}
+				}
+			}
+			Expect(len(aggregated)).To(BeNumerically("<=", len(ipCidrs)))
+		}
+	})
+
+	It("keeps the action of every address for pass and drop rules", func() {
+		// actionOf returns the action of the highest priority untagged ipv4 filter matching ip
+		actionOf := func(filters []types.Filter, ip net.IP) types.Action {
+			var action types.Action
+			prio := uint16(math.MaxUint16)
+			for _, f := range filters {
+				flowerFilter := f.(*types.FlowerFilter)
+				if flowerFilter.Protocol != types.FilterProtocolIPv4 || *flowerFilter.Priority >= prio {
+					continue
+				}
+				if flowerFilter.Flower.DstIP != nil && !flowerFilter.Flower.DstIP.Contains(ip) {
+					continue
+				}
+				prio = *flowerFilter.Priority
+				action = flowerFilter.Actions[len(flowerFilter.Actions)-1]
+			}
+			return action
+		}
+		// simpleFilters returns the filters generated for rules without aggregation, a filter per rule CIDR
+		simpleFilters := func(rules []policyrules.Rule) []types.Filter {
+			filters := make([]types.Filter, 0)
+			for _, rule := range rules {
+				basePrio, action := generator.BasePrioPass, passAction
+				if rule.Action == policyrules.PolicyActionDrop {
+					basePrio, action = generator.BasePrioDrop, dropAction
+				}
+				for _, ipCidr := range rule.IPCidrs {
+					filters = append(filters, protoFilter(types.FilterProtocolIPv4, basePrio, false).
+						WithMatchKeyDstIP(ipCidr).
+						WithAction(action).
+						Build())
+				}
+			}
+			return append(filters, protoFilter(types.FilterProtocolIPv4, generator.BasePrioDefault, false).
+				WithAction(dropAction).
+				Build())
+		}
+
+		r := rand.New(rand.NewSource(2))
+		for iteration := 0; iteration < 200; iteration++ {
+			rules := make([]policyrules.Rule, 0)
+			for i := 0; i < 1+r.Intn(6); i++ {
+				action := policyrules.PolicyActionPass
+				if r.Intn(3) == 0 {
+					action = policyrules.PolicyActionDrop
+				}
+				rules = append(rules, policyrules.Rule{IPCidrs: randomCIDRs(r, 1+r.Intn(5)), Action: action})
+			}
+			tcObj := genObjects(generator.Options{}, egressRuleSet(rules))
+
+			expected := simpleFilters(rules)
+			for _, ip := range allAddresses() {
+				Expect(actionOf(tcObj.Filters, ip).Equals(actionOf(expected, ip))).To(BeTrue(), "address %s", ip)
+			}
+		}
+	})
+
+	It("does not merge multicast CIDRs with other CIDRs if MulticastMAC is set", func() {
+		tcObj := genObjects(generator.Options{MulticastMAC: true}, egressRuleSet([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "224.0.0.0/4", "208.0.0.0/4", "224.0.0.0/5", "232.0.0.0/5")}))
+
+		dstIPs := make([]string, 0)
+		for _, f := range tcObj.Filters {
+			flowerFilter := f.(*types.FlowerFilter)
+			if flowerFilter.Protocol == types.FilterProtocolIPv4 && flowerFilter.Flower.DstIP != nil {
+				dstIPs = append(dstIPs, flowerFilter.Flower.DstIP.String())
+			}
+		}
+		Expect(dstIPs).To(ConsistOf("208.0.0.0/4", "224.0.0.0/4"))
+	})
+})
+
+var _ = Describe("SimpleTCGenerator chain templates tests", func() {
+	templateOpts := generator.Options{ChainTemplates: true}
+
+	portFilter := func(chain uint32, basePrio generator.BasePrio, cidr string, port uint16,
+		action types.Action) types.Filter {
+		return protoFilter(types.FilterProtocolIPv4, basePrio, false).
+			WithChain(chain).
+			WithMatchKeyDstIP(ipnetFromStr(cidr)).
+			WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+			WithMatchKeyDstPort(port).
+			WithAction(action).
+			Build()
+	}
+
+	defaultFilter := func(chain uint32, proto types.FilterProtocol) types.Filter {
+		return protoFilter(proto, generator.BasePrioDefault, false).WithChain(chain).WithAction(dropAction).Build()
+	}
+
+	gotoFilter := func(chain uint32, prio uint16, to uint32) types.Filter {
+		return types.NewFlowerFilterBuilder().
+			WithChain(chain).
+			WithProtocol(types.FilterProtocolAll).
+			WithPriority(prio).
+			WithAction(gotoChainAction(to)).
+			Build()
+	}
+
+	portTemplate := func(cidr string) *types.FlowerSpec {
+		return types.NewFlowerFilterBuilder().
+			WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+			WithMatchKeyDstIP(ipnetFromStr(cidr)).
+			WithMatchKeyDstPort(0).
+			Build().Flower
+	}
+
+	It("generates no chains when not enabled", func() {
+		tcObj := genObjects(generator.Options{}, egressRuleSet([]policyrules.Rule{
+			tcpRule(policyrules.PolicyActionPass, "10.100.1.0/24", 80)}))
+		Expect(tcObj.Chains).To(BeEmpty())
+	})
+
+	It("generates no chains when there are no rules", func() {
+		tcObj := genObjects(templateOpts, untaggedRuleSet(nil))
+		Expect(tcObj.Chains).To(BeEmpty())
+		Expect(tcObj.Filters).To(BeEmpty())
+	})
+
+	It("groups filters which match on the same mask in template chains", func() {
+		tcObj := genObjects(templateOpts, untaggedRuleSet([]policyrules.Rule{
+			tcpRule(policyrules.PolicyActionPass, "10.100.1.0/24", 80),
+			tcpRule(policyrules.PolicyActionPass, "10.100.2.5/32", 443),
+		}))
+
+		base := generator.TemplateChainBase
+		passPrio := generator.PrioFromBaseAndProtcol(generator.BasePrioPass, types.FilterProtocolIPv4)
+		filtersEqual(filterSetFromFilters(tcObj.Filters), filterSetFromFilters([]types.Filter{
+			gotoFilter(types.ChainDefaultChain, passPrio, base),
+			portFilter(base, generator.BasePrioPass, "10.100.1.0/24", 80, passAction),
+			gotoFilter(base, math.MaxUint16, base+1),
+			portFilter(base+1, generator.BasePrioPass, "10.100.2.5/32", 443, passAction),
+			gotoFilter(base+1, math.MaxUint16, base+2),
+			defaultFilter(base+2, types.FilterProtocolIPv4),
+			gotoFilter(base+2, math.MaxUint16, base+3),
+			defaultFilter(base+3, types.FilterProtocolIPv6),
+			gotoFilter(base+3, math.MaxUint16, base+4),
+			defaultFilter(base+4, types.FilterProtocol8021Q),
+			gotoFilter(base+4, math.MaxUint16, base+5),
+			defaultFilter(base+5, types.FilterProtocol8021AD),
+		}))
+
+		Expect(tcObj.Chains).To(HaveLen(6))
+		expectedTemplates := []*types.ChainTemplate{
+			{Protocol: types.FilterProtocolIPv4, Flower: portTemplate("0.0.0.0/24")},
+			{Protocol: types.FilterProtocolIPv4, Flower: portTemplate("0.0.0.0/32")},
+			{Protocol: types.FilterProtocolIPv4, Flower: &types.FlowerSpec{}},
+			{Protocol: types.FilterProtocolIPv6, Flower: &types.FlowerSpec{}},
+			{Protocol: types.FilterProtocol8021Q, Flower: &types.FlowerSpec{}},
+			{Protocol: types.FilterProtocol8021AD, Flower: &types.FlowerSpec{}},
+		}
+		for idx, c := range tcObj.Chains {
+			Expect(*c.Attrs().Chain).To(Equal(base + uint32(idx)))
+			Expect(c.Attrs().Template.Equals(expectedTemplates[idx])).To(BeTrue())
+		}
+	})
+
+	It("does not group filters of different protocols in the same template chain", func() {
+		tcObj := genObjects(templateOpts, untaggedRuleSet([]policyrules.Rule{
+			withPorts(ipRule(policyrules.PolicyActionPass), tcpPort(80))}))
+
+		templateProtocols := make(map[uint32]types.FilterProtocol)
+		for _, c := range tcObj.Chains {
+			templateProtocols[*c.Attrs().Chain] = c.Attrs().Template.Protocol
+		}
+		for _, f := range tcObj.Filters {
+			if chainOf(f) < generator.TemplateChainBase || f.Attrs().Protocol == types.FilterProtocolAll {
+				continue
+			}
+			Expect(f.Attrs().Protocol).To(Equal(templateProtocols[chainOf(f)]))
+		}
+	})
+
+	It("does not move filters before overlapping filters with lower priority", func() {
+		tcObj := genObjects(templateOpts, untaggedRuleSet([]policyrules.Rule{
+			tcpRule(policyrules.PolicyActionDrop, "10.100.0.0/24", 80),
+			tcpRule(policyrules.PolicyActionPass, "10.100.1.5/32", 80),
+			tcpRule(policyrules.PolicyActionPass, "10.100.2.0/24", 443),
+		}))
+
+		base := generator.TemplateChainBase
+		fs := filterSetFromFilters(tcObj.Filters)
+		Expect(fs.Has(portFilter(base, generator.BasePrioDrop, "10.100.0.0/24", 80, dropAction))).To(BeTrue())
+		Expect(fs.Has(portFilter(base+1, generator.BasePrioPass, "10.100.1.5/32", 80, passAction))).To(BeTrue())
+		Expect(fs.Has(portFilter(base+2, generator.BasePrioPass, "10.100.2.0/24", 443, passAction))).To(BeTrue())
+		Expect(tcObj.Chains).To(HaveLen(7))
+	})
+
+	It("generates template chains after connection tracking in stateful mode", func() {
+		tcObj := genObjects(generator.Options{ChainTemplates: true, Stateful: true}, untaggedRuleSet(
+			[]policyrules.Rule{tcpRule(policyrules.PolicyActionPass, "10.100.1.0/24", 80)}))
+
+		establishedPrio := generator.PrioFromBaseAndProtcol(generator.BasePrioEstablished, types.FilterProtocolIPv4)
+		fs := filterSetFromFilters(tcObj.Filters)
+		Expect(fs.Has(gotoFilter(generator.ConnTrackChain, establishedPrio, generator.TemplateChainBase))).
+			To(BeTrue())
+		for _, f := range tcObj.Filters {
+			chain := chainOf(f)
+			Expect(chain == types.ChainDefaultChain || chain == generator.ConnTrackChain ||
+				chain >= generator.TemplateChainBase).To(BeTrue())
+			if chain == generator.ConnTrackChain {
+				Expect(f.(*types.FlowerFilter).Actions).To(ConsistOf(gotoChainAction(generator.TemplateChainBase)))
+			}
+		}
+	})
+})
+
+var _ = Describe("U32TCGenerator tests", func() {
+	genU32Objects := func(opts generator.Options, rules []policyrules.Rule) *generator.Objects {
+		return genObjectsWith(generator.NewU32TCGenerator(opts), untaggedRuleSet(rules))
+	}
+
+	// u32Filters returns the u32 filters in tcObj and asserts that no flower filter matches only on a
+	// destination address
+	u32Filters := func(tcObj *generator.Objects) []*types.U32Filter {
+		var res []*types.U32Filter
+		for _, f := range tcObj.Filters {
+			if u32Filter, ok := f.(*types.U32Filter); ok {
+				res = append(res, u32Filter)
+				continue
+			}
+			flowerFilter := f.(*types.FlowerFilter)
+			if flowerFilter.Flower.DstIP != nil && flowerFilter.Flower.IPProto == nil &&
+				flowerFilter.Flower.VlanEthType == nil {
+				ones, bits := flowerFilter.Flower.DstIP.Mask.Size()
+				ExpectWithOffset(1, ones).ToNot(Equal(bits))
+			}
+		}
+		return res
+	}
+
+	hashTableOf := func(filters []*types.U32Filter, prio uint16) uint32 {
+		for _, f := range filters {
+			if *f.Priority == prio && f.U32.Divisor != 0 {
+				return f.U32.HashTable
+			}
+		}
+		return 0
+	}
+
+	It("generates no u32 filters when there are no rules", func() {
+		tcObj := genU32Objects(generator.Options{}, nil)
+		Expect(tcObj.Filters).To(BeEmpty())
+	})
+
+	It("replaces pass filters of destination addresses with u32 hash table filters", func() {
+		tcObj := genU32Objects(generator.Options{}, []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32", "10.0.1.10/32", "2001::1:2/128", "10.100.0.0/24")})
+
+		filters := u32Filters(tcObj)
+		ipv4Table := hashTableOf(filters, 200)
+		ipv6Table := hashTableOf(filters, 201)
+		Expect(ipv4Table).ToNot(BeZero())
+		Expect(ipv6Table).ToNot(BeZero())
+		Expect(ipv4Table).ToNot(Equal(ipv6Table))
+
+		u32Filter := func(proto types.FilterProtocol, prio uint16) *types.U32FilterBuilder {
+			return types.NewU32FilterBuilder().WithProtocol(proto).WithChain(0).WithPriority(prio)
+		}
+		expected := tc.NewFilterSetImpl()
+		expected.Add(u32Filter(types.FilterProtocolIPv4, 200).WithDivisor(ipv4Table, 256).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv4, 200).WithHashTable(ipv4Table, 10).
+			WithMatchDstIP(ipnetFromStr("10.0.0.10/32")).WithAction(passAction).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv4, 200).WithHashTable(ipv4Table, 10).
+			WithMatchDstIP(ipnetFromStr("10.0.1.10/32")).WithAction(passAction).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv4, 200).WithMatch(0, 0, 0).
+			WithHashKey(0xff, 16).WithLink(ipv4Table).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv6, 201).WithDivisor(ipv6Table, 256).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv6, 201).WithHashTable(ipv6Table, 2).
+			WithMatchDstIP(ipnetFromStr("2001::1:2/128")).WithAction(passAction).Build())
+		expected.Add(u32Filter(types.FilterProtocolIPv6, 201).WithMatch(0, 0, 0).
+			WithHashKey(0xff, 36).WithLink(ipv6Table).Build())
+
+		actual := tc.NewFilterSetImpl()
+		for _, f := range filters {
+			actual.Add(f)
+		}
+		filtersEqual(actual, expected)
+
+		// filters of CIDRs are kept, after the hash table
+		Expect(tcObj.Filters).To(ContainElement(types.NewFlowerFilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithPriority(204).
+			WithMatchKeyDstIP(ipnetFromStr("10.100.0.0/24")).
+			WithAction(passAction).
+			Build()))
+	})
+
+	It("adds hash tables before their filters and links", func() {
+		tcObj := genU32Objects(generator.Options{}, []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32", "10.0.0.20/32")})
+
+		filters := u32Filters(tcObj)
+		Expect(filters).To(HaveLen(4))
+		Expect(filters[0].U32.Divisor).To(Equal(uint32(256)))
+		Expect(filters[3].U32.Link).To(Equal(filters[0].U32.HashTable))
+	})
+
+	It("replaces drop filters in a hash table of their own", func() {
+		tcObj := genU32Objects(generator.Options{}, []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32"),
+			ipRule(policyrules.PolicyActionDrop, "10.0.0.11/32")})
+
+		filters := u32Filters(tcObj)
+		Expect(filters).To(HaveLen(6))
+		Expect(hashTableOf(filters, 100)).ToNot(Equal(hashTableOf(filters, 200)))
+		Expect(filters).To(ContainElement(types.NewU32FilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithChain(0).
+			WithPriority(100).
+			WithHashTable(hashTableOf(filters, 100), 11).
+			WithMatchDstIP(ipnetFromStr("10.0.0.11/32")).
+			WithAction(dropAction).
+			Build()))
+	})
+
+	It("keeps hash table IDs stable across generations", func() {
+		gen := generator.NewU32TCGenerator(generator.Options{})
+		genFilters := func(rules []policyrules.Rule) []*types.U32Filter {
+			return u32Filters(genObjectsWith(gen, egressRuleSet(rules)))
+		}
+
+		before := genFilters([]policyrules.Rule{ipRule(policyrules.PolicyActionPass, "10.0.0.10/32")})
+		after := genFilters([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionDrop, "10.0.0.11/32"),
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32", "10.0.0.12/32")})
+		Expect(hashTableOf(after, 200)).To(Equal(hashTableOf(before, 200)))
+	})
+
+	It("generates hash tables at the priority of the replaced filters", func() {
+		mac, _ := net.ParseMAC("00:11:22:33:44:55")
+		tcObj := genU32Objects(generator.Options{}, []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32"),
+			tcpRule(policyrules.PolicyActionPass, "10.0.0.11/32", 80),
+			{MACs: []net.HardwareAddr{mac}, Action: policyrules.PolicyActionPass}})
+
+		// hash table is evaluated before the filters of all protocols at the same base priority, as the replaced
+		// filters were. other ipv4 filters at the priority of the hash table are moved after it, filters of all
+		// protocols are moved after them.
+		Expect(hashTableOf(u32Filters(tcObj), 200)).ToNot(BeZero())
+		prios := make(map[types.FilterProtocol][]uint16)
+		for _, f := range tcObj.Filters {
+			if _, ok := f.(*types.FlowerFilter); ok && *f.Attrs().Priority < uint16(generator.BasePrioDefault) {
+				prios[f.Attrs().Protocol] = append(prios[f.Attrs().Protocol], *f.Attrs().Priority)
+			}
+		}
+		Expect(prios[types.FilterProtocolIPv4]).To(ConsistOf(uint16(204)))
+		Expect(prios[types.FilterProtocolAll]).To(ConsistOf(uint16(206)))
+	})
+
+	It("keeps the order of filters moved after hash tables", func() {
+		mac, _ := net.ParseMAC("00:11:22:33:44:55")
+		ruleSet := egressRuleSet([]policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32", "10.100.0.0/24"),
+			{MACs: []net.HardwareAddr{mac}, Action: policyrules.PolicyActionPass}})
+		simpleObj := genObjects(generator.Options{}, ruleSet)
+		u32Obj := genObjectsWith(generator.NewU32TCGenerator(generator.Options{}), ruleSet)
+
+		// filters of all protocols match the traffic of every other protocol, hence every other protocol keeps its
+		// order relative to them. moved filters are evaluated after the hash table.
+		beforeAll := func(tcObj *generator.Objects) map[types.FilterProtocol]bool {
+			prios := make(map[types.FilterProtocol]uint16)
+			for _, f := range tcObj.Filters {
+				if _, ok := f.(*types.FlowerFilter); ok && *f.Attrs().Priority < uint16(generator.BasePrioDefault) {
+					prios[f.Attrs().Protocol] = *f.Attrs().Priority
+				}
+			}
+			res := make(map[types.FilterProtocol]bool)
+			for proto, prio := range prios {
+				if proto != types.FilterProtocolAll {
+					res[proto] = prio < prios[types.FilterProtocolAll]
+				}
+			}
+			return res
+		}
+		Expect(beforeAll(simpleObj)).To(Equal(map[types.FilterProtocol]bool{
+			types.FilterProtocolIPv4: true, types.FilterProtocol8021Q: true, types.FilterProtocol8021AD: false}))
+		Expect(beforeAll(u32Obj)).To(Equal(beforeAll(simpleObj)))
+		Expect(hashTableOf(u32Filters(u32Obj), 200)).ToNot(BeZero())
+	})
+
+	It("does not replace filters with ports", func() {
+		tcObj := genU32Objects(generator.Options{}, []policyrules.Rule{
+			tcpRule(policyrules.PolicyActionPass, "10.0.0.10/32", 80)})
+		Expect(u32Filters(tcObj)).To(BeEmpty())
+	})
+
+	It("generates no chain templates", func() {
+		tcObj := genU32Objects(generator.Options{ChainTemplates: true}, []policyrules.Rule{
+			ipRule(policyrules.PolicyActionPass, "10.0.0.10/32")})
+		Expect(tcObj.Chains).To(BeEmpty())
+		Expect(u32Filters(tcObj)).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("Objects serialization tests", func() {
+	genTemplateObjects := func() *generator.Objects {
+		tcObj := genObjects(generator.Options{ChainTemplates: true, Stateful: true}, egressRuleSet(
+			[]policyrules.Rule{withPorts(ipRule(policyrules.PolicyActionPass, "10.0.0.0/24", "2001::/64"), tcpPort(80))}))
+		Expect(tcObj.Chains).ToNot(BeEmpty())
+		return tcObj
+	}
+
+	expectObjectsEqual := func(actual, expected *generator.Objects) {
+		ExpectWithOffset(1, actual.QDisc.Type()).To(Equal(expected.QDisc.Type()))
+		filtersEqual(filterSetFromFilters(actual.Filters), filterSetFromFilters(expected.Filters))
+		ExpectWithOffset(1, actual.Chains).To(HaveLen(len(expected.Chains)))
+		for i := range expected.Chains {
+			ExpectWithOffset(1, *actual.Chains[i].Attrs().Chain).To(Equal(*expected.Chains[i].Attrs().Chain))
+			ExpectWithOffset(1, actual.Chains[i].Attrs().Template.Equals(expected.Chains[i].Attrs().Template)).
+				To(BeTrue())
+		}
+	}
+
+	It("round trips generated objects via JSON", func() {
+		tcObj := genTemplateObjects()
+
+		data, err := json.Marshal(tcObj)
+		Expect(err).ToNot(HaveOccurred())
+		out := &generator.Objects{}
+		Expect(json.Unmarshal(data, out)).To(Succeed())
+
+		expectObjectsEqual(out, tcObj)
+	})
+
+	It("round trips generated objects via YAML", func() {
+		tcObj := genTemplateObjects()
+
+		data, err := yaml.Marshal(tcObj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(HavePrefix("apiVersion: " + types.SerializationVersion))
+		out := &generator.Objects{}
+		Expect(yaml.Unmarshal(data, out)).To(Succeed())
+
+		expectObjectsEqual(out, tcObj)
+	})
+
+	It("round trips objects without qdisc", func() {
+		data, err := json.Marshal(&generator.Objects{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"apiVersion": "v1"}`))
+
+		out := &generator.Objects{}
+		Expect(json.Unmarshal(data, out)).To(Succeed())
+		Expect(out.QDisc).To(BeNil())
+		Expect(out.Filters).To(BeEmpty())
+	})
+
+	It("fails to deserialize unsupported apiVersion", func() {
+		out := &generator.Objects{}
+		Expect(json.Unmarshal([]byte(`{"apiVersion": "v0", "qdisc": {"type": "ingress"}}`), out)).ToNot(Succeed())
+		Expect(json.Unmarshal([]byte(`{"qdisc": {"type": "ingress"}}`), out)).ToNot(Succeed())
+	})
+})
@@ -36,6 +36,11 @@ func (_m *Generator) GenerateFromPolicyRuleSet(ruleSet policyrules.PolicyRuleSet
 	return r0, r1
 }
 
+// RetainInterfaces provides a mock function with given fields: ifcs
+func (_m *Generator) RetainInterfaces(ifcs []policyrules.InterfaceInfo) {
+	_m.Called(ifcs)
+}
+
 type mockConstructorTestingTNewTCGenerator interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"net"
+
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+)
+
+var (
+	// ipv4Multicast is the IPv4 multicast network (224.0.0.0/4)
+	ipv4Multicast = &net.IPNet{IP: net.IPv4(224, 0, 0, 0).To4(), Mask: net.CIDRMask(4, 32)}
+	// ipv6Multicast is the IPv6 multicast network (ff00::/8)
+	ipv6Multicast = &net.IPNet{IP: net.ParseIP("ff00::"), Mask: net.CIDRMask(8, 128)}
+	// ipv4Broadcast is the IPv4 limited broadcast address
+	ipv4Broadcast = &net.IPNet{IP: net.IPv4bcast.To4(), Mask: net.CIDRMask(32, 32)}
+
+	// macBroadcast is the broadcast MAC address
+	macBroadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	// macMulticast is the group bit of a MAC address, it is set for multicast and broadcast MAC addresses
+	macMulticast = net.HardwareAddr{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+)
+
+// dstMACForIPNet returns the destination MAC match which corresponds to the provided destination IP CIDR.
+// ipv4 limited broadcast matches broadcast MAC, a CIDR contained in ipv4 or ipv6 multicast network matches
+// multicast MACs. nil is returned for any other CIDR.
+func dstMACForIPNet(ipCidr *net.IPNet) *tctypes.FlowerMAC {
+	if utils.IsIPv4(ipCidr.IP) {
+		if ipNetContains(ipv4Broadcast, ipCidr) {
+			return &tctypes.FlowerMAC{Addr: macBroadcast}
+		}
+		if ipNetContains(ipv4Multicast, ipCidr) {
+			return &tctypes.FlowerMAC{Addr: macMulticast, Mask: macMulticast}
+		}
+		return nil
+	}
+
+	if ipNetContains(ipv6Multicast, ipCidr) {
+		return &tctypes.FlowerMAC{Addr: macMulticast, Mask: macMulticast}
+	}
+	return nil
+}
+
+// ipNetContains returns true if inner network is entirely contained in outer network
+func ipNetContains(outer, inner *net.IPNet) bool {
+	outerOnes, _ := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	return innerOnes >= outerOnes && outer.Contains(inner.IP)
+}
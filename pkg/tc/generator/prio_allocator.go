@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// PrioSlotSize is the number of priorities in a rule slot, one per filter protocol (see PrioFromBaseAndProtcol)
+const PrioSlotSize = 8
+
+// PrioBand is an ordered range of filter priorities which holds filters of rules of the same kind.
+// each rule in the band is assigned a slot of PrioSlotSize priorities.
+type PrioBand struct {
+	// Name is the name of the band
+	Name string
+	// Start is the first priority of the band
+	Start uint16
+	// Slots is the number of rule slots in the band, i.e the maximal number of distinct rules in the band
+	Slots uint16
+}
+
+// Prio returns the base priority of slot in the band
+func (b PrioBand) Prio(slot uint16) BasePrio {
+	return BasePrio(b.Start + slot*PrioSlotSize)
+}
+
+var (
+	// PrioBandAdminDeny holds filters of admin deny rules, priorities 1000 - 8999 (1000 rules).
+	// Note(adrianc): reserved, there are no admin deny rules ATM.
+	PrioBandAdminDeny = PrioBand{Name: "admin-deny", Start: 1000, Slots: 1000}
+	// PrioBandExcept holds filters of drop rules (ipBlock except), priorities 10000 - 17999 (1000 rules)
+	PrioBandExcept = PrioBand{Name: "except", Start: 10000, Slots: 1000}
+	// PrioBandDSCPPass holds filters of pass rules with DSCP marking, priorities 20000 - 27999 (1000 rules)
+	PrioBandDSCPPass = PrioBand{Name: "dscp-pass", Start: 20000, Slots: 1000}
+	// PrioBandPass holds filters of pass rules, priorities 28000 - 59999 (4000 rules)
+	PrioBandPass = PrioBand{Name: "pass", Start: 28000, Slots: 4000}
+
+	// rulePrioBases maps base priorities of filters which are not generated per rule to their priority
+	// when rule priorities are allocated. filters with base priorities lower than BasePrioDrop
+	// (control traffic, global pass, anti-spoofing, connection tracking and rate limit) keep their priority.
+	rulePrioBases = map[BasePrio]BasePrio{
+		// fragments drop filters between except and pass bands
+		BasePrioFragments: 19000,
+		// default band: fragments pass, port chains and default drop filters after pass band
+		BasePrioPass:       60000,
+		BasePrioPortChains: 62000,
+		BasePrioDefault:    63000,
+	}
+)
+
+// NewPrioAllocator creates a new PrioAllocator instance
+func NewPrioAllocator() *PrioAllocator {
+	return &PrioAllocator{slots: make(map[string]map[string]map[string]uint16)}
+}
+
+// PrioAllocator allocates rule slots in PrioBands per interface. allocations are stable, a rule which was allocated
+// a slot in the previous allocation of the interface and band keeps its slot.
+// Note(adrianc): slots follow allocation history, not policy or rule order. a new rule is allocated the lowest free
+// slot, which may precede slots of existing rules (e.g a slot released by a removed rule). as rules in a band have
+// the same action, this only affects which DSCP value is set on traffic allowed by several rules with DSCP marking.
+type PrioAllocator struct {
+	mu sync.Mutex
+	// slots holds the slot of rule key per band name per interface
+	slots map[string]map[string]map[string]uint16
+}
+
+// Allocate allocates a slot in band for every rule key in keys for the interface identified by ifc and returns
+// the base priority of each rule key. slots of keys which are not provided are released. new keys are allocated
+// the lowest free slots in the order provided. an error is returned if band has not enough slots.
+func (a *PrioAllocator) Allocate(ifc string, band PrioBand, keys []string) (map[string]BasePrio, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prev := a.slots[ifc][band.Name]
+	slots := make(map[string]uint16, len(keys))
+	used := make(map[uint16]struct{}, len(keys))
+	for _, key := range keys {
+		if slot, ok := prev[key]; ok {
+			slots[key] = slot
+			used[slot] = struct{}{}
+		}
+	}
+
+	var next uint16
+	for _, key := range keys {
+		if _, ok := slots[key]; ok {
+			continue
+		}
+		for ; next < band.Slots; next++ {
+			if _, ok := used[next]; !ok {
+				break
+			}
+		}
+		if next >= band.Slots {
+			return nil, fmt.Errorf("priority band %s exhausted, %d slots available", band.Name, band.Slots)
+		}
+		slots[key] = next
+		used[next] = struct{}{}
+	}
+
+	if a.slots[ifc] == nil {
+		a.slots[ifc] = make(map[string]map[string]uint16)
+	}
+	a.slots[ifc][band.Name] = slots
+
+	prios := make(map[string]BasePrio, len(slots))
+	for key, slot := range slots {
+		prios[key] = band.Prio(slot)
+	}
+	return prios, nil
+}
+
+// Release releases all slots of the interface identified by ifc
+func (a *PrioAllocator) Release(ifc string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.slots, ifc)
+}
+
+// Retain releases all slots of interfaces which are not in ifcs
+func (a *PrioAllocator) Retain(ifcs map[string]struct{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for ifc := range a.slots {
+		if _, ok := ifcs[ifc]; !ok {
+			delete(a.slots, ifc)
+		}
+	}
+}
+
+// ruleKey returns a key which identifies rule by its action, origin (Global), DSCP, kind of peers and ports.
+// peers are not part of the key, rules keep their priority when their peers change (e.g pods are added).
+// Note(adrianc): rules with IPs and the same key are merged by aggregateRules, other rules with the same key
+// (e.g rules with MACs) share the same priority. rules which differ only by their peers cannot be ordered.
+func ruleKey(rule policyrules.Rule) string {
+	peers := "any"
+	switch {
+	case len(rule.MACs) > 0:
+		peers = "mac"
+	case len(rule.IPCidrs) > 0:
+		peers = "ip"
+	}
+	parts := []string{string(rule.Action), fmt.Sprintf("global=%t", rule.Global), "peers=" + peers}
+	if rule.DSCP != nil {
+		parts = append(parts, fmt.Sprintf("dscp=%d", *rule.DSCP))
+	}
+	return strings.Join(append(parts, portSetKey(uniquePorts(rule.Ports))), ",")
+}
+
+// ifcKey returns a key which identifies the interface of ifcInfo on the node
+func ifcKey(ifcInfo policyrules.InterfaceInfo) string {
+	return ifcInfo.DeviceID + "/" + ifcInfo.GetUID()
+}
+
+// withBasePrio sets the priority of the provided filters generated at oldBase to the same protocol priority at newBase
+func withBasePrio(filters []tctypes.Filter, oldBase, newBase BasePrio) []tctypes.Filter {
+	for _, f := range filters {
+		prio := *f.Attrs().Priority - uint16(oldBase) + uint16(newBase)
+		f.Attrs().Priority = &prio
+	}
+	return filters
+}
+
+// withRulePrioBases sets the priority of the provided filters which are not generated per rule according to
+// rulePrioBases. filters with priority outside of the generator base priorities are not modified.
+func withRulePrioBases(filters []tctypes.Filter) []tctypes.Filter {
+	for _, f := range filters {
+		prio := *f.Attrs().Priority
+		if prio >= uint16(PrioBandAdminDeny.Start) {
+			// rule filter
+			continue
+		}
+		// base priorities are multiples of 10, protocol offsets are lower than 10
+		base := BasePrio(prio - prio%10)
+		newBase, ok := rulePrioBases[base]
+		if !ok {
+			continue
+		}
+		withBasePrio([]tctypes.Filter{f}, base, newBase)
+	}
+	return filters
+}
@@ -7,22 +7,62 @@ import (
 type BasePrio uint16
 
 const (
-	BasePrioDefault BasePrio = 300
-	BasePrioPass    BasePrio = 200
-	BasePrioDrop    BasePrio = 100
+	BasePrioDefault    BasePrio = 300
+	BasePrioPortChains BasePrio = 290
+	BasePrioPass       BasePrio = 200
+	BasePrioDSCPPass   BasePrio = 190
+	BasePrioFragments  BasePrio = 150
+	BasePrioDrop       BasePrio = 100
+	BasePrioGlobalPass BasePrio = 80
+	BasePrioControl    BasePrio = 50
+
+	// anti-spoofing base priorities, used in chain 0 when anti-spoofing is enabled
+	BasePrioSpoofMACDrop BasePrio = 40
+	BasePrioSpoofMACPass BasePrio = 30
+	BasePrioSpoofDrop    BasePrio = 20
+	BasePrioSpoofPass    BasePrio = 10
+
+	// connection tracking base priorities, used when stateful mode is enabled.
+	// BasePrioConnTrack is used in chain 0 (or PolicyChain if anti-spoofing is enabled),
+	// BasePrioEstablished is used in ConnTrackChain.
+	BasePrioEstablished BasePrio = 20
+	BasePrioConnTrack   BasePrio = 10
+
+	// rate limit base priority, used in chain 0 when interface egress bandwidth is limited
+	BasePrioRateLimit BasePrio = 0
 )
 
 const (
 	prioOffsetIPv4 = iota
 	prioOffsetIPv6
 	prioOffset8021Q
+	prioOffsetARP
+	prioOffsetAll
+	prioOffset8021AD
+	// all protocols and 802.1ad filters in a slot with u32 hash table filters (see genU32HashFilters)
+	prioOffsetU32All
+	prioOffsetU328021AD
 )
 
 var (
 	protoToPrioOffset = map[tctypes.FilterProtocol]uint16{
-		tctypes.FilterProtocolIPv4:  prioOffsetIPv4,
-		tctypes.FilterProtocolIPv6:  prioOffsetIPv6,
-		tctypes.FilterProtocol8021Q: prioOffset8021Q,
+		tctypes.FilterProtocolIPv4:   prioOffsetIPv4,
+		tctypes.FilterProtocolIPv6:   prioOffsetIPv6,
+		tctypes.FilterProtocol8021Q:  prioOffset8021Q,
+		tctypes.FilterProtocolARP:    prioOffsetARP,
+		tctypes.FilterProtocolAll:    prioOffsetAll,
+		tctypes.FilterProtocol8021AD: prioOffset8021AD,
+	}
+
+	// u32SlotPrioOffsets holds the offsets of flower filters which are moved in a slot with u32 hash table filters.
+	// ipv4 and ipv6 flower filters which share their priority with u32 hash table filters are moved after ARP
+	// filters, all protocols and 802.1ad filters are moved after them to keep the order of filters which may match
+	// the same traffic.
+	u32SlotPrioOffsets = map[tctypes.FilterProtocol]uint16{
+		tctypes.FilterProtocolIPv4:   prioOffsetAll,
+		tctypes.FilterProtocolIPv6:   prioOffset8021AD,
+		tctypes.FilterProtocolAll:    prioOffsetU32All,
+		tctypes.FilterProtocol8021AD: prioOffsetU328021AD,
 	}
 )
 
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// serializedObjects is the serialized form of Objects
+type serializedObjects struct {
+	// APIVersion is the serialization version (see tctypes.SerializationVersion)
+	APIVersion string                     `json:"apiVersion"`
+	QDisc      *tctypes.SerializedQDisc   `json:"qdisc,omitempty"`
+	Chains     []tctypes.SerializedChain  `json:"chains,omitempty"`
+	Filters    []tctypes.SerializedFilter `json:"filters,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface. Objects are serialized to a versioned document
+// (see tctypes.SerializationVersion), YAML is supported via sigs.k8s.io/yaml.
+func (o Objects) MarshalJSON() ([]byte, error) {
+	so := serializedObjects{APIVersion: tctypes.SerializationVersion}
+
+	if o.QDisc != nil {
+		so.QDisc = tctypes.QDiscToSerializedQDisc(o.QDisc)
+	}
+	for _, c := range o.Chains {
+		so.Chains = append(so.Chains, *tctypes.ChainToSerializedChain(c))
+	}
+	for _, f := range o.Filters {
+		sf, err := tctypes.FilterToSerializedFilter(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to serialize filter")
+		}
+		so.Filters = append(so.Filters, *sf)
+	}
+	return json.Marshal(so)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (o *Objects) UnmarshalJSON(data []byte) error {
+	var so serializedObjects
+	if err := json.Unmarshal(data, &so); err != nil {
+		return err
+	}
+
+	if so.APIVersion != tctypes.SerializationVersion {
+		return fmt.Errorf("unsupported apiVersion: %q", so.APIVersion)
+	}
+
+	objs := Objects{Filters: make([]tctypes.Filter, 0, len(so.Filters))}
+	if so.QDisc != nil {
+		qdisc, err := tctypes.SerializedQDiscToQDisc(so.QDisc)
+		if err != nil {
+			return errors.Wrap(err, "failed to deserialize qdisc")
+		}
+		objs.QDisc = qdisc
+	}
+	for i := range so.Chains {
+		chain, err := tctypes.SerializedChainToChain(&so.Chains[i])
+		if err != nil {
+			return errors.Wrap(err, "failed to deserialize chain")
+		}
+		objs.Chains = append(objs.Chains, chain)
+	}
+	for i := range so.Filters {
+		filter, err := tctypes.SerializedFilterToFilter(&so.Filters[i])
+		if err != nil {
+			return errors.Wrapf(err, "failed to deserialize filter at index %d", i)
+		}
+		objs.Filters = append(objs.Filters, filter)
+	}
+
+	*o = objs
+	return nil
+}
@@ -4,35 +4,63 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/controllers"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
 	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 )
 
 var (
-	allProtocols = [...]tctypes.FilterProtocol{
+	ipProtocols = [...]tctypes.FilterProtocol{
 		tctypes.FilterProtocolIPv4,
 		tctypes.FilterProtocolIPv6,
-		tctypes.FilterProtocol8021Q,
 	}
 )
 
 // NewSimpleTCGenerator creates a new SimpleTCGenerator instance
-func NewSimpleTCGenerator() *SimpleTCGenerator {
-	return &SimpleTCGenerator{}
+func NewSimpleTCGenerator(opts Options) *SimpleTCGenerator {
+	s := &SimpleTCGenerator{opts: opts}
+	if opts.RulePriorities {
+		s.prioAllocator = NewPrioAllocator()
+	}
+	return s
 }
 
 // SimpleTCGenerator is a simple implementation for Generator interface
-type SimpleTCGenerator struct{}
+type SimpleTCGenerator struct {
+	opts Options
+	// portChains if set, pass rules with IPs and ports are generated in per port set chains
+	// (see genPortChainFilters)
+	portChains bool
+	// prioAllocator allocates rule priorities if RulePriorities is set in Options, nil otherwise
+	prioAllocator *PrioAllocator
+}
 
 // GenerateFromPolicyRuleSet implements Generator interface
 // It renders TC objects needed to satisfy the rules in the provided PolicyRuleSet
-// QDisc is Ingress QDisc
+// QDisc is Ingress QDisc, or Clsact QDisc if Clsact is set in Options (filters are attached to its ingress hook)
 // Filters is a list of filters which satisfy the PolicyRuleSet. They are generated as follows
-//  1. Drop rule at chain 0, priority 300 for all traffic
-//  2. Accept rules per CIDR X Port for every Pass Rule in PolicyRuleSet at chain 0, priority 200
+//  1. Drop rule at chain 0, priority 300 for all IP traffic, or for all traffic if strict mode is enabled
+//  2. Accept rules per CIDR (or MAC) X Port for every Pass Rule in PolicyRuleSet at chain 0, priority 200
+//     DSCP is set on allowed ip traffic if Rule has DSCP (see withDSCPMarking), such rules are generated at
+//     priority 190 ahead of other pass rules
 //  3. Drop rules per CIDR X Port for every Drop Rule in PolicyRuleSet at chain 0, prioirty 100
+//  4. Accept rules for every control traffic type in Options at chain 0, priority 50
+//  5. Accept rules per CIDR X Port for every Global Pass Rule (node-wide allowlist) at chain 0, priority 80
+//  6. Accept or drop rules for IP fragments according to Fragments policy in Options at chain 0, priority 200 or 150
 //     Note: only Egress Policy type is supported
+//
+// If stateful mode is enabled, the filters above are generated in ConnTrackChain, pass rules commit the connection
+// and connection tracking filters are generated in chain 0 in the zone of the interface (see genConnTrackFilters).
+// If the network has VLAN IDs, tagged filters with pass action above match on each of the network VLAN IDs.
+// If ChainTemplates is set in Options, the filters above which match on the same mask are moved to template chains
+// starting from TemplateChainBase (see genTemplateChains).
+// If anti-spoofing is enabled for the network, the filters at chain 0 are generated in PolicyChain
+// and anti-spoofing filters are generated in chain 0 at priorities 10 - 45.
+// If the interface egress bandwidth is limited, the filters at chain 0 are generated in RateLimitChain
+// and a rate limit filter is generated in chain 0 at priority 4 (see genRateLimitFilters).
+// If the network VLAN mode is untagged, no filters matching tagged (802.1Q, 802.1ad) ip or arp traffic are generated,
+// tagged traffic is dropped by default filters (see genDefaultFilters).
 func (s *SimpleTCGenerator) GenerateFromPolicyRuleSet(ruleSet policyrules.PolicyRuleSet) (*Objects, error) {
 	tcObj := &Objects{
 		QDisc:   nil,
@@ -44,37 +72,201 @@ func (s *SimpleTCGenerator) GenerateFromPolicyRuleSet(ruleSet policyrules.Policy
 	}
 
 	// create qdisc obj
-	tcObj.QDisc = tctypes.NewIngressQDiscBuilder().Build()
+	if s.opts.Clsact {
+		tcObj.QDisc = tctypes.NewClsactQDiscBuilder().Build()
+	} else {
+		tcObj.QDisc = tctypes.NewIngressQDiscBuilder().Build()
+	}
+
+	// create filters
+	policyFilters, err := s.genPolicyFilters(ruleSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.opts.Stateful && len(policyFilters) > 0 {
+		policyFilters = genConnTrackFilters(policyFilters, ConnTrackZone(ruleSet.IfcInfo))
+	}
+
+	untagged := s.isUntagged(ruleSet.IfcInfo)
+	if untagged {
+		// tagged traffic is dropped by default filters
+		policyFilters = withoutTaggedFilters(policyFilters)
+	}
+
+	// allow tagged traffic only on the VLANs of the network
+	policyFilters = withVlanIDs(policyFilters, ruleSet.IfcInfo.NetworkConfig.VlanIDs)
+
+	if s.opts.ChainTemplates && len(policyFilters) > 0 {
+		// group policy filters which match on the same mask in template chains
+		entryChain := tctypes.ChainDefaultChain
+		if s.opts.Stateful {
+			entryChain = ConnTrackChain
+		}
+		tcObj.Chains, policyFilters = genTemplateChains(policyFilters, entryChain)
+	}
+
+	if ruleSet.IfcInfo.NetworkConfig.AntiSpoofing {
+		// anti-spoofing filters at chain 0, policy filters at PolicyChain
+		antiSpoofFilters := genAntiSpoofFilters(ruleSet.IfcInfo)
+		if untagged {
+			antiSpoofFilters = withoutTaggedFilters(antiSpoofFilters)
+		}
+		tcObj.Filters = append(tcObj.Filters, antiSpoofFilters...)
+		tcObj.Filters = append(tcObj.Filters, genPolicyChainFilters(policyFilters)...)
+	} else {
+		tcObj.Filters = append(tcObj.Filters, policyFilters...)
+	}
+
+	if ruleSet.IfcInfo.EgressBandwidth > 0 {
+		// rate limit filter at chain 0, other filters at RateLimitChain
+		tcObj.Filters = genRateLimitFilters(tcObj.Filters, ruleSet.IfcInfo.EgressBandwidth)
+	}
+
+	return tcObj, nil
+}
+
+// RetainInterfaces implements Generator interface
+// It releases the rule priorities allocated for interfaces which are not in ifcs
+func (s *SimpleTCGenerator) RetainInterfaces(ifcs []policyrules.InterfaceInfo) {
+	if s.prioAllocator == nil {
+		return
+	}
+	keys := make(map[string]struct{}, len(ifcs))
+	for _, ifc := range ifcs {
+		keys[ifcKey(ifc)] = struct{}{}
+	}
+	s.prioAllocator.Retain(keys)
+}
+
+// genPolicyFilters generates Filters for the provided PolicyRuleSet rules, no filters are generated if
+// PolicyRuleSet has no rules.
+func (s *SimpleTCGenerator) genPolicyFilters(ruleSet policyrules.PolicyRuleSet) ([]tctypes.Filter, error) {
+	filters := make([]tctypes.Filter, 0)
 
 	if ruleSet.Rules == nil {
 		// no rules
-		return tcObj, nil
+		if s.prioAllocator != nil {
+			s.prioAllocator.Release(ifcKey(ruleSet.IfcInfo))
+		}
+		return filters, nil
 	}
 
-	// create filters
+	// merge CIDRs of rules with the same action and ports
+	ruleSet.Rules = s.aggregateRules(ruleSet.Rules)
+
+	rulePrios, err := s.allocateRulePrios(ruleSet)
+	if err != nil {
+		return nil, err
+	}
 
 	// default filters at priority 3xx
-	tcObj.Filters = append(tcObj.Filters, s.genDefaultFilters()...)
+	strict := s.opts.StrictMode || ruleSet.IfcInfo.NetworkConfig.StrictMode
+	filters = append(filters, s.genDefaultFilters(strict, s.isUntagged(ruleSet.IfcInfo))...)
+
+	// control traffic filters at priority 5x
+	filters = append(filters, genControlFilters(s.opts.ControlTraffic)...)
 
+	chainRules := make([]policyrules.Rule, 0)
 	for _, rule := range ruleSet.Rules {
-		// 2. accept rules at priority 2xx
+		// 2. accept rules at priority 2xx (8x for global rules)
 		// 3. drop rules at priority 1xx
 		switch rule.Action {
 		case policyrules.PolicyActionPass:
-			tcObj.Filters = append(tcObj.Filters, s.genPassFilters(rule)...)
+			if s.portChains && isPortChainRule(rule) {
+				chainRules = append(chainRules, rule)
+				continue
+			}
+			passFilters := s.genPassFilters(rule)
+			basePrio := BasePrioPass
+			if rule.DSCP != nil {
+				// rules with DSCP marking precede other pass rules so that traffic they allow is marked
+				basePrio = BasePrioDSCPPass
+				passFilters = withDSCPMarking(withBasePrio(passFilters, BasePrioPass, basePrio), *rule.DSCP)
+			}
+			if prio, ok := rulePrios[ruleKey(rule)]; ok {
+				passFilters = withBasePrio(passFilters, basePrio, prio)
+			}
+			if s.opts.Stateful {
+				passFilters = withConnTrackCommit(passFilters, ConnTrackZone(ruleSet.IfcInfo))
+			}
+			filters = append(filters, passFilters...)
 		case policyrules.PolicyActionDrop:
-			tcObj.Filters = append(tcObj.Filters, s.genDropFilters(rule)...)
+			dropFilters := s.genDropFilters(rule)
+			if prio, ok := rulePrios[ruleKey(rule)]; ok {
+				dropFilters = withBasePrio(dropFilters, BasePrioDrop, prio)
+			}
+			filters = append(filters, dropFilters...)
 		default:
 			// we should not get here
 			return nil, fmt.Errorf("unknown policy action for rule. %s", rule.Action)
 		}
 	}
-	return tcObj, nil
+
+	// port chain filters at priority 29x
+	if len(chainRules) > 0 {
+		filters = append(filters, s.genPortChainFilters(chainRules, ConnTrackZone(ruleSet.IfcInfo))...)
+	}
+
+	// fragments filters at priority 15x or 2xx
+	filters = append(filters, genFragmentsFilters(s.opts.Fragments, ruleSet.Rules)...)
+
+	if s.prioAllocator != nil {
+		filters = withRulePrioBases(filters)
+	}
+	return filters, nil
 }
 
-// genPassFilters generates Filters with Pass action
+// allocateRulePrios allocates priorities for the provided PolicyRuleSet rules if RulePriorities is set in Options.
+// drop rules are allocated in PrioBandExcept, pass rules with DSCP marking in PrioBandDSCPPass and other pass rules
+// in PrioBandPass. global pass rules and pass rules generated in port chains are not allocated. it returns the base
+// priority per rule key (see ruleKey). allocations are kept if PolicyRuleSet has no rules (e.g default deny is
+// applied on filter budget overflow), they are released once the interface is removed.
+func (s *SimpleTCGenerator) allocateRulePrios(ruleSet policyrules.PolicyRuleSet) (map[string]BasePrio, error) {
+	prios := make(map[string]BasePrio)
+	if s.prioAllocator == nil || len(ruleSet.Rules) == 0 {
+		return prios, nil
+	}
+
+	var passKeys, dscpPassKeys, dropKeys []string
+	for _, rule := range ruleSet.Rules {
+		switch {
+		case rule.Action == policyrules.PolicyActionDrop:
+			dropKeys = append(dropKeys, ruleKey(rule))
+		case rule.Global || (s.portChains && isPortChainRule(rule)):
+			continue
+		case rule.DSCP != nil:
+			dscpPassKeys = append(dscpPassKeys, ruleKey(rule))
+		default:
+			passKeys = append(passKeys, ruleKey(rule))
+		}
+	}
+
+	ifc := ifcKey(ruleSet.IfcInfo)
+	bandKeys := map[PrioBand][]string{PrioBandExcept: dropKeys, PrioBandDSCPPass: dscpPassKeys, PrioBandPass: passKeys}
+	for band, keys := range bandKeys {
+		bandPrios, err := s.prioAllocator.Allocate(ifc, band, keys)
+		if err != nil {
+			return nil, err
+		}
+		for key, prio := range bandPrios {
+			prios[key] = prio
+		}
+	}
+	return prios, nil
+}
+
+// genPassFilters generates Filters with Pass action. filters of Global rules are generated at BasePrioGlobalPass
 func (s *SimpleTCGenerator) genPassFilters(rule policyrules.Rule) []tctypes.Filter {
-	return s.genFilters(rule.IPCidrs, rule.Ports, BasePrioPass, tctypes.NewGenericActionBuiler().WithPass().Build())
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+	basePrio := BasePrioPass
+	if rule.Global {
+		basePrio = BasePrioGlobalPass
+	}
+	if len(rule.MACs) > 0 {
+		return genFiltersWithMACs(rule.MACs, rule.Ports, basePrio, pass)
+	}
+	return s.genFilters(rule.IPCidrs, rule.Ports, basePrio, pass)
 }
 
 // genPassFilters generates Filters with Drop action
@@ -87,14 +279,53 @@ func (s *SimpleTCGenerator) genDropFilters(rule policyrules.Rule) []tctypes.Filt
 //  2. drop ipv6 traffic
 //  3. drop 802.1Q ipv4 traffic
 //  4. drop 802.1Q ipv6 traffic
-func (s *SimpleTCGenerator) genDefaultFilters() []tctypes.Filter {
-	return s.genFilters(nil, nil, BasePrioDefault,
-		tctypes.NewGenericActionBuiler().WithDrop().Build())
+//  5. drop 802.1ad (QinQ) ipv4 traffic
+//  6. drop 802.1ad (QinQ) ipv6 traffic
+//
+// if strict is set, a single filter which drops all traffic is generated instead. otherwise if untagged is set,
+// (3) - (6) are replaced by a filter which drops all 802.1Q traffic and a filter which drops all 802.1ad traffic.
+func (s *SimpleTCGenerator) genDefaultFilters(strict, untagged bool) []tctypes.Filter {
+	if strict {
+		return []tctypes.Filter{
+			tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(PrioFromBaseAndProtcol(BasePrioDefault, tctypes.FilterProtocolAll)).
+				WithAction(tctypes.NewGenericActionBuiler().WithDrop().Build()).
+				Build(),
+		}
+	}
+	drop := tctypes.NewGenericActionBuiler().WithDrop().Build()
+	if !untagged {
+		return s.genFilters(nil, nil, BasePrioDefault, drop)
+	}
+
+	filters := make([]tctypes.Filter, 0)
+	for _, proto := range []tctypes.FilterProtocol{
+		tctypes.FilterProtocolIPv4, tctypes.FilterProtocolIPv6,
+		tctypes.FilterProtocol8021Q, tctypes.FilterProtocol8021AD} {
+		filters = append(filters, tctypes.NewFlowerFilterBuilder().
+			WithProtocol(proto).
+			WithPriority(PrioFromBaseAndProtcol(BasePrioDefault, proto)).
+			WithAction(drop).
+			Build())
+	}
+	return filters
+}
+
+// isUntagged returns true if the VLAN mode of the network of ifcInfo is controllers.VlanModeUntagged.
+// the VLAN mode in Options applies to networks which do not configure a VLAN mode.
+func (s *SimpleTCGenerator) isUntagged(ifcInfo policyrules.InterfaceInfo) bool {
+	mode := controllers.VlanMode(ifcInfo.NetworkConfig.VlanMode)
+	if mode == "" {
+		mode = s.opts.VlanMode
+	}
+	return mode == controllers.VlanModeUntagged
 }
 
 // genFilters generates (flower) Filters based on provided ipCidrs, ports on the given base prio with the given action
 // the filters generated are: matching on {ipCidrs} [X {Ports}] With priority `prio`, and action `action`
-// if no IPs and Ports provided, returned filters will match all ipv4, ipv6, 802.1q traffic with provided action
+// if no IPs and Ports provided, returned filters will match all ipv4, ipv6, 802.1q, 802.1ad traffic with provided
+// action
 func (s *SimpleTCGenerator) genFilters(ipCidrs []*net.IPNet, ports []policyrules.Port, basePrio BasePrio,
 	action tctypes.Action) []tctypes.Filter {
 	hasIPs := len(ipCidrs) > 0
@@ -129,22 +360,10 @@ func (s *SimpleTCGenerator) genFiltersWithIPs(ipCidrs []*net.IPNet, basePrio Bas
 			proto = tctypes.FilterProtocolIPv6
 		}
 
-		filters = append(filters,
-			tctypes.NewFlowerFilterBuilder().
-				WithProtocol(proto).
-				WithPriority(PrioFromBaseAndProtcol(basePrio, proto)).
-				WithMatchKeyDstIP(ipCidr).
-				WithAction(action).
-				Build())
-		// traffic may be tagged, add rule to match on tag traffic as well
-		filters = append(filters,
-			tctypes.NewFlowerFilterBuilder().
-				WithProtocol(tctypes.FilterProtocol8021Q).
-				WithPriority(PrioFromBaseAndProtcol(basePrio, tctypes.FilterProtocol8021Q)).
-				WithMatchKeyVlanEthType(tctypes.ProtoToFlowerVlanEthType(proto)).
-				WithMatchKeyDstIP(ipCidr).
-				WithAction(action).
-				Build())
+		filters = append(filters, genFiltersForProto(proto, basePrio, action,
+			func(fb *tctypes.FlowerFilterBuilder) {
+				s.withMatchDstIP(fb, ipCidr)
+			})...)
 	}
 
 	return filters
@@ -156,40 +375,23 @@ func (s *SimpleTCGenerator) genFiltersWithPorts(ports []policyrules.Port, basePr
 	filters := make([]tctypes.Filter, 0)
 
 	for _, port := range ports {
+		port := port
 		// match all protocols with given port
-		for _, proto := range allProtocols {
-			prio := PrioFromBaseAndProtcol(basePrio, proto)
-
-			if proto == tctypes.FilterProtocol8021Q {
-				// for vlan protocol we need to match on both ipv4 and ipv6 vlan eth type
-				filters = append(filters,
-					tctypes.NewFlowerFilterBuilder().
-						WithProtocol(proto).
-						WithPriority(prio).
-						WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
-						WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
-						WithMatchKeyDstPort(port.Number).
-						WithAction(action).
-						Build())
-				filters = append(filters,
-					tctypes.NewFlowerFilterBuilder().
-						WithProtocol(proto).
-						WithPriority(prio).
-						WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv6).
-						WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
-						WithMatchKeyDstPort(port.Number).
-						WithAction(action).
-						Build())
-			} else {
-				filters = append(filters,
-					tctypes.NewFlowerFilterBuilder().
-						WithProtocol(proto).
-						WithPriority(prio).
-						WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
-						WithMatchKeyDstPort(port.Number).
-						WithAction(action).
-						Build())
-			}
+		for _, proto := range ipProtocols {
+			filters = append(filters,
+				tctypes.NewFlowerFilterBuilder().
+					WithProtocol(proto).
+					WithPriority(PrioFromBaseAndProtcol(basePrio, proto)).
+					WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
+					WithMatchKeyDstPort(port.Number).
+					WithAction(action).
+					Build())
+			// traffic may be tagged, add rule to match on tag traffic as well
+			filters = append(filters, genVlanFilters(proto, basePrio, action,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					fb.WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
+						WithMatchKeyDstPort(port.Number)
+				})...)
 		}
 	}
 
@@ -212,63 +414,119 @@ func (s *SimpleTCGenerator) genFiltersWithIPsAndPorts(ipCidrs []*net.IPNet, port
 		}
 
 		for _, port := range ports {
-			filters = append(filters,
-				tctypes.NewFlowerFilterBuilder().
-					WithProtocol(proto).
-					WithPriority(PrioFromBaseAndProtcol(basePrio, proto)).
-					WithMatchKeyDstIP(ipCidr).
-					WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
-					WithMatchKeyDstPort(port.Number).
-					WithAction(action).
-					Build())
-			// traffic may be tagged, add rule to match on tag traffic as well
-			filters = append(filters,
-				tctypes.NewFlowerFilterBuilder().
-					WithProtocol(tctypes.FilterProtocol8021Q).
-					WithPriority(PrioFromBaseAndProtcol(basePrio, tctypes.FilterProtocol8021Q)).
-					WithMatchKeyVlanEthType(tctypes.ProtoToFlowerVlanEthType(proto)).
-					WithMatchKeyDstIP(ipCidr).
-					WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
-					WithMatchKeyDstPort(port.Number).
-					WithAction(action).
-					Build())
+			port := port
+			filters = append(filters, genFiltersForProto(proto, basePrio, action,
+				func(fb *tctypes.FlowerFilterBuilder) {
+					s.withMatchDstIP(fb, ipCidr).
+						WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
+						WithMatchKeyDstPort(port.Number)
+				})...)
 		}
 	}
 
 	return filters
 }
 
+// genFiltersWithMACs generates (flower) Filters based on provided destination MACs and ports on the given base prio
+// with the given action. if no ports are provided, filters match all traffic to the MACs. otherwise filters match
+// ipv4 and ipv6 traffic to the MACs on the given ports.
+func genFiltersWithMACs(macs []net.HardwareAddr, ports []policyrules.Port, basePrio BasePrio,
+	action tctypes.Action) []tctypes.Filter {
+	filters := make([]tctypes.Filter, 0)
+
+	for _, mac := range macs {
+		mac := mac
+		if len(ports) == 0 {
+			filters = append(filters, tctypes.NewFlowerFilterBuilder().
+				WithProtocol(tctypes.FilterProtocolAll).
+				WithPriority(PrioFromBaseAndProtcol(basePrio, tctypes.FilterProtocolAll)).
+				WithMatchKeyDstMAC(mac, nil).
+				WithAction(action).
+				Build())
+			continue
+		}
+
+		for _, port := range ports {
+			port := port
+			for _, proto := range ipProtocols {
+				filters = append(filters, genFiltersForProto(proto, basePrio, action,
+					func(fb *tctypes.FlowerFilterBuilder) {
+						fb.WithMatchKeyDstMAC(mac, nil).
+							WithMatchKeyIPProto(tctypes.PortProtocolToFlowerIPProto(port.Protocol)).
+							WithMatchKeyDstPort(port.Number)
+					})...)
+			}
+		}
+	}
+
+	return filters
+}
+
+// withMatchDstIP adds destination IP match for ipCidr to the provided FlowerFilterBuilder. if MulticastMAC is set
+// in Options, the corresponding multicast or broadcast destination MAC match is added as well (see dstMACForIPNet).
+func (s *SimpleTCGenerator) withMatchDstIP(
+	fb *tctypes.FlowerFilterBuilder, ipCidr *net.IPNet) *tctypes.FlowerFilterBuilder {
+	fb.WithMatchKeyDstIP(ipCidr)
+	if !s.opts.MulticastMAC {
+		return fb
+	}
+	if dstMAC := dstMACForIPNet(ipCidr); dstMAC != nil {
+		fb.WithMatchKeyDstMAC(dstMAC.Addr, dstMAC.Mask)
+	}
+	return fb
+}
+
 // genFiltersMatchAll generates (flower) Filters matchin all IP traffic on the given base prio with the given action.
 func (s *SimpleTCGenerator) genFiltersMatchAll(basePrio BasePrio, action tctypes.Action) []tctypes.Filter {
 	filters := make([]tctypes.Filter, 0)
 
-	for _, proto := range allProtocols {
-		prio := PrioFromBaseAndProtcol(basePrio, proto)
-		if proto == tctypes.FilterProtocol8021Q {
-			// add for both ipv4 and ipv6 packets
-			filters = append(filters,
-				tctypes.NewFlowerFilterBuilder().
-					WithProtocol(proto).
-					WithPriority(prio).
-					WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv4).
-					WithAction(action).
-					Build())
-			filters = append(filters,
-				tctypes.NewFlowerFilterBuilder().
-					WithProtocol(proto).
-					WithPriority(prio).
-					WithMatchKeyVlanEthType(tctypes.FlowerVlanEthTypeIPv6).
-					WithAction(action).
-					Build())
-		} else {
-			filters = append(filters,
-				tctypes.NewFlowerFilterBuilder().
-					WithProtocol(proto).
-					WithPriority(prio).
-					WithAction(action).
-					Build())
-		}
+	for _, proto := range ipProtocols {
+		filters = append(filters,
+			tctypes.NewFlowerFilterBuilder().
+				WithProtocol(proto).
+				WithPriority(PrioFromBaseAndProtcol(basePrio, proto)).
+				WithAction(action).
+				Build())
+		// add for tagged packets as well
+		filters = append(filters, genVlanFilters(proto, basePrio, action,
+			func(fb *tctypes.FlowerFilterBuilder) {})...)
 	}
 
 	return filters
 }
+
+// genFiltersForProto generates a filter for proto and its tagged counterparts on the given base prio with
+// the given action. withMatches is used to add the additional flower matches to all filters.
+func genFiltersForProto(proto tctypes.FilterProtocol, basePrio BasePrio, action tctypes.Action,
+	withMatches func(fb *tctypes.FlowerFilterBuilder)) []tctypes.Filter {
+	untagged := tctypes.NewFlowerFilterBuilder().
+		WithProtocol(proto).
+		WithPriority(PrioFromBaseAndProtcol(basePrio, proto)).
+		WithAction(action)
+	withMatches(untagged)
+
+	return append([]tctypes.Filter{untagged.Build()}, genVlanFilters(proto, basePrio, action, withMatches)...)
+}
+
+// genVlanFilters generates (flower) Filters matching single tagged (802.1Q) and double tagged (802.1ad)
+// traffic with inner protocol proto on the given base prio with the given action.
+// withMatches is used to add the additional flower matches to the generated filters.
+func genVlanFilters(proto tctypes.FilterProtocol, basePrio BasePrio, action tctypes.Action,
+	withMatches func(fb *tctypes.FlowerFilterBuilder)) []tctypes.Filter {
+	singleTagged := tctypes.NewFlowerFilterBuilder().
+		WithProtocol(tctypes.FilterProtocol8021Q).
+		WithPriority(PrioFromBaseAndProtcol(basePrio, tctypes.FilterProtocol8021Q)).
+		WithMatchKeyVlanEthType(tctypes.ProtoToFlowerVlanEthType(proto)).
+		WithAction(action)
+	withMatches(singleTagged)
+
+	doubleTagged := tctypes.NewFlowerFilterBuilder().
+		WithProtocol(tctypes.FilterProtocol8021AD).
+		WithPriority(PrioFromBaseAndProtcol(basePrio, tctypes.FilterProtocol8021AD)).
+		WithMatchKeyVlanEthType(tctypes.FlowerVlanEthType8021Q).
+		WithMatchKeyCVlanEthType(tctypes.ProtoToFlowerVlanEthType(proto)).
+		WithAction(action)
+	withMatches(doubleTagged)
+
+	return []tctypes.Filter{singleTagged.Build(), doubleTagged.Build()}
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"math"
+	"net"
+	"sort"
+	"strings"
+
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// TemplateChainBase is the first template chain. template chains are allocated sequentially starting from this chain.
+// Note(adrianc): port chains (see PortChainBase) are expected to be lower than this chain.
+const TemplateChainBase uint32 = 10000
+
+// prioNextTemplateChain is the priority of the goto next template chain filter in template chains. it is
+// evaluated after all other filters in the chain.
+const prioNextTemplateChain uint16 = math.MaxUint16
+
+// templateChain holds filters which match on the same mask
+type templateChain struct {
+	// key identifies the mask of the chain filters
+	key      string
+	template *tctypes.ChainTemplate
+	filters  []tctypes.Filter
+}
+
+// genTemplateChains moves the provided filters in entryChain to template chains, each template chain holds filters
+// which match on the same mask and is declared with a chain template of that mask. template chains are evaluated
+// one after the other (in the order of the filters priority), as follows:
+//  1. goto first template chain filter for all traffic at entryChain, priority of the first filter
+//  2. filters which match on the template mask at template chain, priority of the filter
+//  3. goto next template chain filter for all traffic at template chain, priority 65535
+//
+// a filter is placed in the first template chain of its mask which is not evaluated before filters with
+// lower priority that may match the same traffic. filters are returned along with the template chains.
+func genTemplateChains(filters []tctypes.Filter, entryChain uint32) ([]tctypes.Chain, []tctypes.Filter) {
+	res := make([]tctypes.Filter, 0, len(filters))
+	var grouped []*tctypes.FlowerFilter
+	for _, f := range filters {
+		if filterChain(f) != entryChain {
+			res = append(res, f)
+			continue
+		}
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if !ok {
+			// only flower filter masks can be grouped
+			return nil, filters
+		}
+		grouped = append(grouped, flowerFilter)
+	}
+	if len(grouped) == 0 {
+		return nil, filters
+	}
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return *grouped[i].Attrs().Priority < *grouped[j].Attrs().Priority
+	})
+
+	var templateChains []*templateChain
+	for _, f := range grouped {
+		template := maskTemplate(f)
+		key := maskKey(template)
+
+		// filter must be evaluated after the filters it may overlap with
+		first := 0
+		for idx, c := range templateChains {
+			for _, other := range c.filters {
+				if protocolsOverlap(f.Attrs().Protocol, other.Attrs().Protocol) {
+					first = idx
+					break
+				}
+			}
+		}
+
+		var target *templateChain
+		for _, c := range templateChains[first:] {
+			if c.key == key {
+				target = c
+				break
+			}
+		}
+		if target == nil {
+			target = &templateChain{key: key, template: template}
+			templateChains = append(templateChains, target)
+		}
+		target.filters = append(target.filters, f)
+	}
+
+	chains := make([]tctypes.Chain, 0, len(templateChains))
+	res = append(res, tctypes.NewFlowerFilterBuilder().
+		WithChain(entryChain).
+		WithProtocol(tctypes.FilterProtocolAll).
+		WithPriority(*grouped[0].Attrs().Priority).
+		WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(TemplateChainBase).Build()).
+		Build())
+	for idx, c := range templateChains {
+		chain := TemplateChainBase + uint32(idx)
+		chains = append(chains, tctypes.NewChainBuilder().
+			WithChain(chain).
+			WithTemplate(c.template.Protocol, c.template.Flower).
+			Build())
+
+		for _, f := range c.filters {
+			chain := chain
+			f.Attrs().Chain = &chain
+			res = append(res, f)
+		}
+		if idx == len(templateChains)-1 {
+			// classification ends at the last template chain as it would have at entryChain
+			break
+		}
+		res = append(res, tctypes.NewFlowerFilterBuilder().
+			WithChain(chain).
+			WithProtocol(tctypes.FilterProtocolAll).
+			WithPriority(prioNextTemplateChain).
+			WithAction(tctypes.NewGenericActionBuiler().WithGotoChain(chain+1).Build()).
+			Build())
+	}
+
+	return chains, res
+}
+
+// maskTemplate returns the chain template of the mask filter matches on. template match keys values are zeroed,
+// except for keys which are matched as a set of flags (or are prerequisite of other keys) which are kept.
+func maskTemplate(filter *tctypes.FlowerFilter) *tctypes.ChainTemplate {
+	template := &tctypes.ChainTemplate{Protocol: filter.Attrs().Protocol, Flower: &tctypes.FlowerSpec{}}
+	spec := filter.Flower
+	if spec == nil {
+		return template
+	}
+
+	var zero16 uint16
+	var zero8 uint8
+	t := template.Flower
+	if spec.VlanID != nil {
+		t.VlanID = &zero16
+	}
+	t.VlanEthType = spec.VlanEthType
+	if spec.CVlanID != nil {
+		t.CVlanID = &zero16
+	}
+	t.CVlanEthType = spec.CVlanEthType
+	if spec.SrcMAC != nil {
+		t.SrcMAC = make(net.HardwareAddr, len(spec.SrcMAC))
+	}
+	if spec.DstMAC != nil {
+		t.DstMAC = &tctypes.FlowerMAC{Addr: make(net.HardwareAddr, len(spec.DstMAC.Addr)), Mask: spec.DstMAC.Mask}
+	}
+	t.IPProto = spec.IPProto
+	t.SrcIP = zeroIPNet(spec.SrcIP)
+	t.DstIP = zeroIPNet(spec.DstIP)
+	t.ArpSIP = zeroIPNet(spec.ArpSIP)
+	if spec.SrcPort != nil {
+		t.SrcPort = &zero16
+	}
+	if spec.DstPort != nil {
+		t.DstPort = &zero16
+	}
+	t.IPFlags = spec.IPFlags
+	if spec.TCPFlags != nil {
+		t.TCPFlags = &tctypes.FlowerTCPFlags{Mask: spec.TCPFlags.Mask}
+	}
+	if spec.ICMPType != nil {
+		t.ICMPType = &zero8
+	}
+	t.CtState = spec.CtState
+
+	return template
+}
+
+// maskKey returns a key which identifies the mask and protocol of template. keys which are prerequisite of other
+// keys are matched with a full mask regardless of their value. filters of different protocols (e.g ipv4 and ipv6)
+// are not grouped in the same template chain as the chain template is declared with the protocol.
+func maskKey(template *tctypes.ChainTemplate) string {
+	masked := tctypes.ChainTemplate{Protocol: template.Protocol, Flower: &tctypes.FlowerSpec{}}
+	*masked.Flower = *template.Flower
+	anyIPProto := tctypes.FlowerIPProto("*")
+	anyVlanEthType := tctypes.FlowerVlanEthType("*")
+	if masked.Flower.IPProto != nil {
+		masked.Flower.IPProto = &anyIPProto
+	}
+	if masked.Flower.VlanEthType != nil {
+		masked.Flower.VlanEthType = &anyVlanEthType
+	}
+	if masked.Flower.CVlanEthType != nil {
+		masked.Flower.CVlanEthType = &anyVlanEthType
+	}
+	return strings.Join(masked.GenCmdLineArgs(), " ")
+}
+
+// zeroIPNet returns the zero address network with the mask of ipNet, nil is returned if ipNet is nil
+func zeroIPNet(ipNet *net.IPNet) *net.IPNet {
+	if ipNet == nil {
+		return nil
+	}
+	if len(ipNet.Mask) == net.IPv4len {
+		return &net.IPNet{IP: net.IPv4zero.To4(), Mask: ipNet.Mask}
+	}
+	return &net.IPNet{IP: net.IPv6zero, Mask: ipNet.Mask}
+}
+
+// protocolsOverlap returns true if filters of the provided protocols may match the same traffic
+func protocolsOverlap(first, second tctypes.FilterProtocol) bool {
+	return first == second || first == tctypes.FilterProtocolAll || second == tctypes.FilterProtocolAll
+}
+
+// filterChain returns the chain of filter
+func filterChain(filter tctypes.Filter) uint32 {
+	if filter.Attrs().Chain == nil {
+		return tctypes.ChainDefaultChain
+	}
+	return *filter.Attrs().Chain
+}
@@ -0,0 +1,197 @@
+package generator
+
+import (
+	"hash/fnv"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+const (
+	// u32HashTableDivisor is the number of buckets of u32 hash tables, a bucket per value of the last byte of
+	// destination IP
+	u32HashTableDivisor uint32 = 256
+	// u32HashKeyMask is the mask of the last byte of destination IP in the last 32 bit word of the address
+	u32HashKeyMask uint32 = 0xff
+	// maxU32HashTable is the highest u32 hash table ID which is allocated by U32TCGenerator.
+	// Note(adrianc): higher IDs are allocated by the kernel for the root hash table of each u32 priority
+	maxU32HashTable uint32 = 0x7ff
+)
+
+// NewU32TCGenerator creates a new U32TCGenerator instance
+func NewU32TCGenerator(opts Options) *U32TCGenerator {
+	// Note(adrianc): u32 filters cannot be added to chains declared with a flower chain template
+	opts.ChainTemplates = false
+	return &U32TCGenerator{*NewSimpleTCGenerator(opts)}
+}
+
+// U32TCGenerator is an implementation for Generator interface which uses u32 hash tables to match on large sets of
+// destination addresses. it is intended for software datapath, where classification of flower filters is linear
+// in the number of filters.
+// Filters are generated as in SimpleTCGenerator, then flower filters which match only on an ipv4 (or ipv6)
+// destination address are replaced (per chain and priority) by the following u32 filters:
+//  1. hash table with 256 buckets, at the flower filters priority. other flower filters at that priority (e.g with
+//     ports) are moved to slot + 4 (ipv4) or + 5 (ipv6), all protocols and 802.1ad filters of the slot are moved to
+//     slot + 6 and + 7 to remain after them
+//  2. filter per destination address with the flower filter actions, in the hash table bucket of the last byte
+//     of the address
+//  3. link filter for all traffic of the priority to the hash table bucket of the last byte of destination address
+type U32TCGenerator struct {
+	SimpleTCGenerator
+}
+
+// GenerateFromPolicyRuleSet implements Generator interface
+func (u *U32TCGenerator) GenerateFromPolicyRuleSet(ruleSet policyrules.PolicyRuleSet) (*Objects, error) {
+	objs, err := u.SimpleTCGenerator.GenerateFromPolicyRuleSet(ruleSet)
+	if err != nil {
+		return nil, err
+	}
+	objs.Filters = genU32HashFilters(objs.Filters)
+	return objs, nil
+}
+
+// u32HashGroup holds flower filters which are replaced by the u32 filters of a hash table
+type u32HashGroup struct {
+	chain   uint32
+	prio    uint16
+	proto   tctypes.FilterProtocol
+	filters []*tctypes.FlowerFilter
+}
+
+// genU32HashFilters replaces flower filters which match only on a destination address with u32 hash table filters.
+// filters at the same chain and priority are replaced by the filters of a single hash table at their priority, hence
+// the hash table is evaluated before filters of other protocols in the same slot as the replaced filters were.
+// other flower filters at that chain and priority are moved to a priority which is not used by flower filters in
+// the same slot, as filters of a priority must be of the same kind. all protocols and 802.1ad filters of the slot
+// are moved after them, keeping the order of filters which may match the same traffic (see u32SlotPrioOffsets).
+// other filters are returned as is.
+func genU32HashFilters(filters []tctypes.Filter) []tctypes.Filter {
+	res := make([]tctypes.Filter, 0, len(filters))
+	var groups []*u32HashGroup
+	groupIdx := make(map[[2]uint32]int)
+	for _, f := range filters {
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if !ok || !isU32HashFilter(flowerFilter) {
+			res = append(res, f)
+			continue
+		}
+
+		key := [2]uint32{filterChain(f), uint32(*f.Attrs().Priority)}
+		idx, ok := groupIdx[key]
+		if !ok {
+			idx = len(groups)
+			groupIdx[key] = idx
+			groups = append(groups, &u32HashGroup{
+				chain: key[0], prio: *f.Attrs().Priority, proto: f.Attrs().Protocol})
+		}
+		groups[idx].filters = append(groups[idx].filters, flowerFilter)
+	}
+
+	slots := make(map[[2]uint32]struct{}, len(groups))
+	for _, g := range groups {
+		slots[[2]uint32{g.chain, uint32(g.prio - protoToPrioOffset[g.proto])}] = struct{}{}
+	}
+	for _, f := range res {
+		if prio, ok := u32SlotPrio(f, slots, groupIdx); ok {
+			f.Attrs().Priority = &prio
+		}
+	}
+
+	usedHashTables := make(map[uint32]struct{}, len(groups))
+	for _, g := range groups {
+		// hash key is the last 32 bit word of destination IP
+		hashKeyOffset := tctypes.U32OffsetIPv4DstIP
+		if g.proto == tctypes.FilterProtocolIPv6 {
+			hashKeyOffset = tctypes.U32OffsetIPv6DstIP + 12
+		}
+		hashTable := allocU32HashTable(g.chain, g.prio, usedHashTables)
+
+		res = append(res, tctypes.NewU32FilterBuilder().
+			WithProtocol(g.proto).
+			WithChain(g.chain).
+			WithPriority(g.prio).
+			WithDivisor(hashTable, u32HashTableDivisor).
+			Build())
+		for _, f := range g.filters {
+			dstIP := f.Flower.DstIP.IP.To4()
+			if dstIP == nil {
+				dstIP = f.Flower.DstIP.IP.To16()
+			}
+			ub := tctypes.NewU32FilterBuilder().
+				WithProtocol(g.proto).
+				WithChain(g.chain).
+				WithPriority(g.prio).
+				WithHashTable(hashTable, uint32(dstIP[len(dstIP)-1])).
+				WithMatchDstIP(f.Flower.DstIP)
+			for _, a := range f.Actions {
+				ub.WithAction(a)
+			}
+			res = append(res, ub.Build())
+		}
+		res = append(res, tctypes.NewU32FilterBuilder().
+			WithProtocol(g.proto).
+			WithChain(g.chain).
+			WithPriority(g.prio).
+			WithMatch(0, 0, 0).
+			WithHashKey(u32HashKeyMask, hashKeyOffset).
+			WithLink(hashTable).
+			Build())
+	}
+
+	return res
+}
+
+// u32SlotPrio returns the priority of filter in a slot with u32 hash table filters and true if filter is moved.
+// ipv4 and ipv6 flower filters are moved if they share their priority with u32 hash table filters, all protocols
+// and 802.1ad filters are moved if their slot has u32 hash table filters.
+func u32SlotPrio(f tctypes.Filter, slots map[[2]uint32]struct{}, groupIdx map[[2]uint32]int) (uint16, bool) {
+	offset, ok := u32SlotPrioOffsets[f.Attrs().Protocol]
+	if !ok || f.Attrs().Priority == nil || *f.Attrs().Priority < protoToPrioOffset[f.Attrs().Protocol] {
+		return 0, false
+	}
+	prio := *f.Attrs().Priority
+	slot := prio - protoToPrioOffset[f.Attrs().Protocol]
+	if _, ok := slots[[2]uint32{filterChain(f), uint32(slot)}]; !ok {
+		return 0, false
+	}
+	if f.Attrs().Protocol == tctypes.FilterProtocolIPv4 || f.Attrs().Protocol == tctypes.FilterProtocolIPv6 {
+		if _, ok := groupIdx[[2]uint32{filterChain(f), uint32(prio)}]; !ok {
+			return 0, false
+		}
+	}
+	return slot + offset, true
+}
+
+// isU32HashFilter returns true if filter is an ipv4 or ipv6 flower filter which matches only on a destination address
+func isU32HashFilter(filter *tctypes.FlowerFilter) bool {
+	if filter.SkipHW || filter.Flower == nil || filter.Flower.DstIP == nil || filter.Attrs().Priority == nil {
+		return false
+	}
+	if filter.Protocol != tctypes.FilterProtocolIPv4 && filter.Protocol != tctypes.FilterProtocolIPv6 {
+		return false
+	}
+	if ones, bits := filter.Flower.DstIP.Mask.Size(); ones != bits || bits == 0 {
+		return false
+	}
+
+	spec := *filter.Flower
+	spec.DstIP = nil
+	return spec.Equals(&tctypes.FlowerSpec{})
+}
+
+// allocU32HashTable allocates a hash table ID for the hash table of chain and prio. the ID is derived from chain
+// and prio to keep it stable across generations, IDs in use are skipped. the allocated ID is added to used.
+func allocU32HashTable(chain uint32, prio uint16, used map[uint32]struct{}) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte{byte(chain >> 24), byte(chain >> 16), byte(chain >> 8), byte(chain),
+		byte(prio >> 8), byte(prio)})
+	id := h.Sum32()%maxU32HashTable + 1
+	for {
+		if _, ok := used[id]; !ok {
+			break
+		}
+		id = id%maxU32HashTable + 1
+	}
+	used[id] = struct{}{}
+	return id
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	tctypes "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+// withVlanIDs restricts the provided tagged (802.1Q and 802.1ad) filters with pass action to the given VLAN IDs.
+// each such filter is replaced by a copy per VLAN ID which matches on the (outer) VLAN ID. other filters
+// are returned as is. if no VLAN IDs are provided, filters are returned as is.
+func withVlanIDs(filters []tctypes.Filter, vlanIDs []uint16) []tctypes.Filter {
+	if len(vlanIDs) == 0 {
+		return filters
+	}
+
+	pass := tctypes.NewGenericActionBuiler().WithPass().Build()
+	res := make([]tctypes.Filter, 0, len(filters))
+	for _, f := range filters {
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if !ok || !isTaggedProtocol(flowerFilter.Protocol) || !hasAction(flowerFilter, pass) {
+			res = append(res, f)
+			continue
+		}
+
+		for _, vlanID := range vlanIDs {
+			vlanID := vlanID
+			// copy the whole filter to keep all its fields (e.g SkipHW)
+			vlanFilter := *flowerFilter
+			flower := *flowerFilter.Flower
+			flower.VlanID = &vlanID
+			vlanFilter.Flower = &flower
+			res = append(res, &vlanFilter)
+		}
+	}
+	return res
+}
+
+// withoutTaggedFilters returns the provided filters without filters matching tagged (802.1Q and 802.1ad) traffic
+// of a specific inner protocol. filters matching all tagged traffic are kept.
+func withoutTaggedFilters(filters []tctypes.Filter) []tctypes.Filter {
+	res := make([]tctypes.Filter, 0, len(filters))
+	for _, f := range filters {
+		flowerFilter, ok := f.(*tctypes.FlowerFilter)
+		if ok && isTaggedProtocol(flowerFilter.Protocol) && flowerFilter.Flower != nil &&
+			flowerFilter.Flower.VlanEthType != nil {
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// isTaggedProtocol returns true if proto is 802.1Q or 802.1ad
+func isTaggedProtocol(proto tctypes.FilterProtocol) bool {
+	return proto == tctypes.FilterProtocol8021Q || proto == tctypes.FilterProtocol8021AD
+}
+
+// hasAction returns true if filter has the given action
+func hasAction(filter *tctypes.FlowerFilter, action tctypes.Action) bool {
+	for _, a := range filter.Actions {
+		if a.Equals(action) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	generator "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
 	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
 )
 
 // Actuator is an autogenerated mock type for the Actuator type
@@ -26,6 +28,29 @@ func (_m *Actuator) Actuate(objects *generator.Objects) error {
 	return r0
 }
 
+// FilterList provides a mock function with given fields:
+func (_m *Actuator) FilterList() ([]types.Filter, error) {
+	ret := _m.Called()
+
+	var r0 []types.Filter
+	if rf, ok := ret.Get(0).(func() []types.Filter); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Filter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewActuator interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,173 @@
+package tc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+const (
+	// rules file lines (see ActuatorFileWriterImpl)
+	rulesQDiscPrefix    = "qdisc:"
+	rulesNilQDisc       = "<nil>"
+	rulesChainsSection  = "chains:"
+	rulesFiltersSection = "filters:"
+
+	// tc objects of tc batch commands
+	tcObjectQDisc  = "qdisc"
+	tcObjectChain  = "chain"
+	tcObjectFilter = "filter"
+)
+
+// tcOptionsWithValue maps tc options which are followed by a value to their shortest abbreviation accepted by tc
+var tcOptionsWithValue = map[string]string{"-netns": "-n", "-batch": "-b", "-conf": "-conf", "-cf": "-cf"}
+
+// rulesSectionObjects maps rules file sections to the tc object of their lines
+var rulesSectionObjects = map[string]string{rulesChainsSection: tcObjectChain, rulesFiltersSection: tcObjectFilter}
+
+// ReadRulesFile reads TC objects from file at path, see ReadRules
+func ReadRulesFile(path string) (*generator.Objects, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	objs, err := ReadRules(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rules file: %s", path)
+	}
+	return objs, nil
+}
+
+// ReadRules reads TC objects from r. r contains either rules as saved by ActuatorFileWriterImpl or tc batch
+// commands (e.g "filter add dev eth0 ingress protocol ip pref 100 flower ..."), one per line. tc commands may be
+// prefixed with "tc", their device is ignored. empty lines and lines starting with '#' are ignored.
+// Note(adrianc): returned objects can be compared with generated objects (see FilterSet) or actuated.
+func ReadRules(r io.Reader) (*generator.Objects, error) {
+	objs := &generator.Objects{Filters: make([]types.Filter, 0)}
+	var section string
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+
+		var err error
+		switch args[0] {
+		case rulesQDiscPrefix:
+			if len(args) != 2 || args[1] != rulesNilQDisc {
+				objs.QDisc, err = types.ParseQDiscArgs(args[1:])
+			}
+		case rulesChainsSection, rulesFiltersSection:
+			section = args[0]
+		default:
+			err = readRulesLine(objs, section, args)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse line %d", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read rules")
+	}
+
+	return objs, nil
+}
+
+// readRulesLine parses args of a tc batch command or of a line in rules file section and adds the parsed
+// object to objs
+func readRulesLine(objs *generator.Objects, section string, args []string) error {
+	object, objArgs, err := tcCommandArgs(args)
+	if err != nil {
+		return err
+	}
+	if object == "" {
+		// not a tc command, object is determined by rules file section
+		object, objArgs = rulesSectionObjects[section], args
+	}
+
+	switch object {
+	case tcObjectQDisc:
+		objs.QDisc, err = types.ParseQDiscArgs(objArgs)
+	case tcObjectChain:
+		var chain types.Chain
+		if chain, err = types.ParseChainArgs(objArgs); err == nil {
+			objs.Chains = append(objs.Chains, chain)
+		}
+	case tcObjectFilter:
+		var filter types.Filter
+		if filter, err = types.ParseFilterArgs(objArgs); err == nil {
+			objs.Filters = append(objs.Filters, filter)
+		}
+	default:
+		err = fmt.Errorf("unexpected line outside of %q or %q sections", rulesChainsSection, rulesFiltersSection)
+	}
+	return err
+}
+
+// tcCommandArgs returns the object (qdisc, chain or filter) of tc command args and its args without device.
+// it returns an empty object if args are not a tc command. only add and replace commands are supported.
+func tcCommandArgs(args []string) (string, []string, error) {
+	if len(args) > 0 && args[0] == "tc" {
+		args = args[1:]
+	}
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		// tc options, values of options which take one are skipped
+		switch tcOptionWithValue(args[0]) {
+		case "":
+			args = args[1:]
+			continue
+		case "-batch":
+			return "", nil, fmt.Errorf("unsupported tc option: %q", args[0])
+		}
+		if len(args) < 2 {
+			return "", nil, fmt.Errorf("missing value of tc option: %q", args[0])
+		}
+		args = args[2:]
+	}
+
+	if len(args) < 2 || (args[0] != tcObjectQDisc && args[0] != tcObjectChain && args[0] != tcObjectFilter) {
+		return "", nil, nil
+	}
+	switch args[1] {
+	case "add", "replace":
+	case "change", "delete", "del", "show", "list", "get":
+		return "", nil, fmt.Errorf("unsupported tc %s command: %q", args[0], args[1])
+	default:
+		// a rules file line (e.g "chain 10000 ...")
+		return "", nil, nil
+	}
+
+	objArgs := make([]string, 0, len(args))
+	for i := 2; i < len(args); i++ {
+		if args[i] == "dev" {
+			// skip device
+			i++
+			continue
+		}
+		objArgs = append(objArgs, args[i])
+	}
+	return args[0], objArgs, nil
+}
+
+// tcOptionWithValue returns the tc option which takes a value that opt stands for, or an empty string if opt does not
+// take a value. as in tc, opt may be abbreviated (e.g "-net" for "-netns") and prefixed with "--".
+func tcOptionWithValue(opt string) string {
+	opt = "-" + strings.TrimLeft(opt, "-")
+	for option, abbrev := range tcOptionsWithValue {
+		if strings.HasPrefix(opt, abbrev) && strings.HasPrefix(option, opt) {
+			return option
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package tc_test
+
+import (
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/generator"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+)
+
+var _ = Describe("Rules reader tests", func() {
+	filterSetOf := func(filters []types.Filter) tc.FilterSet {
+		fs := tc.NewFilterSetImpl()
+		for _, f := range filters {
+			fs.Add(f)
+		}
+		return fs
+	}
+
+	It("reads rules saved by actuator file writer", func() {
+		dstIP, err := utils.IPToIPNet("10.0.0.1")
+		Expect(err).ToNot(HaveOccurred())
+		objs := &generator.Objects{
+			QDisc: types.NewClsactQDiscBuilder().Build(),
+			Chains: []types.Chain{types.NewChainBuilder().WithChain(generator.TemplateChainBase).
+				WithTemplate(types.FilterProtocolIPv4, &types.FlowerSpec{}).Build()},
+			Filters: []types.Filter{
+				types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(100).
+					WithChain(generator.TemplateChainBase).
+					WithMatchKeyDstIP(dstIP).
+					WithAction(types.NewGenericAction(types.ActionGenericPass)).
+					Build(),
+				types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithParent(types.ParentEgress).
+					WithDivisor(0x100, 256).
+					Build(),
+			},
+		}
+		path := filepath.Join(GinkgoT().TempDir(), "tc.rules")
+		Expect(tc.NewActuatorFileWriterImpl(path, klog.NewKlogr()).Actuate(objs)).To(Succeed())
+
+		read, err := tc.ReadRulesFile(path)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read.QDisc.Type()).To(Equal(types.QDiscClsactType))
+		Expect(read.Chains).To(HaveLen(1))
+		Expect(read.Chains[0].GenCmdLineArgs()).To(Equal(objs.Chains[0].GenCmdLineArgs()))
+		Expect(filterSetOf(read.Filters).Equals(filterSetOf(objs.Filters))).To(BeTrue())
+	})
+
+	It("reads tc batch commands", func() {
+		rules := `# hand-written rules
+tc qdisc add dev eth0 clsact
+chain add dev eth0 egress chain 10000 protocol ip flower dst_ip 0.0.0.0/24
+
+tc -force filter add dev eth0 egress protocol ip pref 100 chain 10000 flower dst_ip 10.0.0.0/24 action drop
+`
+		read, err := tc.ReadRules(strings.NewReader(rules))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read.QDisc.Type()).To(Equal(types.QDiscClsactType))
+		Expect(read.Chains).To(HaveLen(1))
+		Expect(*read.Chains[0].Attrs().Parent).To(Equal(types.ParentEgress))
+		Expect(read.Filters).To(HaveLen(1))
+		Expect(types.HookOf(read.Filters[0].Attrs().Parent)).To(Equal(types.ParentEgress))
+		Expect(*read.Filters[0].Attrs().Chain).To(Equal(uint32(10000)))
+	})
+
+	It("skips values of tc options", func() {
+		rules := `tc -netns ns1 -s qdisc add dev eth0 clsact
+tc -n ns1 -cf /etc/iproute2 --force filter add dev eth0 egress protocol ip pref 100 flower action drop
+`
+		read, err := tc.ReadRules(strings.NewReader(rules))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read.QDisc.Type()).To(Equal(types.QDiscClsactType))
+		Expect(read.Filters).To(HaveLen(1))
+		Expect(*read.Filters[0].Attrs().Priority).To(Equal(uint16(100)))
+	})
+
+	It("reads rules file without qdisc", func() {
+		read, err := tc.ReadRules(strings.NewReader("qdisc: <nil>\nfilters:\n"))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read.QDisc).To(BeNil())
+		Expect(read.Filters).To(BeEmpty())
+	})
+
+	It("fails on invalid lines", func() {
+		for _, rules := range []string{
+			"protocol ip pref 100 flower\n",
+			"filters:\nprotocol ip pref 100 flower enc_key_id 1\n",
+			"tc filter del dev eth0 ingress pref 100\n",
+			"qdisc: mq\n",
+			"tc -batch rules.batch\n",
+			"tc -net\n",
+		} {
+			_, err := tc.ReadRules(strings.NewReader(rules))
+			Expect(err).To(HaveOccurred(), rules)
+		}
+	})
+})
@@ -1,12 +1,44 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 const (
 	// Action type
-	ActionTypeGeneric ActionType = "gact"
+	ActionTypeGeneric   ActionType = "gact"
+	ActionTypeConnTrack ActionType = "ct"
+	ActionTypePedit     ActionType = "pedit"
+	ActionTypeCsum      ActionType = "csum"
+	ActionTypePolice    ActionType = "police"
 
 	// Generic control actions
 	ActionGenericPass ActionGenericType = "pass"
 	ActionGenericDrop ActionGenericType = "drop"
+	ActionGenericGoto ActionGenericType = "goto"
+
+	// Connection tracking actions
+	ActionConnTrackCommit ActionConnTrackType = "commit"
+
+	// Packet edit header types
+	PeditHeaderIPv4 PeditHeaderType = "ip"
+	PeditHeaderIPv6 PeditHeaderType = "ip6"
+
+	// Checksum update types
+	CsumUpdateIPv4Header CsumUpdateType = "iph"
+
+	// Police control actions
+	PoliceControlPass     PoliceControlType = "pass"
+	PoliceControlDrop     PoliceControlType = "drop"
+	PoliceControlPipe     PoliceControlType = "pipe"
+	PoliceControlContinue PoliceControlType = "continue"
+
+	// DSCPMax is the maximal DSCP value
+	DSCPMax uint8 = 0x3f
+	// dscpShift is the offset of DSCP in ipv4 TOS and ipv6 traffic class
+	dscpShift = 2
 )
 
 // ActionType is the TC Action type
@@ -15,6 +47,18 @@ type ActionType string
 // ActionGenericType is the Generic Action control action type
 type ActionGenericType string
 
+// ActionConnTrackType is the Connection Tracking Action type
+type ActionConnTrackType string
+
+// PeditHeaderType is the header type edited by Packet Edit Action
+type PeditHeaderType string
+
+// CsumUpdateType is the type of checksum updated by Checksum Action
+type CsumUpdateType string
+
+// PoliceControlType is the control action of Police Action for conforming or exceeding traffic
+type PoliceControlType string
+
 // Action is an interface which represents a TC action
 type Action interface {
 	// Type returns the action type
@@ -33,9 +77,16 @@ func NewGenericAction(controlAction ActionGenericType) *GenericAction {
 	return &GenericAction{controlAction: controlAction}
 }
 
+// NewGenericGotoAction creates a new GenericAction with ActionGenericGoto control action to the given chain
+func NewGenericGotoAction(chain uint32) *GenericAction {
+	return &GenericAction{controlAction: ActionGenericGoto, chain: chain}
+}
+
 // GenericAction is a struct representing TC generic action (gact)
 type GenericAction struct {
 	controlAction ActionGenericType
+	// chain is only valid if controlAction is ActionGenericGoto
+	chain uint32
 }
 
 // Type implements Action interface, it returns the type of the action
@@ -47,6 +98,9 @@ func (a *GenericAction) Type() ActionType {
 func (a *GenericAction) Spec() map[string]string {
 	m := make(map[string]string)
 	m["control_action"] = string(a.controlAction)
+	if a.controlAction == ActionGenericGoto {
+		m["chain"] = strconv.FormatUint(uint64(a.chain), 10)
+	}
 	return m
 }
 
@@ -59,12 +113,211 @@ func (a *GenericAction) Equals(other Action) bool {
 	if a.controlAction != otherGenericAction.controlAction {
 		return false
 	}
+	if a.controlAction == ActionGenericGoto && a.chain != otherGenericAction.chain {
+		return false
+	}
 	return true
 }
 
 // GenCmdLineArgs implements CmdLineGenerator interface
 func (a *GenericAction) GenCmdLineArgs() []string {
-	return []string{"action", string(ActionTypeGeneric), string(a.controlAction)}
+	args := []string{"action", string(ActionTypeGeneric), string(a.controlAction)}
+	if a.controlAction == ActionGenericGoto {
+		args = append(args, "chain", strconv.FormatUint(uint64(a.chain), 10))
+	}
+	return args
+}
+
+// NewConnTrackAction creates a new ConnTrackAction, if commit is set connection is committed to the
+// connection tracking table in the given zone
+func NewConnTrackAction(commit bool, zone uint16) *ConnTrackAction {
+	return &ConnTrackAction{commit: commit, zone: zone}
+}
+
+// ConnTrackAction is a struct representing TC connection tracking action (ct).
+// packet is sent through connection tracking and classification continues (pipe) with the next action.
+type ConnTrackAction struct {
+	commit bool
+	zone   uint16
+}
+
+// Type implements Action interface, it returns the type of the action
+func (a *ConnTrackAction) Type() ActionType {
+	return ActionTypeConnTrack
+}
+
+// Spec implements Action interface, it returns the specification of the action
+func (a *ConnTrackAction) Spec() map[string]string {
+	m := make(map[string]string)
+	if a.commit {
+		m["ct_action"] = string(ActionConnTrackCommit)
+	}
+	m["zone"] = strconv.FormatUint(uint64(a.zone), 10)
+	return m
+}
+
+// Equals implements Action interface, it returns true if this and other Action are equal
+func (a *ConnTrackAction) Equals(other Action) bool {
+	otherConnTrackAction, ok := other.(*ConnTrackAction)
+	if !ok {
+		return false
+	}
+	return *a == *otherConnTrackAction
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface
+func (a *ConnTrackAction) GenCmdLineArgs() []string {
+	args := []string{"action", string(ActionTypeConnTrack)}
+	if a.commit {
+		args = append(args, string(ActionConnTrackCommit))
+	}
+	if a.zone != 0 {
+		args = append(args, "zone", strconv.FormatUint(uint64(a.zone), 10))
+	}
+	return append(args, "pipe")
+}
+
+// NewPeditDSCPAction creates a new PeditDSCPAction which sets DSCP of ipv4 (PeditHeaderIPv4)
+// or ipv6 (PeditHeaderIPv6) header to the given value
+func NewPeditDSCPAction(header PeditHeaderType, dscp uint8) *PeditDSCPAction {
+	return &PeditDSCPAction{header: header, dscp: dscp}
+}
+
+// PeditDSCPAction is a struct representing TC packet edit action (pedit) which sets the DSCP of ipv4 or ipv6 header.
+// ECN bits are retained and classification continues (pipe) with the next action.
+// Note: ipv4 header checksum is not updated (see CsumAction)
+type PeditDSCPAction struct {
+	header PeditHeaderType
+	dscp   uint8
+}
+
+// Type implements Action interface, it returns the type of the action
+func (a *PeditDSCPAction) Type() ActionType {
+	return ActionTypePedit
+}
+
+// Spec implements Action interface, it returns the specification of the action
+func (a *PeditDSCPAction) Spec() map[string]string {
+	m := make(map[string]string)
+	m["header"] = string(a.header)
+	m["dscp"] = strconv.FormatUint(uint64(a.dscp), 10)
+	return m
+}
+
+// Equals implements Action interface, it returns true if this and other Action are equal
+func (a *PeditDSCPAction) Equals(other Action) bool {
+	otherPeditAction, ok := other.(*PeditDSCPAction)
+	if !ok {
+		return false
+	}
+	return *a == *otherPeditAction
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface
+func (a *PeditDSCPAction) GenCmdLineArgs() []string {
+	field := "tos"
+	if a.header == PeditHeaderIPv6 {
+		field = "traffic_class"
+	}
+	return []string{"action", string(ActionTypePedit), "ex", "munge", string(a.header), field,
+		"set", fmt.Sprintf("0x%x", a.dscp<<dscpShift), "retain", fmt.Sprintf("0x%x", DSCPMax<<dscpShift), "pipe"}
+}
+
+// NewCsumAction creates a new CsumAction which updates the given checksum types
+func NewCsumAction(updates ...CsumUpdateType) *CsumAction {
+	return &CsumAction{updates: updates}
+}
+
+// CsumAction is a struct representing TC checksum action (csum). checksums are updated
+// and classification continues (pipe) with the next action.
+type CsumAction struct {
+	updates []CsumUpdateType
+}
+
+// Type implements Action interface, it returns the type of the action
+func (a *CsumAction) Type() ActionType {
+	return ActionTypeCsum
+}
+
+// Spec implements Action interface, it returns the specification of the action
+func (a *CsumAction) Spec() map[string]string {
+	m := make(map[string]string)
+	m["csum"] = a.updatesString()
+	return m
+}
+
+// Equals implements Action interface, it returns true if this and other Action are equal
+func (a *CsumAction) Equals(other Action) bool {
+	otherCsumAction, ok := other.(*CsumAction)
+	if !ok {
+		return false
+	}
+	return a.updatesString() == otherCsumAction.updatesString()
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface
+func (a *CsumAction) GenCmdLineArgs() []string {
+	args := []string{"action", string(ActionTypeCsum)}
+	for _, u := range a.updates {
+		args = append(args, string(u))
+	}
+	return append(args, "pipe")
+}
+
+// updatesString returns the string representation of CsumAction checksum update types
+func (a *CsumAction) updatesString() string {
+	updates := make([]string, 0, len(a.updates))
+	for _, u := range a.updates {
+		updates = append(updates, string(u))
+	}
+	return strings.Join(updates, " ")
+}
+
+// NewPoliceAction creates a new PoliceAction which limits traffic to the given rate (in bytes per second)
+// and burst (in bytes). conform and exceed are the control actions for traffic that conforms to or exceeds the limit.
+func NewPoliceAction(rate uint64, burst uint32, conform, exceed PoliceControlType) *PoliceAction {
+	return &PoliceAction{rate: rate, burst: burst, conform: conform, exceed: exceed}
+}
+
+// PoliceAction is a struct representing TC police action (police)
+type PoliceAction struct {
+	// rate in bytes per second
+	rate uint64
+	// burst in bytes
+	burst   uint32
+	conform PoliceControlType
+	exceed  PoliceControlType
+}
+
+// Type implements Action interface, it returns the type of the action
+func (a *PoliceAction) Type() ActionType {
+	return ActionTypePolice
+}
+
+// Spec implements Action interface, it returns the specification of the action
+func (a *PoliceAction) Spec() map[string]string {
+	m := make(map[string]string)
+	m["rate"] = strconv.FormatUint(a.rate, 10)
+	m["burst"] = strconv.FormatUint(uint64(a.burst), 10)
+	m["conform"] = string(a.conform)
+	m["exceed"] = string(a.exceed)
+	return m
+}
+
+// Equals implements Action interface, it returns true if this and other Action are equal
+func (a *PoliceAction) Equals(other Action) bool {
+	otherPoliceAction, ok := other.(*PoliceAction)
+	if !ok {
+		return false
+	}
+	return *a == *otherPoliceAction
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface
+func (a *PoliceAction) GenCmdLineArgs() []string {
+	return []string{"action", string(ActionTypePolice),
+		"rate", strconv.FormatUint(a.rate*8, 10) + "bit", "burst", strconv.FormatUint(uint64(a.burst), 10),
+		"conform-exceed", string(a.exceed) + "/" + string(a.conform)}
 }
 
 // Builer
@@ -91,7 +344,104 @@ func (gb *GenericActionBuilder) WithPass() *GenericActionBuilder {
 	return gb
 }
 
+// WithGotoChain adds ActionGenericGoto control action with the given chain to GenericActionBuilder
+func (gb *GenericActionBuilder) WithGotoChain(chain uint32) *GenericActionBuilder {
+	gb.genericAction.controlAction = ActionGenericGoto
+	gb.genericAction.chain = chain
+	return gb
+}
+
 // Build builds and returns a new GenericAction instance
 func (gb *GenericActionBuilder) Build() *GenericAction {
-	return NewGenericAction(gb.genericAction.controlAction)
+	return &GenericAction{controlAction: gb.genericAction.controlAction, chain: gb.genericAction.chain}
+}
+
+// NewConnTrackActionBuilder creates a new ConnTrackActionBuilder
+func NewConnTrackActionBuilder() *ConnTrackActionBuilder {
+	return &ConnTrackActionBuilder{}
+}
+
+// ConnTrackActionBuilder is a ConnTrackAction builder
+type ConnTrackActionBuilder struct {
+	connTrackAction ConnTrackAction
+}
+
+// WithCommit adds commit to ConnTrackActionBuilder
+func (cb *ConnTrackActionBuilder) WithCommit() *ConnTrackActionBuilder {
+	cb.connTrackAction.commit = true
+	return cb
+}
+
+// WithZone adds connection tracking zone to ConnTrackActionBuilder
+func (cb *ConnTrackActionBuilder) WithZone(zone uint16) *ConnTrackActionBuilder {
+	cb.connTrackAction.zone = zone
+	return cb
+}
+
+// Build builds and returns a new ConnTrackAction instance
+func (cb *ConnTrackActionBuilder) Build() *ConnTrackAction {
+	return NewConnTrackAction(cb.connTrackAction.commit, cb.connTrackAction.zone)
+}
+
+// NewPeditDSCPActionBuilder creates a new PeditDSCPActionBuilder
+func NewPeditDSCPActionBuilder() *PeditDSCPActionBuilder {
+	return &PeditDSCPActionBuilder{peditAction: PeditDSCPAction{header: PeditHeaderIPv4}}
+}
+
+// PeditDSCPActionBuilder is a PeditDSCPAction builder
+type PeditDSCPActionBuilder struct {
+	peditAction PeditDSCPAction
+}
+
+// WithHeader adds header type to PeditDSCPActionBuilder
+func (pb *PeditDSCPActionBuilder) WithHeader(header PeditHeaderType) *PeditDSCPActionBuilder {
+	pb.peditAction.header = header
+	return pb
+}
+
+// WithDSCP adds DSCP value to PeditDSCPActionBuilder
+func (pb *PeditDSCPActionBuilder) WithDSCP(dscp uint8) *PeditDSCPActionBuilder {
+	pb.peditAction.dscp = dscp
+	return pb
+}
+
+// Build builds and returns a new PeditDSCPAction instance
+func (pb *PeditDSCPActionBuilder) Build() *PeditDSCPAction {
+	return NewPeditDSCPAction(pb.peditAction.header, pb.peditAction.dscp)
+}
+
+// NewPoliceActionBuilder creates a new PoliceActionBuilder. by default, conforming traffic is passed and
+// exceeding traffic is dropped
+func NewPoliceActionBuilder() *PoliceActionBuilder {
+	return &PoliceActionBuilder{policeAction: PoliceAction{conform: PoliceControlPass, exceed: PoliceControlDrop}}
+}
+
+// PoliceActionBuilder is a PoliceAction builder
+type PoliceActionBuilder struct {
+	policeAction PoliceAction
+}
+
+// WithRate adds rate (in bytes per second) to PoliceActionBuilder
+func (pb *PoliceActionBuilder) WithRate(rate uint64) *PoliceActionBuilder {
+	pb.policeAction.rate = rate
+	return pb
+}
+
+// WithBurst adds burst (in bytes) to PoliceActionBuilder
+func (pb *PoliceActionBuilder) WithBurst(burst uint32) *PoliceActionBuilder {
+	pb.policeAction.burst = burst
+	return pb
+}
+
+// WithConformExceed adds control actions for conforming and exceeding traffic to PoliceActionBuilder
+func (pb *PoliceActionBuilder) WithConformExceed(conform, exceed PoliceControlType) *PoliceActionBuilder {
+	pb.policeAction.conform = conform
+	pb.policeAction.exceed = exceed
+	return pb
+}
+
+// Build builds and returns a new PoliceAction instance
+func (pb *PoliceActionBuilder) Build() *PoliceAction {
+	return NewPoliceAction(pb.policeAction.rate, pb.policeAction.burst, pb.policeAction.conform,
+		pb.policeAction.exceed)
 }
@@ -24,6 +24,12 @@ var _ = Describe("Action tests", func() {
 				Expect(ga.Spec()).To(HaveKey("control_action"))
 				Expect(ga.Spec()["control_action"]).To(BeEquivalentTo(types.ActionGenericPass))
 			})
+
+			It("Builds goto GenericAction with correct attributes", func() {
+				ga := types.NewGenericActionBuiler().WithGotoChain(1).Build()
+				Expect(ga.Spec()).To(Equal(map[string]string{"control_action": "goto", "chain": "1"}))
+				Expect(ga.Equals(types.NewGenericGotoAction(1))).To(BeTrue())
+			})
 		})
 	})
 
@@ -53,6 +59,11 @@ var _ = Describe("Action tests", func() {
 				ga2 := types.NewGenericActionBuiler().WithDrop().Build()
 				Expect(ga.Equals(ga2)).To(BeFalse())
 			})
+
+			It("returns false if goto Actions have different chains", func() {
+				Expect(types.NewGenericGotoAction(1).Equals(types.NewGenericGotoAction(2))).To(BeFalse())
+				Expect(types.NewGenericGotoAction(1).Equals(types.NewGenericGotoAction(1))).To(BeTrue())
+			})
 		})
 
 		Context("CmdLineGenerator", func() {
@@ -60,6 +71,127 @@ var _ = Describe("Action tests", func() {
 				expectedArgs := []string{"action", "gact", "pass"}
 				Expect(ga.GenCmdLineArgs()).To(Equal(expectedArgs))
 			})
+
+			It("generates expected command line args - goto chain", func() {
+				expectedArgs := []string{"action", "gact", "goto", "chain", "1"}
+				Expect(types.NewGenericGotoAction(1).GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+	})
+
+	Describe("ConnTrackAction", func() {
+		Context("ConnTrackActionBuilder", func() {
+			It("Builds ConnTrackAction with correct attributes", func() {
+				ct := types.NewConnTrackActionBuilder().WithCommit().WithZone(5).Build()
+				Expect(ct.Type()).To(Equal(types.ActionTypeConnTrack))
+				Expect(ct.Spec()).To(Equal(map[string]string{"ct_action": "commit", "zone": "5"}))
+				Expect(ct.Equals(types.NewConnTrackAction(true, 5))).To(BeTrue())
+			})
+		})
+
+		Context("Equals()", func() {
+			It("returns false if Actions are not equal", func() {
+				ct := types.NewConnTrackActionBuilder().Build()
+				Expect(ct.Equals(types.NewConnTrackActionBuilder().WithCommit().Build())).To(BeFalse())
+				Expect(ct.Equals(types.NewConnTrackActionBuilder().WithZone(1).Build())).To(BeFalse())
+				Expect(ct.Equals(types.NewGenericActionBuiler().WithPass().Build())).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				Expect(types.NewConnTrackActionBuilder().Build().GenCmdLineArgs()).To(Equal(
+					[]string{"action", "ct", "pipe"}))
+				Expect(types.NewConnTrackActionBuilder().WithCommit().WithZone(5).Build().GenCmdLineArgs()).To(Equal(
+					[]string{"action", "ct", "commit", "zone", "5", "pipe"}))
+			})
+		})
+	})
+
+	Describe("PeditDSCPAction", func() {
+		Context("PeditDSCPActionBuilder", func() {
+			It("Builds PeditDSCPAction with correct attributes", func() {
+				pa := types.NewPeditDSCPActionBuilder().WithHeader(types.PeditHeaderIPv6).WithDSCP(46).Build()
+				Expect(pa.Type()).To(Equal(types.ActionTypePedit))
+				Expect(pa.Spec()).To(Equal(map[string]string{"header": "ip6", "dscp": "46"}))
+				Expect(pa.Equals(types.NewPeditDSCPAction(types.PeditHeaderIPv6, 46))).To(BeTrue())
+			})
+		})
+
+		Context("Equals()", func() {
+			It("returns false if Actions are not equal", func() {
+				pa := types.NewPeditDSCPAction(types.PeditHeaderIPv4, 46)
+				Expect(pa.Equals(types.NewPeditDSCPAction(types.PeditHeaderIPv6, 46))).To(BeFalse())
+				Expect(pa.Equals(types.NewPeditDSCPAction(types.PeditHeaderIPv4, 10))).To(BeFalse())
+				Expect(pa.Equals(types.NewGenericActionBuiler().WithPass().Build())).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				Expect(types.NewPeditDSCPAction(types.PeditHeaderIPv4, 46).GenCmdLineArgs()).To(Equal(
+					[]string{"action", "pedit", "ex", "munge", "ip", "tos", "set", "0xb8", "retain", "0xfc", "pipe"}))
+				Expect(types.NewPeditDSCPAction(types.PeditHeaderIPv6, 10).GenCmdLineArgs()).To(Equal(
+					[]string{"action", "pedit", "ex", "munge", "ip6", "traffic_class", "set", "0x28", "retain", "0xfc",
+						"pipe"}))
+			})
+		})
+	})
+
+	Describe("CsumAction", func() {
+		It("has expected attributes", func() {
+			ca := types.NewCsumAction(types.CsumUpdateIPv4Header)
+			Expect(ca.Type()).To(Equal(types.ActionTypeCsum))
+			Expect(ca.Spec()).To(Equal(map[string]string{"csum": "iph"}))
+			Expect(ca.Equals(types.NewCsumAction(types.CsumUpdateIPv4Header))).To(BeTrue())
+			Expect(ca.Equals(types.NewCsumAction())).To(BeFalse())
+			Expect(ca.Equals(types.NewGenericActionBuiler().WithPass().Build())).To(BeFalse())
+		})
+
+		It("generates expected command line args", func() {
+			Expect(types.NewCsumAction(types.CsumUpdateIPv4Header).GenCmdLineArgs()).To(Equal(
+				[]string{"action", "csum", "iph", "pipe"}))
+		})
+	})
+
+	Describe("PoliceAction", func() {
+		Context("PoliceActionBuilder", func() {
+			It("Builds PoliceAction with correct attributes", func() {
+				pa := types.NewPoliceActionBuilder().WithRate(1250000).WithBurst(65536).
+					WithConformExceed(types.PoliceControlPipe, types.PoliceControlDrop).Build()
+				Expect(pa.Type()).To(Equal(types.ActionTypePolice))
+				Expect(pa.Spec()).To(Equal(map[string]string{
+					"rate": "1250000", "burst": "65536", "conform": "pipe", "exceed": "drop"}))
+				Expect(pa.Equals(types.NewPoliceAction(1250000, 65536, types.PoliceControlPipe,
+					types.PoliceControlDrop))).To(BeTrue())
+			})
+
+			It("Builds PoliceAction with default control actions", func() {
+				pa := types.NewPoliceActionBuilder().WithRate(1250000).WithBurst(65536).Build()
+				Expect(pa.Equals(types.NewPoliceAction(1250000, 65536, types.PoliceControlPass,
+					types.PoliceControlDrop))).To(BeTrue())
+			})
+		})
+
+		Context("Equals()", func() {
+			It("returns false if Actions are not equal", func() {
+				pa := types.NewPoliceAction(1250000, 65536, types.PoliceControlPipe, types.PoliceControlDrop)
+				Expect(pa.Equals(types.NewPoliceAction(125000, 65536, types.PoliceControlPipe,
+					types.PoliceControlDrop))).To(BeFalse())
+				Expect(pa.Equals(types.NewPoliceAction(1250000, 6553, types.PoliceControlPipe,
+					types.PoliceControlDrop))).To(BeFalse())
+				Expect(pa.Equals(types.NewPoliceAction(1250000, 65536, types.PoliceControlPass,
+					types.PoliceControlDrop))).To(BeFalse())
+				Expect(pa.Equals(types.NewGenericActionBuiler().WithDrop().Build())).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				Expect(types.NewPoliceAction(1250000, 65536, types.PoliceControlPipe, types.PoliceControlDrop).
+					GenCmdLineArgs()).To(Equal([]string{"action", "police", "rate", "10000000bit", "burst", "65536",
+					"conform-exceed", "drop/pipe"}))
+			})
 		})
 	})
 })
@@ -25,6 +25,44 @@ type Chain interface {
 type ChainAttrs struct {
 	Parent *uint32
 	Chain  *uint32
+	// Template is the (optional) filter template of the chain
+	Template *ChainTemplate
+}
+
+// ChainTemplate is a flower filter template of a chain. flower filters added to a chain with a template must match
+// on a subset of the template match keys masks (values of template match keys are ignored).
+type ChainTemplate struct {
+	Protocol FilterProtocol
+	Flower   *FlowerSpec
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface
+func (ct *ChainTemplate) GenCmdLineArgs() []string {
+	args := []string{}
+
+	if ct == nil {
+		return args
+	}
+
+	if ct.Protocol != "" {
+		args = append(args, "protocol", string(ct.Protocol))
+	}
+	args = append(args, string(FilterKindFlower))
+	args = append(args, ct.Flower.GenCmdLineArgs()...)
+	return args
+}
+
+// Equals compares this ChainTemplate with other, returns true if they are equal or false otherwise
+func (ct *ChainTemplate) Equals(other *ChainTemplate) bool {
+	if ct == other {
+		return true
+	}
+
+	if ct == nil || other == nil {
+		return false
+	}
+
+	return ct.Protocol == other.Protocol && ct.Flower.Equals(other.Flower)
 }
 
 // ChainImpl is a concrete implementation of Chain
@@ -49,9 +87,13 @@ func (c *ChainImpl) GenCmdLineArgs() []string {
 	if c.Chain != nil {
 		args = append(args, "chain", strconv.FormatUint(uint64(*c.Chain), 10))
 	}
+
+	// must be last as next are template specific params
+	args = append(args, c.Template.GenCmdLineArgs()...)
 	return args
 }
 
+// NewChainImpl creates a new ChainImpl
 func NewChainImpl(parent *uint32, chain *uint32) *ChainImpl {
 	return &ChainImpl{ChainAttrs{
 		Parent: parent,
@@ -83,6 +125,12 @@ func (cb *ChainBuilder) WithChain(chain uint32) *ChainBuilder {
 	return cb
 }
 
+// WithTemplate adds Chain flower filter template with the given protocol and flower spec to ChainBuilder
+func (cb *ChainBuilder) WithTemplate(protocol FilterProtocol, flower *FlowerSpec) *ChainBuilder {
+	cb.chain.Template = &ChainTemplate{Protocol: protocol, Flower: flower}
+	return cb
+}
+
 // Build builds and returns a new Chain instance
 // Note: calling Build() multiple times will not return a completely
 // new object on each call. that is, pointer/slice/map types will not be deep copied.
@@ -92,5 +140,7 @@ func (cb *ChainBuilder) Build() *ChainImpl {
 		defChain := ChainDefaultChain
 		cb.chain.Chain = &defChain
 	}
-	return NewChainImpl(cb.chain.Parent, cb.chain.Chain)
+	c := NewChainImpl(cb.chain.Parent, cb.chain.Chain)
+	c.Template = cb.chain.Template
+	return c
 }
@@ -1,6 +1,8 @@
 package types_test
 
 import (
+	"net"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -52,4 +54,37 @@ var _ = Describe("Chain tests", func() {
 			})
 		})
 	})
+
+	Describe("Chain Template", func() {
+		_, dstIP, _ := net.ParseCIDR("0.0.0.0/24")
+		flower := types.NewFlowerFilterBuilder().
+			WithMatchKeyDstIP(dstIP).
+			WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+			WithMatchKeyDstPort(0).
+			Build().Flower
+		c := types.NewChainBuilder().WithParent(parent).WithChain(10000).
+			WithTemplate(types.FilterProtocolIPv4, flower).Build()
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				expectedArgs := []string{"parent", "ffff:fff1", "chain", "10000", "protocol", "ip", "flower",
+					"ip_proto", "tcp", "dst_ip", "0.0.0.0/24", "dst_port", "0"}
+				Expect(c.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+
+		Context("Equals", func() {
+			It("returns true for equal templates", func() {
+				other := &types.ChainTemplate{Protocol: types.FilterProtocolIPv4, Flower: flower}
+				Expect(c.Template.Equals(other)).To(BeTrue())
+			})
+
+			It("returns false for different templates", func() {
+				Expect(c.Template.Equals(nil)).To(BeFalse())
+				Expect(c.Template.Equals(&types.ChainTemplate{Protocol: types.FilterProtocolIPv6, Flower: flower})).
+					To(BeFalse())
+				Expect(c.Template.Equals(&types.ChainTemplate{Protocol: types.FilterProtocolIPv4})).To(BeFalse())
+			})
+		})
+	})
 })
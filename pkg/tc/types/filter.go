@@ -1,35 +1,85 @@
 package types
 
 import (
+	"encoding/binary"
+	"fmt"
 	"net"
 	"strconv"
-
-	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
+	"strings"
 )
 
 const (
 	// Values for FilterAttrs.Protocol
-	FilterProtocolAll   FilterProtocol = "all"
-	FilterProtocolIPv4  FilterProtocol = "ip"
-	FilterProtocolIPv6  FilterProtocol = "ipv6"
-	FilterProtocol8021Q FilterProtocol = "802.1q"
+	FilterProtocolAll    FilterProtocol = "all"
+	FilterProtocolIPv4   FilterProtocol = "ip"
+	FilterProtocolIPv6   FilterProtocol = "ipv6"
+	FilterProtocol8021Q  FilterProtocol = "802.1q"
+	FilterProtocol8021AD FilterProtocol = "802.1ad"
+	FilterProtocolARP    FilterProtocol = "arp"
 
 	// FlowerFilter.Kind
 	FilterKindFlower FilterKind = "flower"
+	// U32Filter.Kind
+	FilterKindU32 FilterKind = "u32"
+	// MatchAllFilter.Kind
+	FilterKindMatchAll FilterKind = "matchall"
+	// BasicFilter.Kind
+	FilterKindBasic FilterKind = "basic"
 
 	// FlowerKeys
-	FlowerKeyIPProto     FlowerKey = "ip_proto"
-	FlowerKeyDstIP       FlowerKey = "dst_ip"
-	FlowerKeyDstPort     FlowerKey = "dst_port"
-	FlowerKeyVlanEthType FlowerKey = "vlan_ethtype"
+	FlowerKeyIPProto      FlowerKey = "ip_proto"
+	FlowerKeyDstIP        FlowerKey = "dst_ip"
+	FlowerKeySrcIP        FlowerKey = "src_ip"
+	FlowerKeySrcMAC       FlowerKey = "src_mac"
+	FlowerKeyDstMAC       FlowerKey = "dst_mac"
+	FlowerKeyArpSIP       FlowerKey = "arp_sip"
+	FlowerKeySrcPort      FlowerKey = "src_port"
+	FlowerKeyDstPort      FlowerKey = "dst_port"
+	FlowerKeyVlanID       FlowerKey = "vlan_id"
+	FlowerKeyVlanEthType  FlowerKey = "vlan_ethtype"
+	FlowerKeyCVlanID      FlowerKey = "cvlan_id"
+	FlowerKeyCVlanEthType FlowerKey = "cvlan_ethtype"
+	FlowerKeyICMPType     FlowerKey = "type"
+	FlowerKeyCtState      FlowerKey = "ct_state"
+	FlowerKeyTCPFlags     FlowerKey = "tcp_flags"
+	FlowerKeyIPFlags      FlowerKey = "ip_flags"
 
 	// FlowerFilter.Flower.IPProto
-	FlowerIPProtoTCP FlowerIPProto = "tcp"
-	FlowerIPProtoUDP FlowerIPProto = "udp"
+	FlowerIPProtoTCP    FlowerIPProto = "tcp"
+	FlowerIPProtoUDP    FlowerIPProto = "udp"
+	FlowerIPProtoICMP   FlowerIPProto = "icmp"
+	FlowerIPProtoICMPv6 FlowerIPProto = "icmpv6"
+	// Note(adrianc): tc has no name for IGMP protocol, it is provided as a hex number
+	FlowerIPProtoIGMP FlowerIPProto = "0x02"
 
 	// FlowerFilter.Flower.VlanEthType
-	FlowerVlanEthTypeIPv4 FlowerVlanEthType = "ip"
-	FlowerVlanEthTypeIPv6 FlowerVlanEthType = "ipv6"
+	FlowerVlanEthTypeIPv4  FlowerVlanEthType = "ip"
+	FlowerVlanEthTypeIPv6  FlowerVlanEthType = "ipv6"
+	FlowerVlanEthTypeARP   FlowerVlanEthType = "arp"
+	FlowerVlanEthType8021Q FlowerVlanEthType = "802.1q"
+
+	// FlowerFilter.Flower.CtState
+	FlowerCtStateUntracked   FlowerCtState = "-trk"
+	FlowerCtStateNew         FlowerCtState = "+trk+new"
+	FlowerCtStateEstablished FlowerCtState = "+trk+est"
+	FlowerCtStateRelated     FlowerCtState = "+trk+rel"
+	FlowerCtStateInvalid     FlowerCtState = "+trk+inv"
+
+	// FlowerFilter.Flower.IPFlags
+	FlowerIPFlagsFrag        FlowerIPFlags = "frag"
+	FlowerIPFlagsNoFrag      FlowerIPFlags = "nofrag"
+	FlowerIPFlagsFirstFrag   FlowerIPFlags = "frag/firstfrag"
+	FlowerIPFlagsNoFirstFrag FlowerIPFlags = "frag/nofirstfrag"
+
+	// U32Match.Offset of ipv4 and ipv6 destination address
+	U32OffsetIPv4DstIP int32 = 16
+	U32OffsetIPv6DstIP int32 = 24
+
+	// TCP flags for FlowerFilter.Flower.TCPFlags
+	TCPFlagFIN uint16 = 0x1
+	TCPFlagSYN uint16 = 0x2
+	TCPFlagRST uint16 = 0x4
+	TCPFlagACK uint16 = 0x10
 )
 
 // FilterProtocol is the type of filter protocol
@@ -44,15 +94,54 @@ type FlowerKey string
 // FlowerIPProto is the type of IPProto flower key
 type FlowerIPProto string
 
-// FlowerVlanEthType is the type of VlanEthType flower key
+// FlowerVlanEthType is the type of VlanEthType and CVlanEthType flower keys
 type FlowerVlanEthType string
 
+// FlowerIPFlags is the type of IPFlags flower key, it is a "/" separated list of IP flags
+// each optionally prefixed with "no" (e.g "frag/nofirstfrag")
+type FlowerIPFlags string
+
+// FlowerTCPFlags is the type of TCPFlags flower key, TCP flags are matched according to Mask
+type FlowerTCPFlags struct {
+	Flags uint16
+	Mask  uint16
+}
+
+// String returns FlowerTCPFlags string representation in tc format (e.g "0x10/0x10")
+func (tf FlowerTCPFlags) String() string {
+	return fmt.Sprintf("0x%x/0x%x", tf.Flags, tf.Mask)
+}
+
+// FlowerMAC is the type of MAC address flower keys, MAC address is matched according to Mask.
+// a nil Mask matches the entire address
+type FlowerMAC struct {
+	Addr net.HardwareAddr
+	Mask net.HardwareAddr
+}
+
+// String returns FlowerMAC string representation in tc format (e.g "01:00:00:00:00:00/01:00:00:00:00:00").
+// mask is omitted if the entire address is matched
+func (fm FlowerMAC) String() string {
+	for _, b := range fm.Mask {
+		if b != 0xff {
+			return fm.Addr.String() + "/" + fm.Mask.String()
+		}
+	}
+	return fm.Addr.String()
+}
+
+// FlowerCtState is the type of CtState flower key, it is a set of connection tracking state flags
+// each prefixed with +/- (e.g "+trk+est")
+type FlowerCtState string
+
 // Filter represent a tc filter object
 type Filter interface {
 	// Attrs returns FilterAttrs
 	Attrs() *FilterAttrs
 	// Equals compares this Filter with other, returns true if they are equal or false otherwise
 	Equals(other Filter) bool
+	// Key returns a canonical key of this Filter, filters are equal (see Equals) if and only if their keys are equal
+	Key() string
 
 	// Driver Specific related Interfaces
 	CmdLineGenerator
@@ -65,6 +154,9 @@ type FilterAttrs struct {
 	Chain    *uint32
 	Handle   *uint32
 	Priority *uint16
+	// Parent is the hook (ParentIngress or ParentEgress) the filter is attached to, nil is the ingress hook.
+	// it is not part of the command line args as the hook is provided by the TC driver.
+	Parent *uint32
 }
 
 // NewFilterAttrs creates new FilterAttrs instance
@@ -88,7 +180,11 @@ func (fa *FilterAttrs) GenCmdLineArgs() []string {
 	}
 
 	if fa.Handle != nil {
-		args = append(args, "handle", strconv.FormatUint(uint64(*fa.Handle), 10))
+		if fa.Kind == FilterKindU32 {
+			args = append(args, "handle", U32HandleString(*fa.Handle))
+		} else {
+			args = append(args, "handle", strconv.FormatUint(uint64(*fa.Handle), 10))
+		}
 	}
 
 	if fa.Chain != nil {
@@ -128,15 +224,47 @@ func (fa *FilterAttrs) Equals(other *FilterAttrs) bool {
 	if !compare(fa.Priority, other.Priority, nil) {
 		return false
 	}
+	if HookOf(fa.Parent) != HookOf(other.Parent) {
+		return false
+	}
 	return true
 }
 
+// Key returns a canonical key of this FilterAttrs, it normalizes attributes as Equals does
+// (i.e nil chain is the default chain, handle is ignored)
+func (fa *FilterAttrs) Key() string {
+	chain := ChainDefaultChain
+	if fa.Chain != nil {
+		chain = *fa.Chain
+	}
+	return fmt.Sprintf("%q,%q,%d,%s,%x", fa.Kind, fa.Protocol, chain, ptrKey(fa.Priority), HookOf(fa.Parent))
+}
+
 // FlowerSpec holds flower filter specification (which consists of a list of Match)
 type FlowerSpec struct {
+	// VlanID is only valid if filter protocol is FilterProtocol8021Q or FilterProtocol8021AD
+	VlanID      *uint16
 	VlanEthType *FlowerVlanEthType
-	IPProto     *FlowerIPProto
-	DstIP       *net.IPNet
-	DstPort     *uint16
+	// CVlanID and CVlanEthType are only valid if VlanEthType is FlowerVlanEthType8021Q
+	CVlanID      *uint16
+	CVlanEthType *FlowerVlanEthType
+	SrcMAC       net.HardwareAddr
+	DstMAC       *FlowerMAC
+	IPProto      *FlowerIPProto
+	SrcIP        *net.IPNet
+	DstIP        *net.IPNet
+	// ArpSIP is only valid if filter protocol (or VlanEthType) is ARP
+	ArpSIP  *net.IPNet
+	SrcPort *uint16
+	DstPort *uint16
+	// IPFlags is only valid for ipv4 and ipv6 traffic
+	IPFlags *FlowerIPFlags
+	// TCPFlags is only valid if IPProto is FlowerIPProtoTCP
+	TCPFlags *FlowerTCPFlags
+	// ICMPType is only valid if IPProto is FlowerIPProtoICMP or FlowerIPProtoICMPv6
+	ICMPType *uint8
+	// CtState is the connection tracking state, only valid for connection tracked traffic (see ConnTrackAction)
+	CtState *FlowerCtState
 }
 
 // GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for FlowerSpec
@@ -147,26 +275,70 @@ func (ff *FlowerSpec) GenCmdLineArgs() []string {
 		return args
 	}
 
+	if ff.VlanID != nil {
+		args = append(args, string(FlowerKeyVlanID), strconv.FormatUint(uint64(*ff.VlanID), 10))
+	}
+
 	if ff.VlanEthType != nil {
 		args = append(args, string(FlowerKeyVlanEthType), string(*ff.VlanEthType))
 	}
 
+	if ff.CVlanID != nil {
+		args = append(args, string(FlowerKeyCVlanID), strconv.FormatUint(uint64(*ff.CVlanID), 10))
+	}
+
+	if ff.CVlanEthType != nil {
+		args = append(args, string(FlowerKeyCVlanEthType), string(*ff.CVlanEthType))
+	}
+
+	if ff.SrcMAC != nil {
+		args = append(args, string(FlowerKeySrcMAC), ff.SrcMAC.String())
+	}
+
+	if ff.DstMAC != nil {
+		args = append(args, string(FlowerKeyDstMAC), ff.DstMAC.String())
+	}
+
 	if ff.IPProto != nil {
 		args = append(args, string(FlowerKeyIPProto), string(*ff.IPProto))
 	}
 
+	if ff.SrcIP != nil {
+		args = append(args, string(FlowerKeySrcIP), ipNetCmdLineArg(ff.SrcIP))
+	}
+
 	if ff.DstIP != nil {
-		if ff.DstIP.Mask != nil && !utils.IsMaskFull(ff.DstIP.Mask) {
-			args = append(args, string(FlowerKeyDstIP), ff.DstIP.String())
-		} else {
-			args = append(args, string(FlowerKeyDstIP), ff.DstIP.IP.String())
-		}
+		args = append(args, string(FlowerKeyDstIP), ipNetCmdLineArg(ff.DstIP))
+	}
+
+	if ff.ArpSIP != nil {
+		args = append(args, string(FlowerKeyArpSIP), ipNetCmdLineArg(ff.ArpSIP))
+	}
+
+	if ff.SrcPort != nil {
+		args = append(args, string(FlowerKeySrcPort), strconv.FormatUint(uint64(*ff.SrcPort), 10))
 	}
 
 	if ff.DstPort != nil {
 		args = append(args, string(FlowerKeyDstPort), strconv.FormatUint(uint64(*ff.DstPort), 10))
 	}
 
+	if ff.IPFlags != nil {
+		args = append(args, string(FlowerKeyIPFlags), string(*ff.IPFlags))
+	}
+
+	if ff.TCPFlags != nil {
+		args = append(args, string(FlowerKeyTCPFlags), ff.TCPFlags.String())
+	}
+
+	if ff.ICMPType != nil {
+		args = append(args, string(FlowerKeyICMPType), strconv.FormatUint(uint64(*ff.ICMPType), 10))
+	}
+
+	if ff.CtState != nil {
+		args = append(args, string(FlowerKeyCtState), string(*ff.CtState))
+	}
+
 	return args
 }
 
@@ -181,39 +353,79 @@ func (ff *FlowerSpec) Equals(other *FlowerSpec) bool {
 	}
 
 	// same Key/val
+	if !compare(ff.VlanID, other.VlanID, nil) {
+		return false
+	}
 	if !compare(ff.VlanEthType, other.VlanEthType, nil) {
 		return false
 	}
+	if !compare(ff.CVlanID, other.CVlanID, nil) {
+		return false
+	}
+	if !compare(ff.CVlanEthType, other.CVlanEthType, nil) {
+		return false
+	}
+	if ff.SrcMAC.String() != other.SrcMAC.String() {
+		return false
+	}
+	if !flowerMACEquals(ff.DstMAC, other.DstMAC) {
+		return false
+	}
 	if !compare(ff.IPProto, other.IPProto, nil) {
 		return false
 	}
-	if ff.DstIP != other.DstIP {
-		if ff.DstIP != nil && other.DstIP != nil {
-			// same IP (compare string representation to avoid cases where IP was created with 4 bytes vs 16 bytes)
-			if ff.DstIP.IP.String() != other.DstIP.IP.String() {
-				return false
-			}
-			// same mask
-			if ff.DstIP.Mask.String() != other.DstIP.Mask.String() {
-				return false
-			}
-		} else {
-			// one is nil the other is not
-			return false
-		}
+	if !ipNetEquals(ff.SrcIP, other.SrcIP) {
+		return false
+	}
+	if !ipNetEquals(ff.DstIP, other.DstIP) {
+		return false
+	}
+	if !ipNetEquals(ff.ArpSIP, other.ArpSIP) {
+		return false
+	}
+	if !compare(ff.SrcPort, other.SrcPort, nil) {
+		return false
 	}
 	if !compare(ff.DstPort, other.DstPort, nil) {
 		return false
 	}
+	if !compare(ff.IPFlags, other.IPFlags, nil) {
+		return false
+	}
+	if !compare(ff.TCPFlags, other.TCPFlags, nil) {
+		return false
+	}
+	if !compare(ff.ICMPType, other.ICMPType, nil) {
+		return false
+	}
+	if !compare(ff.CtState, other.CtState, nil) {
+		return false
+	}
 
 	return true
 }
 
+// Key returns a canonical key of this FlowerSpec, FlowerSpecs are equal if and only if their keys are equal
+func (ff *FlowerSpec) Key() string {
+	if ff == nil {
+		return nilKey
+	}
+
+	return strings.Join([]string{
+		ptrKey(ff.VlanID), ptrKey(ff.VlanEthType), ptrKey(ff.CVlanID), ptrKey(ff.CVlanEthType),
+		ff.SrcMAC.String(), flowerMACKey(ff.DstMAC), ptrKey(ff.IPProto),
+		ipNetKey(ff.SrcIP), ipNetKey(ff.DstIP), ipNetKey(ff.ArpSIP), ptrKey(ff.SrcPort), ptrKey(ff.DstPort),
+		ptrKey(ff.IPFlags), ptrKey(ff.TCPFlags), ptrKey(ff.ICMPType), ptrKey(ff.CtState),
+	}, ",")
+}
+
 // FlowerFilter is a concrete implementation of Filter of kind Flower
 type FlowerFilter struct {
 	FilterAttrs
 	// Flower Match keys, only valid if Kind == FilterKindFlower
 	Flower *FlowerSpec
+	// SkipHW if set, the filter is not offloaded to hardware
+	SkipHW bool
 	// Actions
 	Actions []Action
 }
@@ -241,12 +453,135 @@ func (f *FlowerFilter) Equals(other Filter) bool {
 		return false
 	}
 
+	if f.SkipHW != otherFlower.SkipHW {
+		return false
+	}
+
 	// Actions Equal (order matters)
-	if len(f.Actions) != len(otherFlower.Actions) {
+	return actionsEqual(f.Actions, otherFlower.Actions)
+}
+
+// Key implements Filter interface
+func (f *FlowerFilter) Key() string {
+	return strings.Join([]string{string(FilterKindFlower), f.FilterAttrs.Key(), f.Flower.Key(),
+		strconv.FormatBool(f.SkipHW), actionsKey(f.Actions)}, "|")
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for FlowerFilter
+func (f *FlowerFilter) GenCmdLineArgs() []string {
+	args := []string{}
+
+	args = append(args, f.FilterAttrs.GenCmdLineArgs()...)
+
+	if f.SkipHW {
+		args = append(args, "skip_hw")
+	}
+
+	if f.Flower != nil {
+		args = append(args, f.Flower.GenCmdLineArgs()...)
+	}
+
+	for _, action := range f.Actions {
+		args = append(args, action.GenCmdLineArgs()...)
+	}
+
+	return args
+}
+
+// U32Match matches the 32 bit word at Offset (relative to the network header) masked with Mask against Value
+type U32Match struct {
+	Value  uint32
+	Mask   uint32
+	Offset int32
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for U32Match
+func (m U32Match) GenCmdLineArgs() []string {
+	return []string{"match", "u32", fmt.Sprintf("0x%08x", m.Value), fmt.Sprintf("0x%08x", m.Mask),
+		"at", strconv.FormatInt(int64(m.Offset), 10)}
+}
+
+// U32HashKey selects the bucket of a hash table from the 32 bit word at Offset (relative to the network header)
+// masked with Mask
+type U32HashKey struct {
+	Mask   uint32
+	Offset int32
+}
+
+// U32Spec holds u32 filter specification
+type U32Spec struct {
+	// Divisor if non-zero, the filter creates hash table HashTable with Divisor buckets.
+	// such filter has no other keys or actions.
+	Divisor uint32
+	// HashTable is the ID of the hash table the filter is added to (or created in, if Divisor is set),
+	// zero for the root hash table of the filter priority
+	HashTable uint32
+	// Bucket is the bucket of HashTable the filter is added to
+	Bucket uint32
+	// Matches are the filter matches, all must match
+	Matches []U32Match
+	// HashKey selects the bucket of Link hash table, only valid if Link is set
+	HashKey *U32HashKey
+	// Link is the ID of the hash table classification continues at if Matches match, zero if not set
+	Link uint32
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for U32Spec
+// Note: hash table created by the filter is provided as the filter handle (see FilterAttrs)
+func (us *U32Spec) GenCmdLineArgs() []string {
+	args := []string{}
+
+	if us == nil {
+		return args
+	}
+
+	if us.Divisor != 0 {
+		return append(args, "divisor", strconv.FormatUint(uint64(us.Divisor), 10))
+	}
+
+	if us.HashTable != 0 {
+		args = append(args, "ht", fmt.Sprintf("%x:%x:", us.HashTable, us.Bucket))
+	}
+
+	for _, m := range us.Matches {
+		args = append(args, m.GenCmdLineArgs()...)
+	}
+
+	if us.Link != 0 {
+		if us.HashKey != nil {
+			args = append(args, "hashkey", "mask", fmt.Sprintf("0x%08x", us.HashKey.Mask),
+				"at", strconv.FormatInt(int64(us.HashKey.Offset), 10))
+		}
+		args = append(args, "link", fmt.Sprintf("%x:", us.Link))
+	}
+
+	return args
+}
+
+// Equals compares this U32Spec with other, returns true if they are equal or false otherwise
+func (us *U32Spec) Equals(other *U32Spec) bool {
+	if us == other {
+		return true
+	}
+
+	if (us == nil && other != nil) || (us != nil && other == nil) {
+		return false
+	}
+
+	if us.Divisor != other.Divisor || us.HashTable != other.HashTable || us.Bucket != other.Bucket ||
+		us.Link != other.Link {
 		return false
 	}
-	for i := range f.Actions {
-		if !f.Actions[i].Equals(otherFlower.Actions[i]) {
+	if !compare(us.HashKey, other.HashKey, nil) {
+		return false
+	}
+
+	// Matches equal (order matters)
+	if len(us.Matches) != len(other.Matches) {
+		return false
+	}
+	for i := range us.Matches {
+		if us.Matches[i] != other.Matches[i] {
 			return false
 		}
 	}
@@ -254,16 +589,72 @@ func (f *FlowerFilter) Equals(other Filter) bool {
 	return true
 }
 
-// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for FlowerFilter
-func (f *FlowerFilter) GenCmdLineArgs() []string {
+// Key returns a canonical key of this U32Spec, U32Specs are equal if and only if their keys are equal
+func (us *U32Spec) Key() string {
+	if us == nil {
+		return nilKey
+	}
+	return fmt.Sprintf("%d,%d,%d,%d,%s,%v", us.Divisor, us.HashTable, us.Bucket, us.Link, ptrKey(us.HashKey),
+		us.Matches)
+}
+
+// U32Filter is a concrete implementation of Filter of kind u32
+type U32Filter struct {
+	FilterAttrs
+	// U32 specification
+	U32 *U32Spec
+	// SkipHW if set, the filter is not offloaded to hardware
+	SkipHW bool
+	// Actions
+	Actions []Action
+}
+
+// Attrs implements Filter interface, it returns FilterAttrs
+func (f *U32Filter) Attrs() *FilterAttrs {
+	return &f.FilterAttrs
+}
+
+// Equals implements Filter interface
+func (f *U32Filter) Equals(other Filter) bool {
+	otherU32, ok := other.(*U32Filter)
+	if !ok {
+		return false
+	}
+
+	if !f.Attrs().Equals(other.Attrs()) {
+		return false
+	}
+
+	if !f.U32.Equals(otherU32.U32) {
+		return false
+	}
+
+	if f.SkipHW != otherU32.SkipHW {
+		return false
+	}
+
+	// Actions Equal (order matters)
+	return actionsEqual(f.Actions, otherU32.Actions)
+}
+
+// Key implements Filter interface
+func (f *U32Filter) Key() string {
+	return strings.Join([]string{string(FilterKindU32), f.FilterAttrs.Key(), f.U32.Key(),
+		strconv.FormatBool(f.SkipHW), actionsKey(f.Actions)}, "|")
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for U32Filter
+func (f *U32Filter) GenCmdLineArgs() []string {
 	args := []string{}
 
 	args = append(args, f.FilterAttrs.GenCmdLineArgs()...)
 
-	if f.Flower != nil {
-		args = append(args, f.Flower.GenCmdLineArgs()...)
+	if f.SkipHW {
+		args = append(args, "skip_hw")
 	}
 
+	args = append(args, f.U32.GenCmdLineArgs()...)
+
 	for _, action := range f.Actions {
 		args = append(args, action.GenCmdLineArgs()...)
 	}
@@ -271,6 +662,141 @@ func (f *FlowerFilter) GenCmdLineArgs() []string {
 	return args
 }
 
+// U32Handle returns the u32 filter handle of node in bucket of hash table
+func U32Handle(hashTable, bucket, node uint32) uint32 {
+	return (hashTable&0xfff)<<20 | (bucket&0xff)<<12 | node&0xfff
+}
+
+// U32HandleString returns u32 filter handle string representation in tc format (e.g "100:a:800")
+func U32HandleString(handle uint32) string {
+	var s string
+	if hashTable := handle >> 20; hashTable != 0 {
+		s += fmt.Sprintf("%x:", hashTable)
+	}
+	if bucket := (handle >> 12) & 0xff; bucket != 0 {
+		s += fmt.Sprintf("%x", bucket)
+	}
+	if node := handle & 0xfff; node != 0 {
+		s += fmt.Sprintf(":%x", node)
+	}
+	return s
+}
+
+// MatchAllFilter is a concrete implementation of Filter of kind matchall, it matches all traffic of its protocol
+type MatchAllFilter struct {
+	FilterAttrs
+	// SkipHW if set, the filter is not offloaded to hardware
+	SkipHW bool
+	// Actions
+	Actions []Action
+}
+
+// Attrs implements Filter interface, it returns FilterAttrs
+func (f *MatchAllFilter) Attrs() *FilterAttrs {
+	return &f.FilterAttrs
+}
+
+// Equals implements Filter interface
+func (f *MatchAllFilter) Equals(other Filter) bool {
+	otherMatchAll, ok := other.(*MatchAllFilter)
+	if !ok {
+		return false
+	}
+
+	if !f.Attrs().Equals(other.Attrs()) {
+		return false
+	}
+
+	if f.SkipHW != otherMatchAll.SkipHW {
+		return false
+	}
+
+	return actionsEqual(f.Actions, otherMatchAll.Actions)
+}
+
+// Key implements Filter interface
+func (f *MatchAllFilter) Key() string {
+	return strings.Join([]string{string(FilterKindMatchAll), f.FilterAttrs.Key(),
+		strconv.FormatBool(f.SkipHW), actionsKey(f.Actions)}, "|")
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for
+// MatchAllFilter
+func (f *MatchAllFilter) GenCmdLineArgs() []string {
+	args := []string{}
+
+	args = append(args, f.FilterAttrs.GenCmdLineArgs()...)
+
+	if f.SkipHW {
+		args = append(args, "skip_hw")
+	}
+
+	for _, action := range f.Actions {
+		args = append(args, action.GenCmdLineArgs()...)
+	}
+
+	return args
+}
+
+// BasicFilter is a concrete implementation of Filter of kind basic, it matches all traffic of its protocol.
+// basic filters are never offloaded to hardware.
+// Note: extended matches (ematch) are not supported
+type BasicFilter struct {
+	FilterAttrs
+	// Actions
+	Actions []Action
+}
+
+// Attrs implements Filter interface, it returns FilterAttrs
+func (f *BasicFilter) Attrs() *FilterAttrs {
+	return &f.FilterAttrs
+}
+
+// Equals implements Filter interface
+func (f *BasicFilter) Equals(other Filter) bool {
+	otherBasic, ok := other.(*BasicFilter)
+	if !ok {
+		return false
+	}
+
+	if !f.Attrs().Equals(other.Attrs()) {
+		return false
+	}
+
+	return actionsEqual(f.Actions, otherBasic.Actions)
+}
+
+// Key implements Filter interface
+func (f *BasicFilter) Key() string {
+	return strings.Join([]string{string(FilterKindBasic), f.FilterAttrs.Key(), actionsKey(f.Actions)}, "|")
+}
+
+// GenCmdLineArgs implements CmdLineGenerator interface, it generates the needed tc command line args for BasicFilter
+func (f *BasicFilter) GenCmdLineArgs() []string {
+	args := []string{}
+
+	args = append(args, f.FilterAttrs.GenCmdLineArgs()...)
+
+	for _, action := range f.Actions {
+		args = append(args, action.GenCmdLineArgs()...)
+	}
+
+	return args
+}
+
+// actionsEqual returns true if actions and other are equal (order matters)
+func actionsEqual(actions, other []Action) bool {
+	if len(actions) != len(other) {
+		return false
+	}
+	for i := range actions {
+		if !actions[i].Equals(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // Builders
 
 // NewFilterAttrsBuilder returns a new FilterAttrsBuilder
@@ -313,13 +839,21 @@ func (fb *FilterAttrsBuilder) WithPriority(p uint16) *FilterAttrsBuilder {
 	return fb
 }
 
+// WithParent adds Parent (ParentIngress or ParentEgress) to FilterAttrsBuilder
+func (fb *FilterAttrsBuilder) WithParent(p uint32) *FilterAttrsBuilder {
+	fb.filterAttrs.Parent = &p
+	return fb
+}
+
 // Build builds and returns a new FilterAttrs instance
 // Note: calling Build() multiple times will not return a completely
 // new object on each call. that is, pointer/slice/map types will not be deep copied.
 // to create several objects, different builders should be used.
 func (fb *FilterAttrsBuilder) Build() *FilterAttrs {
-	return NewFilterAttrs(fb.filterAttrs.Kind, fb.filterAttrs.Protocol, fb.filterAttrs.Chain, fb.filterAttrs.Handle,
-		fb.filterAttrs.Priority)
+	attrs := NewFilterAttrs(fb.filterAttrs.Kind, fb.filterAttrs.Protocol, fb.filterAttrs.Chain,
+		fb.filterAttrs.Handle, fb.filterAttrs.Priority)
+	attrs.Parent = fb.filterAttrs.Parent
+	return attrs
 }
 
 // NewFlowerFilterBuilder returns a new instance of FlowerFilterBuilder
@@ -369,30 +903,115 @@ func (fb *FlowerFilterBuilder) WithPriority(p uint16) *FlowerFilterBuilder {
 	return fb
 }
 
+// WithParent adds Parent (ParentIngress or ParentEgress) to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithParent(p uint32) *FlowerFilterBuilder {
+	fb.filterAttrsBuilder = fb.filterAttrsBuilder.WithParent(p)
+	return fb
+}
+
+// WithMatchKeyVlanID adds Match with FlowerKeyVlanID key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyVlanID(val uint16) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.VlanID = &val
+	return fb
+}
+
 // WithMatchKeyVlanEthType adds Match with FlowerKeyVlanEthType key and specified value to FlowerFilterBuilder
 func (fb *FlowerFilterBuilder) WithMatchKeyVlanEthType(val FlowerVlanEthType) *FlowerFilterBuilder {
 	fb.flowerFilter.Flower.VlanEthType = &val
 	return fb
 }
 
+// WithMatchKeyCVlanID adds Match with FlowerKeyCVlanID key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyCVlanID(val uint16) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.CVlanID = &val
+	return fb
+}
+
+// WithMatchKeyCVlanEthType adds Match with FlowerKeyCVlanEthType key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyCVlanEthType(val FlowerVlanEthType) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.CVlanEthType = &val
+	return fb
+}
+
 // WithMatchKeyIPProto adds Match with FlowerKeyIPProto key and specified value to FlowerFilterBuilder
 func (fb *FlowerFilterBuilder) WithMatchKeyIPProto(val FlowerIPProto) *FlowerFilterBuilder {
 	fb.flowerFilter.Flower.IPProto = &val
 	return fb
 }
 
+// WithMatchKeySrcMAC adds Match with FlowerKeySrcMAC key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeySrcMAC(mac net.HardwareAddr) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.SrcMAC = mac
+	return fb
+}
+
+// WithMatchKeySrcIP adds Match with FlowerKeySrcIP key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeySrcIP(ipNet *net.IPNet) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.SrcIP = ipNet
+	return fb
+}
+
+// WithMatchKeyArpSIP adds Match with FlowerKeyArpSIP key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyArpSIP(ipNet *net.IPNet) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.ArpSIP = ipNet
+	return fb
+}
+
 // WithMatchKeyDstIP adds Match with FlowerKeyDstIP key and specified value to FlowerFilterBuilder
 func (fb *FlowerFilterBuilder) WithMatchKeyDstIP(ipNet *net.IPNet) *FlowerFilterBuilder {
 	fb.flowerFilter.Flower.DstIP = ipNet
 	return fb
 }
 
+// WithMatchKeySrcPort adds Match with FlowerKeySrcPort key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeySrcPort(val uint16) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.SrcPort = &val
+	return fb
+}
+
 // WithMatchKeyDstPort adds Match with FlowerKeyDstPort key and specified value to FlowerFilterBuilder
 func (fb *FlowerFilterBuilder) WithMatchKeyDstPort(val uint16) *FlowerFilterBuilder {
 	fb.flowerFilter.Flower.DstPort = &val
 	return fb
 }
 
+// WithMatchKeyIPFlags adds Match with FlowerKeyIPFlags key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyIPFlags(val FlowerIPFlags) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.IPFlags = &val
+	return fb
+}
+
+// WithMatchKeyDstMAC adds Match with FlowerKeyDstMAC key and specified value to FlowerFilterBuilder.
+// if mask is nil the entire address is matched
+func (fb *FlowerFilterBuilder) WithMatchKeyDstMAC(mac, mask net.HardwareAddr) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.DstMAC = &FlowerMAC{Addr: mac, Mask: mask}
+	return fb
+}
+
+// WithMatchKeyTCPFlags adds Match with FlowerKeyTCPFlags key and specified flags and mask to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyTCPFlags(flags, mask uint16) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.TCPFlags = &FlowerTCPFlags{Flags: flags, Mask: mask}
+	return fb
+}
+
+// WithMatchKeyICMPType adds Match with FlowerKeyICMPType key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyICMPType(val uint8) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.ICMPType = &val
+	return fb
+}
+
+// WithMatchKeyCtState adds Match with FlowerKeyCtState key and specified value to FlowerFilterBuilder
+func (fb *FlowerFilterBuilder) WithMatchKeyCtState(val FlowerCtState) *FlowerFilterBuilder {
+	fb.flowerFilter.Flower.CtState = &val
+	return fb
+}
+
+// WithSkipHW sets FlowerFilterBuilder to build a filter which is not offloaded to hardware
+func (fb *FlowerFilterBuilder) WithSkipHW() *FlowerFilterBuilder {
+	fb.flowerFilter.SkipHW = true
+	return fb
+}
+
 // WithAction adds specified Action to FlowerFilterBuilder
 func (fb *FlowerFilterBuilder) WithAction(a Action) *FlowerFilterBuilder {
 	fb.flowerFilter.Actions = append(fb.flowerFilter.Actions, a)
@@ -410,6 +1029,274 @@ func (fb *FlowerFilterBuilder) Build() *FlowerFilter {
 	return &FlowerFilter{
 		FilterAttrs: *fb.flowerFilter.Attrs(),
 		Flower:      fb.flowerFilter.Flower,
+		SkipHW:      fb.flowerFilter.SkipHW,
 		Actions:     fb.flowerFilter.Actions,
 	}
 }
+
+// NewU32FilterBuilder returns a new instance of U32FilterBuilder
+func NewU32FilterBuilder() *U32FilterBuilder {
+	return &U32FilterBuilder{
+		filterAttrsBuilder: NewFilterAttrsBuilder(),
+		u32Filter: U32Filter{
+			U32:     &U32Spec{},
+			Actions: make([]Action, 0),
+		},
+	}
+}
+
+// U32FilterBuilder is a U32Filter builder
+type U32FilterBuilder struct {
+	filterAttrsBuilder *FilterAttrsBuilder
+	u32Filter          U32Filter
+}
+
+// WithProtocol adds Protocol to U32FilterBuilder
+func (ub *U32FilterBuilder) WithProtocol(p FilterProtocol) *U32FilterBuilder {
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithProtocol(p)
+	return ub
+}
+
+// WithChain adds Chain number to U32FilterBuilder
+func (ub *U32FilterBuilder) WithChain(c uint32) *U32FilterBuilder {
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithChain(c)
+	return ub
+}
+
+// WithHandle adds Handle to U32FilterBuilder (see U32Handle)
+func (ub *U32FilterBuilder) WithHandle(h uint32) *U32FilterBuilder {
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithHandle(h)
+	return ub
+}
+
+// WithPriority adds Priority to U32FilterBuilder
+func (ub *U32FilterBuilder) WithPriority(p uint16) *U32FilterBuilder {
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithPriority(p)
+	return ub
+}
+
+// WithParent adds Parent (ParentIngress or ParentEgress) to U32FilterBuilder
+func (ub *U32FilterBuilder) WithParent(p uint32) *U32FilterBuilder {
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithParent(p)
+	return ub
+}
+
+// WithDivisor sets U32FilterBuilder to build a filter which creates hashTable with divisor buckets.
+// the filter handle is set to the hash table handle.
+func (ub *U32FilterBuilder) WithDivisor(hashTable, divisor uint32) *U32FilterBuilder {
+	ub.u32Filter.U32.HashTable = hashTable
+	ub.u32Filter.U32.Divisor = divisor
+	ub.filterAttrsBuilder = ub.filterAttrsBuilder.WithHandle(U32Handle(hashTable, 0, 0))
+	return ub
+}
+
+// WithHashTable adds the hash table and bucket the filter is added to, to U32FilterBuilder
+func (ub *U32FilterBuilder) WithHashTable(hashTable, bucket uint32) *U32FilterBuilder {
+	ub.u32Filter.U32.HashTable = hashTable
+	ub.u32Filter.U32.Bucket = bucket
+	return ub
+}
+
+// WithMatch adds U32Match with specified value, mask and offset to U32FilterBuilder
+func (ub *U32FilterBuilder) WithMatch(value, mask uint32, offset int32) *U32FilterBuilder {
+	ub.u32Filter.U32.Matches = append(ub.u32Filter.U32.Matches, U32Match{Value: value, Mask: mask, Offset: offset})
+	return ub
+}
+
+// WithMatchDstIP adds U32Matches on ipv4 or ipv6 destination address of ipNet to U32FilterBuilder
+func (ub *U32FilterBuilder) WithMatchDstIP(ipNet *net.IPNet) *U32FilterBuilder {
+	ip := ipNet.IP.To4()
+	offset := U32OffsetIPv4DstIP
+	if ip == nil {
+		ip = ipNet.IP.To16()
+		offset = U32OffsetIPv6DstIP
+	}
+	mask := net.IP(ipNet.Mask)
+	if len(mask) != len(ip) {
+		mask = net.IP(net.CIDRMask(len(ip)*8, len(ip)*8))
+	}
+	for i := 0; i < len(ip); i += 4 {
+		wordMask := binary.BigEndian.Uint32(mask[i : i+4])
+		if wordMask == 0 {
+			continue
+		}
+		ub.WithMatch(binary.BigEndian.Uint32(ip[i:i+4])&wordMask, wordMask, offset+int32(i))
+	}
+	return ub
+}
+
+// WithHashKey adds HashKey with specified mask and offset to U32FilterBuilder
+func (ub *U32FilterBuilder) WithHashKey(mask uint32, offset int32) *U32FilterBuilder {
+	ub.u32Filter.U32.HashKey = &U32HashKey{Mask: mask, Offset: offset}
+	return ub
+}
+
+// WithLink adds the hash table classification continues at to U32FilterBuilder
+func (ub *U32FilterBuilder) WithLink(hashTable uint32) *U32FilterBuilder {
+	ub.u32Filter.U32.Link = hashTable
+	return ub
+}
+
+// WithSkipHW sets U32FilterBuilder to build a filter which is not offloaded to hardware
+func (ub *U32FilterBuilder) WithSkipHW() *U32FilterBuilder {
+	ub.u32Filter.SkipHW = true
+	return ub
+}
+
+// WithAction adds specified Action to U32FilterBuilder
+func (ub *U32FilterBuilder) WithAction(a Action) *U32FilterBuilder {
+	ub.u32Filter.Actions = append(ub.u32Filter.Actions, a)
+	return ub
+}
+
+// Build builds and creates a new U32Filter instance
+// Note: calling Build() multiple times will not return a completely
+// new object on each call. that is, pointer/slice/map types will not be deep copied.
+// to create several objects, different builders should be used.
+func (ub *U32FilterBuilder) Build() *U32Filter {
+	ub.u32Filter.FilterAttrs = *ub.filterAttrsBuilder.WithKind(FilterKindU32).Build()
+
+	return &U32Filter{
+		FilterAttrs: *ub.u32Filter.Attrs(),
+		U32:         ub.u32Filter.U32,
+		SkipHW:      ub.u32Filter.SkipHW,
+		Actions:     ub.u32Filter.Actions,
+	}
+}
+
+// NewMatchAllFilterBuilder returns a new instance of MatchAllFilterBuilder
+func NewMatchAllFilterBuilder() *MatchAllFilterBuilder {
+	return &MatchAllFilterBuilder{
+		filterAttrsBuilder: NewFilterAttrsBuilder(),
+		matchAllFilter: MatchAllFilter{
+			Actions: make([]Action, 0),
+		},
+	}
+}
+
+// MatchAllFilterBuilder is a MatchAllFilter builder
+type MatchAllFilterBuilder struct {
+	filterAttrsBuilder *FilterAttrsBuilder
+	matchAllFilter     MatchAllFilter
+}
+
+// WithProtocol adds Protocol to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithProtocol(p FilterProtocol) *MatchAllFilterBuilder {
+	mb.filterAttrsBuilder = mb.filterAttrsBuilder.WithProtocol(p)
+	return mb
+}
+
+// WithChain adds Chain number to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithChain(c uint32) *MatchAllFilterBuilder {
+	mb.filterAttrsBuilder = mb.filterAttrsBuilder.WithChain(c)
+	return mb
+}
+
+// WithHandle adds Handle to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithHandle(h uint32) *MatchAllFilterBuilder {
+	mb.filterAttrsBuilder = mb.filterAttrsBuilder.WithHandle(h)
+	return mb
+}
+
+// WithPriority adds Priority to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithPriority(p uint16) *MatchAllFilterBuilder {
+	mb.filterAttrsBuilder = mb.filterAttrsBuilder.WithPriority(p)
+	return mb
+}
+
+// WithParent adds Parent (ParentIngress or ParentEgress) to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithParent(p uint32) *MatchAllFilterBuilder {
+	mb.filterAttrsBuilder = mb.filterAttrsBuilder.WithParent(p)
+	return mb
+}
+
+// WithSkipHW sets MatchAllFilterBuilder to build a filter which is not offloaded to hardware
+func (mb *MatchAllFilterBuilder) WithSkipHW() *MatchAllFilterBuilder {
+	mb.matchAllFilter.SkipHW = true
+	return mb
+}
+
+// WithAction adds specified Action to MatchAllFilterBuilder
+func (mb *MatchAllFilterBuilder) WithAction(a Action) *MatchAllFilterBuilder {
+	mb.matchAllFilter.Actions = append(mb.matchAllFilter.Actions, a)
+	return mb
+}
+
+// Build builds and creates a new MatchAllFilter instance
+// Note: calling Build() multiple times will not return a completely
+// new object on each call. that is, pointer/slice/map types will not be deep copied.
+// to create several objects, different builders should be used.
+func (mb *MatchAllFilterBuilder) Build() *MatchAllFilter {
+	mb.matchAllFilter.FilterAttrs = *mb.filterAttrsBuilder.WithKind(FilterKindMatchAll).Build()
+
+	return &MatchAllFilter{
+		FilterAttrs: *mb.matchAllFilter.Attrs(),
+		SkipHW:      mb.matchAllFilter.SkipHW,
+		Actions:     mb.matchAllFilter.Actions,
+	}
+}
+
+// NewBasicFilterBuilder returns a new instance of BasicFilterBuilder
+func NewBasicFilterBuilder() *BasicFilterBuilder {
+	return &BasicFilterBuilder{
+		filterAttrsBuilder: NewFilterAttrsBuilder(),
+		basicFilter: BasicFilter{
+			Actions: make([]Action, 0),
+		},
+	}
+}
+
+// BasicFilterBuilder is a BasicFilter builder
+type BasicFilterBuilder struct {
+	filterAttrsBuilder *FilterAttrsBuilder
+	basicFilter        BasicFilter
+}
+
+// WithProtocol adds Protocol to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithProtocol(p FilterProtocol) *BasicFilterBuilder {
+	bb.filterAttrsBuilder = bb.filterAttrsBuilder.WithProtocol(p)
+	return bb
+}
+
+// WithChain adds Chain number to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithChain(c uint32) *BasicFilterBuilder {
+	bb.filterAttrsBuilder = bb.filterAttrsBuilder.WithChain(c)
+	return bb
+}
+
+// WithHandle adds Handle to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithHandle(h uint32) *BasicFilterBuilder {
+	bb.filterAttrsBuilder = bb.filterAttrsBuilder.WithHandle(h)
+	return bb
+}
+
+// WithPriority adds Priority to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithPriority(p uint16) *BasicFilterBuilder {
+	bb.filterAttrsBuilder = bb.filterAttrsBuilder.WithPriority(p)
+	return bb
+}
+
+// WithParent adds Parent (ParentIngress or ParentEgress) to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithParent(p uint32) *BasicFilterBuilder {
+	bb.filterAttrsBuilder = bb.filterAttrsBuilder.WithParent(p)
+	return bb
+}
+
+// WithAction adds specified Action to BasicFilterBuilder
+func (bb *BasicFilterBuilder) WithAction(a Action) *BasicFilterBuilder {
+	bb.basicFilter.Actions = append(bb.basicFilter.Actions, a)
+	return bb
+}
+
+// Build builds and creates a new BasicFilter instance
+// Note: calling Build() multiple times will not return a completely
+// new object on each call. that is, pointer/slice/map types will not be deep copied.
+// to create several objects, different builders should be used.
+func (bb *BasicFilterBuilder) Build() *BasicFilter {
+	bb.basicFilter.FilterAttrs = *bb.filterAttrsBuilder.WithKind(FilterKindBasic).Build()
+
+	return &BasicFilter{
+		FilterAttrs: *bb.basicFilter.Attrs(),
+		Actions:     bb.basicFilter.Actions,
+	}
+}
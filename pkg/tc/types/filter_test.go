@@ -13,6 +13,7 @@ import (
 var _ = Describe("Filter tests", func() {
 	ipToIpNet := func(ip string) *net.IPNet { ipn, _ := utils.IPToIPNet(ip); return ipn }
 	passAction := types.NewGenericActionBuiler().WithPass().Build()
+	dropAction := types.NewGenericActionBuiler().WithDrop().Build()
 	testFilterIPv4 := types.NewFlowerFilterBuilder().
 		WithProtocol(types.FilterProtocolIPv4).
 		WithPriority(100).
@@ -148,6 +149,19 @@ var _ = Describe("Filter tests", func() {
 				Expect(filter1.Equals(filter2)).To(BeFalse())
 			})
 
+			It("returns false for filters with/without skip_hw", func() {
+				filter1 := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithAction(passAction).
+					Build()
+				filter2 := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithSkipHW().
+					WithAction(passAction).
+					Build()
+				Expect(filter1.Equals(filter2)).To(BeFalse())
+			})
+
 			It("retuns true for filters with/without /32 mask for ipv4 dest IP", func() {
 				filter1 := types.NewFlowerFilterBuilder().
 					WithProtocol(types.FilterProtocolIPv4).
@@ -241,6 +255,118 @@ var _ = Describe("Filter tests", func() {
 				Expect(filter1.Equals(filter2)).To(BeFalse())
 			})
 
+			It("returns false for filters with different cvlan keys", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().
+						WithProtocol(types.FilterProtocol8021AD).
+						WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q)
+				}
+				Expect(fb().WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv4).Build().Equals(
+					fb().WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv6).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyCVlanID(10).Build().Equals(
+					fb().WithMatchKeyCVlanID(20).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyCVlanID(10).Build().Equals(fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyCVlanID(10).Build().Equals(
+					fb().WithMatchKeyCVlanID(10).Build())).To(BeTrue())
+			})
+
+			It("returns false for filters with different ICMP types", func() {
+				fb := func(icmpType uint8) types.Filter {
+					return types.NewFlowerFilterBuilder().
+						WithProtocol(types.FilterProtocolIPv6).
+						WithMatchKeyIPProto(types.FlowerIPProtoICMPv6).
+						WithMatchKeyICMPType(icmpType).
+						Build()
+				}
+				Expect(fb(133).Equals(fb(134))).To(BeFalse())
+				Expect(fb(133).Equals(fb(133))).To(BeTrue())
+			})
+
+			It("returns false for filters with different source keys", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4)
+				}
+				mac1, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+				mac2, _ := net.ParseMAC("aa:bb:cc:dd:ee:02")
+				Expect(fb().WithMatchKeySrcMAC(mac1).Build().Equals(fb().WithMatchKeySrcMAC(mac2).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeySrcMAC(mac1).Build().Equals(fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeySrcMAC(mac1).Build().Equals(fb().WithMatchKeySrcMAC(mac1).Build())).To(BeTrue())
+				Expect(fb().WithMatchKeySrcIP(ipToIpNet("10.10.10.1/32")).Build().Equals(
+					fb().WithMatchKeySrcIP(ipToIpNet("10.10.10.2/32")).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeySrcIP(ipToIpNet("10.10.10.1/32")).Build().Equals(
+					fb().WithMatchKeyDstIP(ipToIpNet("10.10.10.1/32")).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeySrcIP(ipToIpNet("10.10.10.1/32")).Build().Equals(
+					fb().WithMatchKeySrcIP(ipToIpNet("10.10.10.1")).Build())).To(BeTrue())
+				Expect(fb().WithMatchKeyArpSIP(ipToIpNet("10.10.10.1/32")).Build().Equals(
+					fb().WithMatchKeyArpSIP(ipToIpNet("10.10.10.2/32")).Build())).To(BeFalse())
+			})
+
+			It("returns false for filters with different ct_state", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4)
+				}
+				Expect(fb().WithMatchKeyCtState(types.FlowerCtStateEstablished).Build().Equals(
+					fb().WithMatchKeyCtState(types.FlowerCtStateNew).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyCtState(types.FlowerCtStateEstablished).Build().Equals(
+					fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyCtState(types.FlowerCtStateEstablished).Build().Equals(
+					fb().WithMatchKeyCtState(types.FlowerCtStateEstablished).Build())).To(BeTrue())
+			})
+
+			It("returns false for filters with different tcp flags", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().
+						WithProtocol(types.FilterProtocolIPv4).
+						WithMatchKeyIPProto(types.FlowerIPProtoTCP)
+				}
+				Expect(fb().WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).Build().Equals(
+					fb().WithMatchKeyTCPFlags(0, types.TCPFlagSYN).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).Build().Equals(
+					fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).Build().Equals(
+					fb().WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).Build())).To(BeTrue())
+			})
+
+			It("returns false for filters with different vlan id", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().
+						WithProtocol(types.FilterProtocol8021Q).
+						WithMatchKeyVlanEthType(types.FlowerVlanEthTypeIPv4)
+				}
+				Expect(fb().WithMatchKeyVlanID(100).Build().Equals(fb().WithMatchKeyVlanID(200).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyVlanID(100).Build().Equals(fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyVlanID(100).Build().Equals(fb().WithMatchKeyVlanID(100).Build())).To(BeTrue())
+			})
+
+			It("returns false for filters with different dst mac", func() {
+				mcast := net.HardwareAddr{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+				bcast := net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolAll)
+				}
+				Expect(fb().WithMatchKeyDstMAC(mcast, mcast).Build().Equals(
+					fb().WithMatchKeyDstMAC(mcast, nil).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyDstMAC(mcast, mcast).Build().Equals(
+					fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyDstMAC(mcast, mcast).Build().Equals(
+					fb().WithMatchKeyDstMAC(mcast, mcast).Build())).To(BeTrue())
+				// full mask is equal to no mask
+				Expect(fb().WithMatchKeyDstMAC(bcast, bcast).Build().Equals(
+					fb().WithMatchKeyDstMAC(bcast, nil).Build())).To(BeTrue())
+			})
+
+			It("returns false for filters with different ip flags", func() {
+				fb := func() *types.FlowerFilterBuilder {
+					return types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4)
+				}
+				Expect(fb().WithMatchKeyIPFlags(types.FlowerIPFlagsFrag).Build().Equals(
+					fb().WithMatchKeyIPFlags(types.FlowerIPFlagsNoFirstFrag).Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyIPFlags(types.FlowerIPFlagsFrag).Build().Equals(
+					fb().Build())).To(BeFalse())
+				Expect(fb().WithMatchKeyIPFlags(types.FlowerIPFlagsFrag).Build().Equals(
+					fb().WithMatchKeyIPFlags(types.FlowerIPFlagsFrag).Build())).To(BeTrue())
+			})
+
 			It("returns true for filters with same IPv4 address but with different byte len", func() {
 				ip1 := net.IP{0x10, 0x20, 0x30, 0x2}
 				ip2 := ip1.To16()
@@ -286,6 +412,450 @@ var _ = Describe("Filter tests", func() {
 					"action", "gact", "pass"}
 				Expect(testFilterVlanIPv6.GenCmdLineArgs()).To(Equal(expectedArgs))
 			})
+
+			It("generates expected command line args - skip_hw", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(300).
+					WithSkipHW().
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "300", "flower", "skip_hw", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - ipv6 icmpv6 type", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv6).
+					WithPriority(51).
+					WithMatchKeyIPProto(types.FlowerIPProtoICMPv6).
+					WithMatchKeyICMPType(135).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ipv6", "pref", "51", "flower",
+					"ip_proto", "icmpv6", "type", "135", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - QinQ ipv4", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocol8021AD).
+					WithPriority(205).
+					WithMatchKeyVlanEthType(types.FlowerVlanEthType8021Q).
+					WithMatchKeyCVlanID(10).
+					WithMatchKeyCVlanEthType(types.FlowerVlanEthTypeIPv4).
+					WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+					WithMatchKeyDstIP(ipToIpNet("10.10.10.0/24")).
+					WithMatchKeyDstPort(6666).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "802.1ad", "pref", "205", "flower",
+					"vlan_ethtype", "802.1q", "cvlan_id", "10", "cvlan_ethtype", "ip", "ip_proto", "tcp",
+					"dst_ip", "10.10.10.0/24", "dst_port", "6666", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - vlan arp", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocol8021Q).
+					WithPriority(52).
+					WithMatchKeyVlanEthType(types.FlowerVlanEthTypeARP).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "802.1q", "pref", "52", "flower",
+					"vlan_ethtype", "arp", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - source address keys", func() {
+				mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(10).
+					WithMatchKeySrcMAC(mac).
+					WithMatchKeySrcIP(ipToIpNet("10.10.10.1/32")).
+					WithAction(types.NewGenericActionBuiler().WithGotoChain(1).Build()).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "10", "flower",
+					"src_mac", "aa:bb:cc:dd:ee:01", "src_ip", "10.10.10.1", "action", "gact", "goto", "chain", "1"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - arp sender address", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolARP).
+					WithPriority(13).
+					WithMatchKeyArpSIP(ipToIpNet("10.10.10.1/32")).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "arp", "pref", "13", "flower",
+					"arp_sip", "10.10.10.1", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - ct_state and ct action", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithChain(2).
+					WithPriority(20).
+					WithMatchKeyCtState(types.FlowerCtStateEstablished).
+					WithAction(types.NewConnTrackActionBuilder().WithCommit().Build()).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "chain", "2", "pref", "20", "flower",
+					"ct_state", "+trk+est", "action", "ct", "commit", "pipe", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - vlan id", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocol8021Q).
+					WithPriority(202).
+					WithMatchKeyVlanID(100).
+					WithMatchKeyVlanEthType(types.FlowerVlanEthTypeIPv4).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "802.1q", "pref", "202", "flower",
+					"vlan_id", "100", "vlan_ethtype", "ip", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - dst mac", func() {
+				mcast := net.HardwareAddr{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(200).
+					WithMatchKeyDstMAC(mcast, mcast).
+					WithMatchKeyDstIP(&net.IPNet{IP: net.IP{239, 1, 1, 1}, Mask: net.CIDRMask(32, 32)}).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "200", "flower",
+					"dst_mac", "01:00:00:00:00:00/01:00:00:00:00:00", "dst_ip", "239.1.1.1", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - igmp", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(50).
+					WithMatchKeyIPProto(types.FlowerIPProtoIGMP).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "50", "flower", "ip_proto", "0x02", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - ip flags", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(200).
+					WithMatchKeyDstIP(&net.IPNet{IP: net.IP{10, 100, 1, 0}, Mask: net.CIDRMask(24, 32)}).
+					WithMatchKeyIPFlags(types.FlowerIPFlagsNoFirstFrag).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "200", "flower",
+					"dst_ip", "10.100.1.0/24", "ip_flags", "frag/nofirstfrag", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - tcp flags", func() {
+				filter := types.NewFlowerFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv6).
+					WithPriority(251).
+					WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+					WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).
+					WithAction(passAction).
+					Build()
+				expectedArgs := []string{
+					"protocol", "ipv6", "pref", "251", "flower",
+					"ip_proto", "tcp", "tcp_flags", "0x10/0x10", "action", "gact", "pass"}
+				Expect(filter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+	})
+
+	Describe("U32Filter", func() {
+		hashTableFilter := types.NewU32FilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithPriority(206).
+			WithDivisor(0x100, 256).
+			Build()
+		entryFilter := types.NewU32FilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithPriority(206).
+			WithHashTable(0x100, 0xa).
+			WithMatchDstIP(ipToIpNet("10.0.0.10/32")).
+			WithAction(passAction).
+			Build()
+		linkFilter := types.NewU32FilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithPriority(206).
+			WithMatch(0, 0, 0).
+			WithHashKey(0xff, types.U32OffsetIPv4DstIP).
+			WithLink(0x100).
+			Build()
+
+		Context("Builder", func() {
+			It("builds hash table filter", func() {
+				Expect(hashTableFilter.Kind).To(Equal(types.FilterKindU32))
+				Expect(*hashTableFilter.Handle).To(Equal(uint32(0x10000000)))
+				Expect(hashTableFilter.U32.HashTable).To(Equal(uint32(0x100)))
+				Expect(hashTableFilter.U32.Divisor).To(Equal(uint32(256)))
+			})
+
+			It("builds matches on ipv6 destination address", func() {
+				filter := types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv6).
+					WithMatchDstIP(ipToIpNet("2001::1:2/112")).
+					Build()
+				Expect(filter.U32.Matches).To(Equal([]types.U32Match{
+					{Value: 0x20010000, Mask: 0xffffffff, Offset: 24},
+					{Value: 0, Mask: 0xffffffff, Offset: 28},
+					{Value: 0, Mask: 0xffffffff, Offset: 32},
+					{Value: 0x00010000, Mask: 0xffff0000, Offset: 36},
+				}))
+			})
+		})
+
+		Context("Equals", func() {
+			It("returns true if filters are equal regardless of node handle", func() {
+				other := types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithHandle(types.U32Handle(0x100, 0xa, 0x800)).
+					WithHashTable(0x100, 0xa).
+					WithMatch(0x0a00000a, 0xffffffff, 16).
+					WithAction(passAction).
+					Build()
+				Expect(entryFilter.Equals(other)).To(BeTrue())
+			})
+
+			It("returns false if filters differ in hash table", func() {
+				other := types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithDivisor(0x101, 256).
+					Build()
+				Expect(hashTableFilter.Equals(other)).To(BeFalse())
+			})
+
+			It("returns false if filters differ in matches", func() {
+				other := types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithHashTable(0x100, 0xa).
+					WithMatchDstIP(ipToIpNet("10.0.1.10/32")).
+					WithAction(passAction).
+					Build()
+				Expect(entryFilter.Equals(other)).To(BeFalse())
+			})
+
+			It("returns false if other filter is not a u32 filter", func() {
+				Expect(entryFilter.Equals(testFilterIPv4)).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args - hash table", func() {
+				expectedArgs := []string{
+					"protocol", "ip", "handle", "100:", "pref", "206", "u32", "divisor", "256"}
+				Expect(hashTableFilter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - hash table entry", func() {
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "206", "u32", "ht", "100:a:",
+					"match", "u32", "0x0a00000a", "0xffffffff", "at", "16", "action", "gact", "pass"}
+				Expect(entryFilter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - link", func() {
+				expectedArgs := []string{
+					"protocol", "ip", "pref", "206", "u32", "match", "u32", "0x00000000", "0x00000000", "at", "0",
+					"hashkey", "mask", "0x000000ff", "at", "16", "link", "100:"}
+				Expect(linkFilter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+
+			It("generates expected command line args - delete by handle", func() {
+				attrs := types.NewFilterAttrsBuilder().
+					WithKind(types.FilterKindU32).
+					WithProtocol(types.FilterProtocolIPv4).
+					WithHandle(types.U32Handle(0x800, 0, 0x800)).
+					WithPriority(206).
+					Build()
+				expectedArgs := []string{"protocol", "ip", "handle", "800::800", "pref", "206", "u32"}
+				Expect(attrs.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+	})
+
+	Describe("MatchAllFilter", func() {
+		matchAllFilter := types.NewMatchAllFilterBuilder().
+			WithProtocol(types.FilterProtocolAll).
+			WithPriority(300).
+			WithChain(1).
+			WithSkipHW().
+			WithAction(dropAction).
+			Build()
+
+		Context("Equals", func() {
+			It("returns true if filters are equal regardless of handle", func() {
+				other := types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithChain(1).
+					WithHandle(1).
+					WithSkipHW().
+					WithAction(dropAction).
+					Build()
+				Expect(matchAllFilter.Equals(other)).To(BeTrue())
+			})
+
+			It("returns false if filters differ in skip_hw or actions", func() {
+				other := types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithChain(1).
+					WithAction(dropAction).
+					Build()
+				Expect(matchAllFilter.Equals(other)).To(BeFalse())
+				other.SkipHW = true
+				other.Actions = []types.Action{passAction}
+				Expect(matchAllFilter.Equals(other)).To(BeFalse())
+			})
+
+			It("returns false if filters are attached to different hooks", func() {
+				ingress := types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithChain(1).
+					WithParent(types.ParentIngress).
+					WithSkipHW().
+					WithAction(dropAction).
+					Build()
+				egress := types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithChain(1).
+					WithParent(types.ParentEgress).
+					WithSkipHW().
+					WithAction(dropAction).
+					Build()
+				// nil parent is the ingress hook
+				Expect(matchAllFilter.Equals(ingress)).To(BeTrue())
+				Expect(matchAllFilter.Equals(egress)).To(BeFalse())
+				Expect(egress.GenCmdLineArgs()).To(Equal(matchAllFilter.GenCmdLineArgs()))
+			})
+
+			It("returns false if other filter is a basic filter with the same attributes", func() {
+				other := types.NewBasicFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithChain(1).
+					WithAction(dropAction).
+					Build()
+				Expect(matchAllFilter.Equals(other)).To(BeFalse())
+				Expect(other.Equals(matchAllFilter)).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				expectedArgs := []string{
+					"protocol", "all", "chain", "1", "pref", "300", "matchall", "skip_hw", "action", "gact", "drop"}
+				Expect(matchAllFilter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+	})
+
+	Describe("BasicFilter", func() {
+		basicFilter := types.NewBasicFilterBuilder().
+			WithProtocol(types.FilterProtocolIPv4).
+			WithPriority(300).
+			WithAction(passAction).
+			Build()
+
+		Context("Equals", func() {
+			It("returns true if filters are equal regardless of handle", func() {
+				other := types.NewBasicFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(300).
+					WithHandle(1).
+					WithAction(passAction).
+					Build()
+				Expect(basicFilter.Equals(other)).To(BeTrue())
+			})
+
+			It("returns false if filters differ in actions", func() {
+				other := types.NewBasicFilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(300).
+					WithAction(dropAction).
+					Build()
+				Expect(basicFilter.Equals(other)).To(BeFalse())
+			})
+		})
+
+		Context("CmdLineGenerator", func() {
+			It("generates expected command line args", func() {
+				expectedArgs := []string{"protocol", "ip", "pref", "300", "basic", "action", "gact", "pass"}
+				Expect(basicFilter.GenCmdLineArgs()).To(Equal(expectedArgs))
+			})
+		})
+	})
+
+	Describe("Key", func() {
+		ip16Net := ipToIpNet("10.10.10.0/24")
+		ip16Net.IP = ip16Net.IP.To16()
+		filters := []types.Filter{
+			testFilterIPv4,
+			testFilterIPv6,
+			testFilterVlanIPv4,
+			testFilterVlanIPv6,
+			// equal to testFilterIPv4 with nil chain, no handle and 16 bytes ipv4 address
+			types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocolIPv4).
+				WithPriority(100).
+				WithMatchKeyDstIP(ip16Net).
+				WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+				WithMatchKeyDstPort(6666).
+				WithAction(passAction).
+				Build(),
+			types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).Build(),
+			types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).
+				WithParent(types.ParentEgress).Build(),
+			types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).
+				WithAction(types.NewGenericGotoAction(1)).Build(),
+			types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).
+				WithAction(types.NewGenericGotoAction(2)).Build(),
+			types.NewFlowerFilterBuilder().WithProtocol(types.FilterProtocolIPv4).Build(),
+			types.NewU32FilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(206).
+				WithDivisor(0x100, 256).Build(),
+			types.NewU32FilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(206).
+				WithMatch(0, 0, 0).WithHashKey(0xff, types.U32OffsetIPv4DstIP).WithLink(0x100).Build(),
+			types.NewMatchAllFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).Build(),
+			types.NewBasicFilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithPriority(100).Build(),
+		}
+
+		It("returns the same key for equal filters only", func() {
+			for _, f := range filters {
+				for _, other := range filters {
+					Expect(f.Key() == other.Key()).To(Equal(f.Equals(other)),
+						"%v\n%v", f.GenCmdLineArgs(), other.GenCmdLineArgs())
+				}
+			}
+			Expect(filters[0].Key()).To(Equal(filters[4].Key()))
 		})
 	})
 })
@@ -0,0 +1,821 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// actionArg starts an action in tc command line args
+	actionArg = "action"
+	// tcpFlagsMaxMask is the mask of TCPFlags flower key if omitted in tc command line args
+	tcpFlagsMaxMask uint16 = 0xfff
+)
+
+// flowerKeySetters maps flower keys to functions which parse the key value from tc command line arg
+// and set it in FlowerSpec
+var flowerKeySetters = map[FlowerKey]func(spec *FlowerSpec, val string) error{
+	FlowerKeyVlanID: func(spec *FlowerSpec, val string) (err error) {
+		spec.VlanID, err = parseUint[uint16](val, 16)
+		return err
+	},
+	FlowerKeyVlanEthType: func(spec *FlowerSpec, val string) error {
+		ethType := FlowerVlanEthType(val)
+		spec.VlanEthType = &ethType
+		return nil
+	},
+	FlowerKeyCVlanID: func(spec *FlowerSpec, val string) (err error) {
+		spec.CVlanID, err = parseUint[uint16](val, 16)
+		return err
+	},
+	FlowerKeyCVlanEthType: func(spec *FlowerSpec, val string) error {
+		ethType := FlowerVlanEthType(val)
+		spec.CVlanEthType = &ethType
+		return nil
+	},
+	FlowerKeySrcMAC: func(spec *FlowerSpec, val string) (err error) {
+		spec.SrcMAC, err = net.ParseMAC(val)
+		return err
+	},
+	FlowerKeyDstMAC: func(spec *FlowerSpec, val string) (err error) {
+		spec.DstMAC, err = parseFlowerMAC(val)
+		return err
+	},
+	FlowerKeyIPProto: func(spec *FlowerSpec, val string) error {
+		ipProto := FlowerIPProto(val)
+		spec.IPProto = &ipProto
+		return nil
+	},
+	FlowerKeySrcIP: func(spec *FlowerSpec, val string) (err error) {
+		spec.SrcIP, err = parseCmdLineIPNet(val)
+		return err
+	},
+	FlowerKeyDstIP: func(spec *FlowerSpec, val string) (err error) {
+		spec.DstIP, err = parseCmdLineIPNet(val)
+		return err
+	},
+	FlowerKeyArpSIP: func(spec *FlowerSpec, val string) (err error) {
+		spec.ArpSIP, err = parseCmdLineIPNet(val)
+		return err
+	},
+	FlowerKeySrcPort: func(spec *FlowerSpec, val string) (err error) {
+		spec.SrcPort, err = parseUint[uint16](val, 16)
+		return err
+	},
+	FlowerKeyDstPort: func(spec *FlowerSpec, val string) (err error) {
+		spec.DstPort, err = parseUint[uint16](val, 16)
+		return err
+	},
+	FlowerKeyIPFlags: func(spec *FlowerSpec, val string) error {
+		ipFlags := FlowerIPFlags(val)
+		spec.IPFlags = &ipFlags
+		return nil
+	},
+	FlowerKeyTCPFlags: func(spec *FlowerSpec, val string) (err error) {
+		spec.TCPFlags, err = parseFlowerTCPFlags(val)
+		return err
+	},
+	FlowerKeyICMPType: func(spec *FlowerSpec, val string) (err error) {
+		spec.ICMPType, err = parseUint[uint8](val, 8)
+		return err
+	},
+	FlowerKeyCtState: func(spec *FlowerSpec, val string) error {
+		ctState := FlowerCtState(val)
+		spec.CtState = &ctState
+		return nil
+	},
+}
+
+// ParseQDiscArgs parses tc qdisc command line args (see GenericQDisc.GenCmdLineArgs) to QDisc.
+// ingress and clsact qdiscs are supported.
+func ParseQDiscArgs(args []string) (QDisc, error) {
+	p := &argsParser{args: args}
+	attrsBuilder := NewQDiscAttrsBuilder()
+	var qdiscType QDiscType
+
+	for !p.done() {
+		switch arg := p.next(); arg {
+		case string(QDiscIngressType), string(QDiscClsactType):
+			qdiscType = QDiscType(arg)
+		case "handle", "parent":
+			val, err := p.value(arg)
+			if err != nil {
+				return nil, err
+			}
+			h, err := parseTCHandle(val)
+			if err != nil {
+				return nil, err
+			}
+			if arg == "handle" {
+				attrsBuilder.WithHandle(h)
+			} else {
+				attrsBuilder.WithParent(h)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported qdisc arg: %q", arg)
+		}
+	}
+
+	if qdiscType == "" {
+		return nil, fmt.Errorf("missing qdisc type")
+	}
+	return NewGenericQdisc(attrsBuilder.Build(), qdiscType), nil
+}
+
+// ParseChainArgs parses tc chain command line args (see ChainImpl.GenCmdLineArgs) to Chain.
+// chain hook may be provided as "ingress" or "egress" arg (as in tc command line of a clsact qdisc).
+func ParseChainArgs(args []string) (Chain, error) {
+	p := &argsParser{args: args}
+	cb := NewChainBuilder()
+	var protocol FilterProtocol
+	var template bool
+
+	for !p.done() {
+		var err error
+		switch arg := p.next(); arg {
+		case "ingress", "egress", "parent":
+			var parent *uint32
+			if parent, err = p.parent(arg); parent != nil {
+				cb.WithParent(*parent)
+			}
+		case "chain":
+			var chain uint64
+			chain, err = p.uintValue(arg, 32)
+			cb.WithChain(uint32(chain))
+		case "protocol":
+			var val string
+			val, err = p.value(arg)
+			protocol = FilterProtocol(val)
+		case string(FilterKindFlower):
+			// must be last as next are template flower keys
+			flower := &FlowerSpec{}
+			for err == nil && !p.done() {
+				err = p.flowerKey(flower, p.next())
+			}
+			cb.WithTemplate(protocol, flower)
+			template = true
+		default:
+			err = fmt.Errorf("unsupported chain arg: %q", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if protocol != "" && !template {
+		return nil, fmt.Errorf("chain template protocol provided without flower template")
+	}
+	return cb.Build(), nil
+}
+
+// ParseFilterArgs parses tc filter command line args (see Filter.GenCmdLineArgs) to Filter.
+// flower, u32, matchall and basic filters are supported. filter hook may be provided as "ingress" or "egress" arg
+// (as in tc command line of a clsact qdisc).
+func ParseFilterArgs(args []string) (Filter, error) {
+	p := &argsParser{args: args}
+	attrs, handle, err := p.filterAttrs()
+	if err != nil {
+		return nil, err
+	}
+
+	if handle != "" {
+		var h uint32
+		if attrs.Kind == FilterKindU32 {
+			h, err = parseU32Handle(handle)
+		} else {
+			var n uint64
+			n, err = strconv.ParseUint(handle, 0, 32)
+			h = uint32(n)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter handle: %q", handle)
+		}
+		attrs.Handle = &h
+	}
+
+	var filter Filter
+	switch attrs.Kind {
+	case FilterKindFlower:
+		filter, err = p.flowerFilter(attrs)
+	case FilterKindU32:
+		filter, err = p.u32Filter(attrs)
+	case FilterKindMatchAll:
+		filter, err = p.matchAllFilter(attrs)
+	case FilterKindBasic:
+		var actions []Action
+		actions, err = p.actions()
+		filter = &BasicFilter{FilterAttrs: *attrs, Actions: actions}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s filter", attrs.Kind)
+	}
+	return filter, nil
+}
+
+// ParseActionArgs parses tc command line args of a list of actions (see Action.GenCmdLineArgs) to Action list.
+// gact, ct, pedit (DSCP edit), csum and police actions are supported.
+func ParseActionArgs(args []string) ([]Action, error) {
+	p := &argsParser{args: args}
+	return p.actions()
+}
+
+// argsParser consumes tc command line args
+type argsParser struct {
+	args []string
+	pos  int
+}
+
+// done returns true if all args were consumed
+func (p *argsParser) done() bool {
+	return p.pos >= len(p.args)
+}
+
+// peek returns the next arg without consuming it, or an empty string if all args were consumed
+func (p *argsParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.args[p.pos]
+}
+
+// next consumes and returns the next arg, or an empty string if all args were consumed
+func (p *argsParser) next() string {
+	arg := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return arg
+}
+
+// atAction returns true if all args were consumed or the next arg starts an action
+func (p *argsParser) atAction() bool {
+	return p.done() || p.peek() == actionArg
+}
+
+// expect consumes the next args, it fails if they are not the expected args
+func (p *argsParser) expect(expected ...string) error {
+	for _, e := range expected {
+		if arg := p.next(); arg != e {
+			return fmt.Errorf("expected %q, got %q", e, arg)
+		}
+	}
+	return nil
+}
+
+// value consumes and returns the value of key
+func (p *argsParser) value(key string) (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("missing value for %q", key)
+	}
+	return p.next(), nil
+}
+
+// uintValue consumes the value of key and parses it as an unsigned integer of bitSize bits
+func (p *argsParser) uintValue(key string, bitSize int) (uint64, error) {
+	val, err := p.value(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(val, 0, bitSize)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value for %q", key)
+	}
+	return n, nil
+}
+
+// parent returns the parent of a filter or chain provided as hook ("ingress" or "egress") arg or
+// consumes the value of "parent" arg. ingress hook is returned as nil parent.
+func (p *argsParser) parent(arg string) (*uint32, error) {
+	switch arg {
+	case "ingress":
+		return nil, nil
+	case "egress":
+		parent := ParentEgress
+		return &parent, nil
+	}
+	val, err := p.value(arg)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := parseTCHandle(val)
+	if err != nil {
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// flowerKey consumes the value of flower key and sets it in spec
+func (p *argsParser) flowerKey(spec *FlowerSpec, key string) error {
+	setter, ok := flowerKeySetters[FlowerKey(key)]
+	if !ok {
+		return fmt.Errorf("unsupported flower key: %q", key)
+	}
+	val, err := p.value(key)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(setter(spec, val), "invalid value for %q", key)
+}
+
+// filterAttrs consumes filter attributes up to and including filter kind. filter handle is returned
+// as is since its format depends on filter kind.
+func (p *argsParser) filterAttrs() (*FilterAttrs, string, error) {
+	attrs := &FilterAttrs{}
+	var handle string
+
+	for attrs.Kind == "" {
+		if p.done() {
+			return nil, "", fmt.Errorf("missing filter kind")
+		}
+
+		var err error
+		switch arg := p.next(); arg {
+		case "protocol":
+			var val string
+			val, err = p.value(arg)
+			attrs.Protocol = FilterProtocol(val)
+		case "handle":
+			handle, err = p.value(arg)
+		case "chain":
+			var chain uint64
+			chain, err = p.uintValue(arg, 32)
+			c := uint32(chain)
+			attrs.Chain = &c
+		case "pref", "prio", "priority":
+			var prio uint64
+			prio, err = p.uintValue(arg, 16)
+			pr := uint16(prio)
+			attrs.Priority = &pr
+		case "ingress", "egress", "parent":
+			attrs.Parent, err = p.parent(arg)
+		case string(FilterKindFlower), string(FilterKindU32), string(FilterKindMatchAll), string(FilterKindBasic):
+			attrs.Kind = FilterKind(arg)
+		default:
+			err = fmt.Errorf("unsupported filter arg: %q", arg)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return attrs, handle, nil
+}
+
+// flowerFilter consumes flower filter args following filter attributes
+func (p *argsParser) flowerFilter(attrs *FilterAttrs) (Filter, error) {
+	f := &FlowerFilter{FilterAttrs: *attrs, Flower: &FlowerSpec{}}
+
+	for !p.atAction() {
+		if arg := p.next(); arg == "skip_hw" {
+			f.SkipHW = true
+		} else if err := p.flowerKey(f.Flower, arg); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if f.Actions, err = p.actions(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// u32Filter consumes u32 filter args following filter attributes
+func (p *argsParser) u32Filter(attrs *FilterAttrs) (Filter, error) {
+	f := &U32Filter{FilterAttrs: *attrs, U32: &U32Spec{}}
+
+	for !p.atAction() {
+		var err error
+		switch arg := p.next(); arg {
+		case "skip_hw":
+			f.SkipHW = true
+		case "divisor":
+			var divisor uint64
+			divisor, err = p.uintValue(arg, 32)
+			f.U32.Divisor = uint32(divisor)
+		case "ht":
+			var h uint32
+			h, err = p.u32Handle(arg)
+			f.U32.HashTable, f.U32.Bucket = h>>20, (h>>12)&0xff
+		case "link":
+			var h uint32
+			h, err = p.u32Handle(arg)
+			f.U32.Link = h >> 20
+		case "match":
+			var m U32Match
+			m, err = p.u32Match()
+			f.U32.Matches = append(f.U32.Matches, m)
+		case "hashkey":
+			f.U32.HashKey, err = p.u32HashKey()
+		default:
+			err = fmt.Errorf("unsupported u32 arg: %q", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.U32.Divisor != 0 && f.Handle != nil {
+		// hash table created by the filter is provided as the filter handle
+		f.U32.HashTable = *f.Handle >> 20
+	}
+
+	var err error
+	if f.Actions, err = p.actions(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// u32Handle consumes the value of key and parses it as u32 handle
+func (p *argsParser) u32Handle(key string) (uint32, error) {
+	val, err := p.value(key)
+	if err != nil {
+		return 0, err
+	}
+	return parseU32Handle(val)
+}
+
+// u32Match consumes u32 match args following "match" arg (see U32Match.GenCmdLineArgs)
+func (p *argsParser) u32Match() (U32Match, error) {
+	if err := p.expect("u32"); err != nil {
+		return U32Match{}, err
+	}
+	value, err := p.uintValue("value", 32)
+	if err != nil {
+		return U32Match{}, err
+	}
+	mask, err := p.uintValue("mask", 32)
+	if err != nil {
+		return U32Match{}, err
+	}
+	offset, err := p.u32Offset()
+	if err != nil {
+		return U32Match{}, err
+	}
+	return U32Match{Value: uint32(value), Mask: uint32(mask), Offset: offset}, nil
+}
+
+// u32HashKey consumes u32 hash key args following "hashkey" arg (see U32Spec.GenCmdLineArgs)
+func (p *argsParser) u32HashKey() (*U32HashKey, error) {
+	if err := p.expect("mask"); err != nil {
+		return nil, err
+	}
+	mask, err := p.uintValue("mask", 32)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := p.u32Offset()
+	if err != nil {
+		return nil, err
+	}
+	return &U32HashKey{Mask: uint32(mask), Offset: offset}, nil
+}
+
+// u32Offset consumes "at" arg and the offset following it
+func (p *argsParser) u32Offset() (int32, error) {
+	if err := p.expect("at"); err != nil {
+		return 0, err
+	}
+	val, err := p.value("at")
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(val, 0, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value for %q", "at")
+	}
+	return int32(offset), nil
+}
+
+// matchAllFilter consumes matchall filter args following filter attributes
+func (p *argsParser) matchAllFilter(attrs *FilterAttrs) (Filter, error) {
+	f := &MatchAllFilter{FilterAttrs: *attrs}
+
+	for !p.atAction() {
+		if arg := p.next(); arg != "skip_hw" {
+			return nil, fmt.Errorf("unsupported matchall arg: %q", arg)
+		}
+		f.SkipHW = true
+	}
+
+	var err error
+	if f.Actions, err = p.actions(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// actions consumes the remaining args as a list of actions
+func (p *argsParser) actions() ([]Action, error) {
+	actions := make([]Action, 0)
+
+	for !p.done() {
+		if err := p.expect(actionArg); err != nil {
+			return nil, err
+		}
+		action, err := p.action()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse action at index %d", len(actions))
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// action consumes action args following "action" arg
+func (p *argsParser) action() (Action, error) {
+	arg := p.next()
+	switch arg {
+	case string(ActionTypeGeneric):
+		return p.genericAction(p.next())
+	case string(ActionGenericPass), string(ActionGenericDrop), string(ActionGenericGoto):
+		// Note(adrianc): tc allows omitting gact action type
+		return p.genericAction(arg)
+	case string(ActionTypeConnTrack):
+		return p.connTrackAction()
+	case string(ActionTypePedit):
+		return p.peditDSCPAction()
+	case string(ActionTypeCsum):
+		return p.csumAction()
+	case string(ActionTypePolice):
+		return p.policeAction()
+	}
+	return nil, fmt.Errorf("unsupported action: %q", arg)
+}
+
+// genericAction consumes generic action args following control action
+func (p *argsParser) genericAction(controlAction string) (Action, error) {
+	switch ActionGenericType(controlAction) {
+	case ActionGenericPass, ActionGenericDrop:
+		return NewGenericAction(ActionGenericType(controlAction)), nil
+	case ActionGenericGoto:
+		if err := p.expect("chain"); err != nil {
+			return nil, err
+		}
+		chain, err := p.uintValue("chain", 32)
+		if err != nil {
+			return nil, err
+		}
+		return NewGenericGotoAction(uint32(chain)), nil
+	}
+	return nil, fmt.Errorf("unsupported generic control action: %q", controlAction)
+}
+
+// connTrackAction consumes connection tracking action args (see ConnTrackAction.GenCmdLineArgs)
+func (p *argsParser) connTrackAction() (Action, error) {
+	cb := NewConnTrackActionBuilder()
+
+	for !p.atAction() {
+		var err error
+		switch arg := p.next(); arg {
+		case string(ActionConnTrackCommit):
+			cb.WithCommit()
+		case "zone":
+			var zone uint64
+			zone, err = p.uintValue(arg, 16)
+			cb.WithZone(uint16(zone))
+		case "pipe":
+		default:
+			err = fmt.Errorf("unsupported ct arg: %q", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cb.Build(), nil
+}
+
+// peditDSCPAction consumes packet edit action args which set DSCP (see PeditDSCPAction.GenCmdLineArgs)
+func (p *argsParser) peditDSCPAction() (Action, error) {
+	if err := p.expect("ex", "munge"); err != nil {
+		return nil, err
+	}
+
+	header := PeditHeaderType(p.next())
+	field := "tos"
+	switch header {
+	case PeditHeaderIPv4:
+	case PeditHeaderIPv6:
+		field = "traffic_class"
+	default:
+		return nil, fmt.Errorf("unsupported pedit header: %q", header)
+	}
+	if err := p.expect(field, "set"); err != nil {
+		return nil, err
+	}
+	val, err := p.uintValue("set", 8)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect("retain"); err != nil {
+		return nil, err
+	}
+	retain, err := p.uintValue("retain", 8)
+	if err != nil {
+		return nil, err
+	}
+
+	dscpMask := uint64(DSCPMax << dscpShift)
+	if retain != dscpMask || val&^dscpMask != 0 {
+		return nil, fmt.Errorf("only DSCP edit is supported, got set 0x%x retain 0x%x", val, retain)
+	}
+	if p.peek() == "pipe" {
+		p.next()
+	}
+	return NewPeditDSCPAction(header, uint8(val>>dscpShift)), nil
+}
+
+// csumAction consumes checksum action args (see CsumAction.GenCmdLineArgs)
+func (p *argsParser) csumAction() (Action, error) {
+	updates := make([]CsumUpdateType, 0)
+
+	for !p.atAction() {
+		if arg := p.next(); arg != "pipe" {
+			updates = append(updates, CsumUpdateType(arg))
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("missing csum update types")
+	}
+	return NewCsumAction(updates...), nil
+}
+
+// policeAction consumes police action args (see PoliceAction.GenCmdLineArgs)
+func (p *argsParser) policeAction() (Action, error) {
+	pb := NewPoliceActionBuilder()
+
+	for !p.atAction() {
+		var err error
+		switch arg := p.next(); arg {
+		case "rate":
+			var rate string
+			if rate, err = p.value(arg); err == nil {
+				var bytesRate uint64
+				bytesRate, err = parseRate(rate)
+				pb.WithRate(bytesRate)
+			}
+		case "burst":
+			var burst uint64
+			burst, err = p.uintValue(arg, 32)
+			pb.WithBurst(uint32(burst))
+		case "conform-exceed":
+			var val string
+			if val, err = p.value(arg); err == nil {
+				var conform, exceed PoliceControlType
+				exceed, conform, err = parsePoliceConformExceed(val)
+				pb.WithConformExceed(conform, exceed)
+			}
+		default:
+			err = fmt.Errorf("unsupported police arg: %q", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pb.Build(), nil
+}
+
+// parseUint parses s as an unsigned integer of bitSize bits, s may be prefixed with 0x for hexadecimal
+func parseUint[T uint8 | uint16 | uint32](s string, bitSize int) (*T, error) {
+	n, err := strconv.ParseUint(s, 0, bitSize)
+	if err != nil {
+		return nil, err
+	}
+	v := T(n)
+	return &v, nil
+}
+
+// parseHex16 parses s as a 16 bit hexadecimal number, optionally prefixed with 0x
+func parseHex16(s string) (uint16, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 16)
+	return uint16(n), err
+}
+
+// parseTCHandle parses tc handle (or parent) in "major:minor" hexadecimal format (e.g "ffff:fff3"),
+// missing major or minor are zero. a handle without ":" is parsed as a 32 bit hexadecimal number.
+func parseTCHandle(s string) (uint32, error) {
+	major, minor, found := strings.Cut(s, ":")
+	if !found {
+		h, err := strconv.ParseUint(s, 16, 32)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid handle: %q", s)
+		}
+		return uint32(h), nil
+	}
+
+	var h uint32
+	for i, part := range []string{major, minor} {
+		if part == "" {
+			continue
+		}
+		n, err := parseHex16(part)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid handle: %q", s)
+		}
+		h |= uint32(n) << (16 * (1 - i))
+	}
+	return h, nil
+}
+
+// parseU32Handle parses u32 filter handle in tc format "hashtable:bucket:node" (see U32HandleString),
+// missing parts are zero
+func parseU32Handle(s string) (uint32, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid u32 handle: %q", s)
+	}
+
+	var ids [3]uint32
+	bitSizes := [3]int{12, 8, 12}
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimPrefix(part, "0x"), 16, bitSizes[i])
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid u32 handle: %q", s)
+		}
+		ids[i] = uint32(n)
+	}
+	return U32Handle(ids[0], ids[1], ids[2]), nil
+}
+
+// parseCmdLineIPNet parses IP address or CIDR tc command line arg (see ipNetCmdLineArg), full mask is
+// assumed if mask is omitted
+func parseCmdLineIPNet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		return parseIPNet(s)
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	bits := len(ip) * 8
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// parseFlowerTCPFlags parses FlowerTCPFlags in tc format "flags[/mask]" (see FlowerTCPFlags.String()),
+// flags and mask are hexadecimal
+func parseFlowerTCPFlags(s string) (*FlowerTCPFlags, error) {
+	flags, mask, found := strings.Cut(s, "/")
+	tf := &FlowerTCPFlags{Mask: tcpFlagsMaxMask}
+	var err error
+
+	if tf.Flags, err = parseHex16(flags); err != nil {
+		return nil, err
+	}
+	if found {
+		if tf.Mask, err = parseHex16(mask); err != nil {
+			return nil, err
+		}
+	}
+	return tf, nil
+}
+
+// parseRate parses tc rate in bits per second (e.g "8000bit", "10mbit") and returns it in bytes per second.
+// rate without units is in bits per second.
+func parseRate(s string) (uint64, error) {
+	units := []struct {
+		suffix     string
+		multiplier uint64
+	}{{"gbit", 1000000000}, {"mbit", 1000000}, {"kbit", 1000}, {"bit", 1}, {"", 1}}
+
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			n, err := strconv.ParseUint(num, 10, 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "invalid rate: %q", s)
+			}
+			return n * u.multiplier / 8, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid rate: %q", s)
+}
+
+// parsePoliceConformExceed parses police "exceed[/conform]" control actions, conforming traffic is passed
+// if conform is omitted
+func parsePoliceConformExceed(s string) (exceed, conform PoliceControlType, err error) {
+	e, c, found := strings.Cut(s, "/")
+	exceed, conform = PoliceControlType(e), PoliceControlPass
+	if found {
+		conform = PoliceControlType(c)
+	}
+
+	for _, control := range []PoliceControlType{exceed, conform} {
+		switch control {
+		case PoliceControlPass, PoliceControlDrop, PoliceControlPipe, PoliceControlContinue:
+		default:
+			return "", "", fmt.Errorf("unsupported police control action: %q", control)
+		}
+	}
+	return exceed, conform, nil
+}
@@ -0,0 +1,169 @@
+package types_test
+
+import (
+	"net"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+var _ = Describe("Parser tests", func() {
+	mustParseCIDR := func(s string) *net.IPNet {
+		ip, ipNet, err := net.ParseCIDR(s)
+		Expect(err).ToNot(HaveOccurred())
+		ipNet.IP = ip
+		return ipNet
+	}
+	mustParseMAC := func(s string) net.HardwareAddr {
+		mac, err := net.ParseMAC(s)
+		Expect(err).ToNot(HaveOccurred())
+		return mac
+	}
+
+	Context("ParseFilterArgs", func() {
+		It("parses generated args of flower filter with all match keys and actions", func() {
+			filter := types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocol8021Q).
+				WithChain(1).
+				WithHandle(3).
+				WithPriority(200).
+				WithParent(types.ParentEgress).
+				WithMatchKeyVlanID(100).
+				WithMatchKeyVlanEthType(types.FlowerVlanEthTypeIPv4).
+				WithMatchKeySrcMAC(mustParseMAC("00:11:22:33:44:55")).
+				WithMatchKeyDstMAC(mustParseMAC("01:00:00:00:00:00"), mustParseMAC("01:00:00:00:00:00")).
+				WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+				WithMatchKeySrcIP(mustParseCIDR("10.0.0.5/32")).
+				WithMatchKeyDstIP(mustParseCIDR("2001::/64")).
+				WithMatchKeySrcPort(80).
+				WithMatchKeyDstPort(8080).
+				WithMatchKeyIPFlags(types.FlowerIPFlagsNoFrag).
+				WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).
+				WithMatchKeyCtState(types.FlowerCtStateEstablished).
+				WithSkipHW().
+				WithAction(types.NewConnTrackAction(true, 2)).
+				WithAction(types.NewPeditDSCPAction(types.PeditHeaderIPv6, 10)).
+				WithAction(types.NewCsumAction(types.CsumUpdateIPv4Header)).
+				WithAction(types.NewPoliceAction(1000, 64, types.PoliceControlPipe, types.PoliceControlDrop)).
+				WithAction(types.NewGenericGotoAction(100)).
+				Build()
+
+			parsed, err := types.ParseFilterArgs(append([]string{"egress"}, filter.GenCmdLineArgs()...))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Equals(filter)).To(BeTrue())
+			Expect(parsed.GenCmdLineArgs()).To(Equal(filter.GenCmdLineArgs()))
+		})
+
+		It("parses generated args of u32, matchall and basic filters", func() {
+			filters := []types.Filter{
+				types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithHandle(types.U32Handle(0x100, 0xa, 0x800)).
+					WithHashTable(0x100, 0xa).
+					WithMatch(0x0a000001, 0xffffffff, types.U32OffsetIPv4DstIP).
+					WithAction(types.NewGenericAction(types.ActionGenericPass)).
+					Build(),
+				types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithMatch(0, 0, 0).
+					WithHashKey(0xff, 16).
+					WithLink(0x100).
+					Build(),
+				types.NewU32FilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithDivisor(0x100, 256).Build(),
+				types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithSkipHW().
+					WithAction(types.NewGenericAction(types.ActionGenericDrop)).
+					Build(),
+				types.NewBasicFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithPriority(300).
+					WithAction(types.NewGenericAction(types.ActionGenericPass)).
+					Build(),
+			}
+
+			for _, f := range filters {
+				parsed, err := types.ParseFilterArgs(f.GenCmdLineArgs())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(parsed.Equals(f)).To(BeTrue(), strings.Join(f.GenCmdLineArgs(), " "))
+				Expect(*parsed.Attrs()).To(Equal(*f.Attrs()))
+			}
+		})
+
+		It("parses hand-written args", func() {
+			args := strings.Fields("ingress protocol ip prio 10 flower dst_ip 10.0.0.1 tcp_flags 2 " +
+				"action drop action police rate 8kbit burst 64 conform-exceed drop")
+			expected := types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocolIPv4).
+				WithPriority(10).
+				WithMatchKeyDstIP(mustParseCIDR("10.0.0.1/32")).
+				WithMatchKeyTCPFlags(types.TCPFlagSYN, 0xfff).
+				WithAction(types.NewGenericAction(types.ActionGenericDrop)).
+				WithAction(types.NewPoliceAction(1000, 64, types.PoliceControlPass, types.PoliceControlDrop)).
+				Build()
+
+			parsed, err := types.ParseFilterArgs(args)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Equals(expected)).To(BeTrue())
+			Expect(parsed.Attrs().Parent).To(BeNil())
+		})
+
+		It("fails on invalid args", func() {
+			for _, args := range []string{
+				"protocol ip pref 100",
+				"protocol ip pref 100 fw",
+				"protocol ip pref 100 flower dst_ip",
+				"protocol ip pref 100 flower enc_key_id 1",
+				"protocol ip pref 100 flower dst_ip 10.0.0.300",
+				"protocol ip pref 100 u32 match ip dst 10.0.0.1/32",
+				"protocol ip pref 100 matchall action mirred egress redirect dev eth1",
+				"protocol ip pref 100 matchall action pedit ex munge ip ttl set 0x10 retain 0xff pipe",
+				"protocol ip pref 100 matchall action police rate 10mbps burst 64",
+				"protocol ip pref 100 matchall drop",
+			} {
+				_, err := types.ParseFilterArgs(strings.Fields(args))
+				Expect(err).To(HaveOccurred(), args)
+			}
+		})
+	})
+
+	Context("ParseChainArgs and ParseQDiscArgs", func() {
+		It("parses generated args of chain with template", func() {
+			chain := types.NewChainBuilder().
+				WithParent(types.ParentEgress).
+				WithChain(10000).
+				WithTemplate(types.FilterProtocolIPv4, &types.FlowerSpec{DstIP: mustParseCIDR("0.0.0.0/24")}).
+				Build()
+
+			parsed, err := types.ParseChainArgs(chain.GenCmdLineArgs())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*parsed.Attrs().Parent).To(Equal(types.ParentEgress))
+			Expect(*parsed.Attrs().Chain).To(Equal(uint32(10000)))
+			Expect(parsed.Attrs().Template.Equals(chain.Template)).To(BeTrue())
+
+			_, err = types.ParseChainArgs(strings.Fields("chain 1 protocol ip"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("parses qdisc args", func() {
+			qdisc, err := types.ParseQDiscArgs(strings.Fields("handle ffff: ingress"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qdisc).To(BeEquivalentTo(types.NewIngressQDiscBuilder().WithHandle(0xffff0000).Build()))
+
+			qdisc, err = types.ParseQDiscArgs([]string{"clsact"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qdisc.Type()).To(Equal(types.QDiscClsactType))
+
+			_, err = types.ParseQDiscArgs([]string{"mq"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -2,8 +2,23 @@ package types
 
 const (
 	QDiscIngressType QDiscType = "ingress"
+	QDiscClsactType  QDiscType = "clsact"
+
+	// ParentIngress is the parent of filters and chains attached to the ingress hook of a clsact qdisc
+	ParentIngress uint32 = 0xfffffff2
+	// ParentEgress is the parent of filters and chains attached to the egress hook of a clsact qdisc
+	ParentEgress uint32 = 0xfffffff3
 )
 
+// HookOf returns the hook (ParentIngress or ParentEgress) of a filter or chain with the given parent.
+// nil parent or a parent other than ParentEgress is the ingress hook.
+func HookOf(parent *uint32) uint32 {
+	if parent != nil && *parent == ParentEgress {
+		return ParentEgress
+	}
+	return ParentIngress
+}
+
 // QDiscType is the type of qdisc
 type QDiscType string
 
@@ -125,3 +140,35 @@ func (iqb *IngressQDiscBuilder) Build() *GenericQDisc {
 	attrs := iqb.qDiscAttrsBuilder.Build()
 	return NewGenericQdisc(attrs, iqb.qDiscType)
 }
+
+// NewClsactQDiscBuilder returns a new ClsactQDiscBuilder
+func NewClsactQDiscBuilder() *ClsactQDiscBuilder {
+	return &ClsactQDiscBuilder{qDiscAttrsBuilder: NewQDiscAttrsBuilder(), qDiscType: QDiscClsactType}
+}
+
+// ClsactQDiscBuilder is a ClsactQDisc builder
+type ClsactQDiscBuilder struct {
+	qDiscAttrsBuilder *QDiscAttrsBuilder
+	qDiscType         QDiscType
+}
+
+// WithParent adds Parent to ClsactQDiscBuilder
+func (cqb *ClsactQDiscBuilder) WithParent(p uint32) *ClsactQDiscBuilder {
+	cqb.qDiscAttrsBuilder.WithParent(p)
+	return cqb
+}
+
+// WithHandle adds Handle to ClsactQDiscBuilder
+func (cqb *ClsactQDiscBuilder) WithHandle(h uint32) *ClsactQDiscBuilder {
+	cqb.qDiscAttrsBuilder.WithHandle(h)
+	return cqb
+}
+
+// Build builds and returns a new GenericQDisc instance of type QDiscClsactType
+// Note: calling Build() multiple times will not return a completely
+// new object on each call. that is, pointer/slice/map types will not be deep copied.
+// to create several objects, different builders should be used.
+func (cqb *ClsactQDiscBuilder) Build() *GenericQDisc {
+	attrs := cqb.qDiscAttrsBuilder.Build()
+	return NewGenericQdisc(attrs, cqb.qDiscType)
+}
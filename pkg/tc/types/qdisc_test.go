@@ -37,6 +37,31 @@ var _ = Describe("QDisc tests", func() {
 				assertQdisc(q)
 			})
 		})
+
+		Context("ClsactQDiscBuilder", func() {
+			It("Builds Clsact Qdisc with correct attributes", func() {
+				q := types.NewClsactQDiscBuilder().WithParent(parent).WithHandle(handle).Build()
+				Expect(*q.Parent).To(Equal(parent))
+				Expect(*q.Handle).To(Equal(handle))
+				Expect(q.Type()).To(Equal(types.QDiscClsactType))
+				Expect(q.GenCmdLineArgs()).To(Equal([]string{"clsact"}))
+			})
+		})
+	})
+
+	Describe("HookOf", func() {
+		It("returns ingress hook for nil or ingress parent", func() {
+			ingress := types.ParentIngress
+			legacyIngress := uint32(0xfffffff1)
+			Expect(types.HookOf(nil)).To(Equal(types.ParentIngress))
+			Expect(types.HookOf(&ingress)).To(Equal(types.ParentIngress))
+			Expect(types.HookOf(&legacyIngress)).To(Equal(types.ParentIngress))
+		})
+
+		It("returns egress hook for egress parent", func() {
+			egress := types.ParentEgress
+			Expect(types.HookOf(&egress)).To(Equal(types.ParentEgress))
+		})
 	})
 
 	Describe("QDisc Interface", func() {
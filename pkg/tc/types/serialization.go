@@ -0,0 +1,465 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SerializationVersion is the version of the serialized form of TC objects. it is bumped on incompatible changes
+// of the serialized types below.
+const SerializationVersion = "v1"
+
+// SerializedQDisc is the serialized form of QDisc
+type SerializedQDisc struct {
+	Type   QDiscType `json:"type"`
+	Parent *uint32   `json:"parent,omitempty"`
+	Handle *uint32   `json:"handle,omitempty"`
+}
+
+// SerializedChain is the serialized form of Chain
+type SerializedChain struct {
+	Parent   *uint32                  `json:"parent,omitempty"`
+	Chain    *uint32                  `json:"chain,omitempty"`
+	Template *SerializedChainTemplate `json:"template,omitempty"`
+}
+
+// SerializedChainTemplate is the serialized form of ChainTemplate
+type SerializedChainTemplate struct {
+	Protocol FilterProtocol        `json:"protocol,omitempty"`
+	Flower   *SerializedFlowerSpec `json:"flower,omitempty"`
+}
+
+// SerializedFilter is the serialized form of Filter. Kind determines which of the kind specific fields are valid:
+// Flower for FilterKindFlower, U32 for FilterKindU32. SkipHW is not valid for FilterKindBasic.
+type SerializedFilter struct {
+	Kind     FilterKind     `json:"kind"`
+	Protocol FilterProtocol `json:"protocol,omitempty"`
+	Chain    *uint32        `json:"chain,omitempty"`
+	Handle   *uint32        `json:"handle,omitempty"`
+	Priority *uint16        `json:"priority,omitempty"`
+	Parent   *uint32        `json:"parent,omitempty"`
+
+	SkipHW  bool                  `json:"skipHW,omitempty"`
+	Flower  *SerializedFlowerSpec `json:"flower,omitempty"`
+	U32     *SerializedU32Spec    `json:"u32,omitempty"`
+	Actions []SerializedAction    `json:"actions,omitempty"`
+}
+
+// SerializedFlowerSpec is the serialized form of FlowerSpec. MAC addresses and IP networks are in tc format
+// (e.g "01:00:00:00:00:00/01:00:00:00:00:00", "10.0.0.0/24")
+type SerializedFlowerSpec struct {
+	VlanID       *uint16                   `json:"vlanID,omitempty"`
+	VlanEthType  *FlowerVlanEthType        `json:"vlanEthType,omitempty"`
+	CVlanID      *uint16                   `json:"cvlanID,omitempty"`
+	CVlanEthType *FlowerVlanEthType        `json:"cvlanEthType,omitempty"`
+	SrcMAC       string                    `json:"srcMAC,omitempty"`
+	DstMAC       string                    `json:"dstMAC,omitempty"`
+	IPProto      *FlowerIPProto            `json:"ipProto,omitempty"`
+	SrcIP        string                    `json:"srcIP,omitempty"`
+	DstIP        string                    `json:"dstIP,omitempty"`
+	ArpSIP       string                    `json:"arpSIP,omitempty"`
+	SrcPort      *uint16                   `json:"srcPort,omitempty"`
+	DstPort      *uint16                   `json:"dstPort,omitempty"`
+	IPFlags      *FlowerIPFlags            `json:"ipFlags,omitempty"`
+	TCPFlags     *SerializedFlowerTCPFlags `json:"tcpFlags,omitempty"`
+	ICMPType     *uint8                    `json:"icmpType,omitempty"`
+	CtState      *FlowerCtState            `json:"ctState,omitempty"`
+}
+
+// SerializedFlowerTCPFlags is the serialized form of FlowerTCPFlags
+type SerializedFlowerTCPFlags struct {
+	Flags uint16 `json:"flags"`
+	Mask  uint16 `json:"mask"`
+}
+
+// SerializedU32Spec is the serialized form of U32Spec
+type SerializedU32Spec struct {
+	Divisor   uint32                `json:"divisor,omitempty"`
+	HashTable uint32                `json:"hashTable,omitempty"`
+	Bucket    uint32                `json:"bucket,omitempty"`
+	Matches   []SerializedU32Match  `json:"matches,omitempty"`
+	HashKey   *SerializedU32HashKey `json:"hashKey,omitempty"`
+	Link      uint32                `json:"link,omitempty"`
+}
+
+// SerializedU32Match is the serialized form of U32Match
+type SerializedU32Match struct {
+	Value  uint32 `json:"value"`
+	Mask   uint32 `json:"mask"`
+	Offset int32  `json:"offset"`
+}
+
+// SerializedU32HashKey is the serialized form of U32HashKey
+type SerializedU32HashKey struct {
+	Mask   uint32 `json:"mask"`
+	Offset int32  `json:"offset"`
+}
+
+// SerializedAction is the serialized form of Action. Kind determines which of the kind specific fields are valid:
+// ControlAction and Chain for ActionTypeGeneric, Commit and Zone for ActionTypeConnTrack, Header and DSCP for
+// ActionTypePedit, Updates for ActionTypeCsum, Rate, Burst, Conform and Exceed for ActionTypePolice.
+type SerializedAction struct {
+	Kind ActionType `json:"kind"`
+
+	ControlAction ActionGenericType `json:"controlAction,omitempty"`
+	Chain         uint32            `json:"chain,omitempty"`
+
+	Commit bool   `json:"commit,omitempty"`
+	Zone   uint16 `json:"zone,omitempty"`
+
+	Header PeditHeaderType `json:"header,omitempty"`
+	DSCP   uint8           `json:"dscp,omitempty"`
+
+	Updates []CsumUpdateType `json:"updates,omitempty"`
+
+	// Rate in bytes per second
+	Rate uint64 `json:"rate,omitempty"`
+	// Burst in bytes
+	Burst   uint32            `json:"burst,omitempty"`
+	Conform PoliceControlType `json:"conform,omitempty"`
+	Exceed  PoliceControlType `json:"exceed,omitempty"`
+}
+
+// QDiscToSerializedQDisc converts QDisc to SerializedQDisc
+func QDiscToSerializedQDisc(qdisc QDisc) *SerializedQDisc {
+	return &SerializedQDisc{Type: qdisc.Type(), Parent: qdisc.Attrs().Parent, Handle: qdisc.Attrs().Handle}
+}
+
+// SerializedQDiscToQDisc converts SerializedQDisc to QDisc
+func SerializedQDiscToQDisc(sq *SerializedQDisc) (QDisc, error) {
+	if sq.Type != QDiscIngressType && sq.Type != QDiscClsactType {
+		return nil, fmt.Errorf("unsupported qdisc type: %q", sq.Type)
+	}
+	return NewGenericQdisc(NewQDiscAttrs(sq.Parent, sq.Handle), sq.Type), nil
+}
+
+// ChainToSerializedChain converts Chain to SerializedChain
+func ChainToSerializedChain(chain Chain) *SerializedChain {
+	sc := &SerializedChain{Parent: chain.Attrs().Parent, Chain: chain.Attrs().Chain}
+	if template := chain.Attrs().Template; template != nil {
+		sc.Template = &SerializedChainTemplate{
+			Protocol: template.Protocol,
+			Flower:   flowerSpecToSerializedFlowerSpec(template.Flower),
+		}
+	}
+	return sc
+}
+
+// SerializedChainToChain converts SerializedChain to Chain
+func SerializedChainToChain(sc *SerializedChain) (Chain, error) {
+	chain := NewChainImpl(sc.Parent, sc.Chain)
+	if sc.Template != nil {
+		flower, err := serializedFlowerSpecToFlowerSpec(sc.Template.Flower)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert chain template")
+		}
+		if flower == nil {
+			return nil, fmt.Errorf("chain template has no flower spec")
+		}
+		chain.Template = &ChainTemplate{Protocol: sc.Template.Protocol, Flower: flower}
+	}
+	return chain, nil
+}
+
+// FilterToSerializedFilter converts Filter to SerializedFilter
+func FilterToSerializedFilter(filter Filter) (*SerializedFilter, error) {
+	attrs := filter.Attrs()
+	sf := &SerializedFilter{
+		Kind:     attrs.Kind,
+		Protocol: attrs.Protocol,
+		Chain:    attrs.Chain,
+		Handle:   attrs.Handle,
+		Priority: attrs.Priority,
+		Parent:   attrs.Parent,
+	}
+
+	var actions []Action
+	switch f := filter.(type) {
+	case *FlowerFilter:
+		sf.SkipHW = f.SkipHW
+		sf.Flower = flowerSpecToSerializedFlowerSpec(f.Flower)
+		actions = f.Actions
+	case *U32Filter:
+		sf.SkipHW = f.SkipHW
+		sf.U32 = u32SpecToSerializedU32Spec(f.U32)
+		actions = f.Actions
+	case *MatchAllFilter:
+		sf.SkipHW = f.SkipHW
+		actions = f.Actions
+	case *BasicFilter:
+		actions = f.Actions
+	default:
+		return nil, fmt.Errorf("unsupported filter kind: %q", attrs.Kind)
+	}
+
+	for _, a := range actions {
+		sa, err := ActionToSerializedAction(a)
+		if err != nil {
+			return nil, err
+		}
+		sf.Actions = append(sf.Actions, *sa)
+	}
+	return sf, nil
+}
+
+// SerializedFilterToFilter converts SerializedFilter to Filter
+func SerializedFilterToFilter(sf *SerializedFilter) (Filter, error) {
+	attrs := NewFilterAttrs(sf.Kind, sf.Protocol, sf.Chain, sf.Handle, sf.Priority)
+	attrs.Parent = sf.Parent
+
+	actions := make([]Action, 0, len(sf.Actions))
+	for i := range sf.Actions {
+		a, err := SerializedActionToAction(&sf.Actions[i])
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+
+	switch sf.Kind {
+	case FilterKindFlower:
+		flower, err := serializedFlowerSpecToFlowerSpec(sf.Flower)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert flower filter")
+		}
+		return &FlowerFilter{FilterAttrs: *attrs, Flower: flower, SkipHW: sf.SkipHW, Actions: actions}, nil
+	case FilterKindU32:
+		return &U32Filter{
+			FilterAttrs: *attrs, U32: serializedU32SpecToU32Spec(sf.U32), SkipHW: sf.SkipHW, Actions: actions}, nil
+	case FilterKindMatchAll:
+		return &MatchAllFilter{FilterAttrs: *attrs, SkipHW: sf.SkipHW, Actions: actions}, nil
+	case FilterKindBasic:
+		return &BasicFilter{FilterAttrs: *attrs, Actions: actions}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter kind: %q", sf.Kind)
+}
+
+// ActionToSerializedAction converts Action to SerializedAction
+func ActionToSerializedAction(action Action) (*SerializedAction, error) {
+	sa := &SerializedAction{Kind: action.Type()}
+
+	switch a := action.(type) {
+	case *GenericAction:
+		sa.ControlAction = a.controlAction
+		if a.controlAction == ActionGenericGoto {
+			sa.Chain = a.chain
+		}
+	case *ConnTrackAction:
+		sa.Commit = a.commit
+		sa.Zone = a.zone
+	case *PeditDSCPAction:
+		sa.Header = a.header
+		sa.DSCP = a.dscp
+	case *CsumAction:
+		sa.Updates = a.updates
+	case *PoliceAction:
+		sa.Rate = a.rate
+		sa.Burst = a.burst
+		sa.Conform = a.conform
+		sa.Exceed = a.exceed
+	default:
+		return nil, fmt.Errorf("unsupported action type: %q", action.Type())
+	}
+	return sa, nil
+}
+
+// SerializedActionToAction converts SerializedAction to Action
+func SerializedActionToAction(sa *SerializedAction) (Action, error) {
+	switch sa.Kind {
+	case ActionTypeGeneric:
+		switch sa.ControlAction {
+		case ActionGenericPass, ActionGenericDrop:
+			return NewGenericAction(sa.ControlAction), nil
+		case ActionGenericGoto:
+			return NewGenericGotoAction(sa.Chain), nil
+		}
+		return nil, fmt.Errorf("unsupported generic control action: %q", sa.ControlAction)
+	case ActionTypeConnTrack:
+		return NewConnTrackAction(sa.Commit, sa.Zone), nil
+	case ActionTypePedit:
+		if sa.Header != PeditHeaderIPv4 && sa.Header != PeditHeaderIPv6 {
+			return nil, fmt.Errorf("unsupported pedit header: %q", sa.Header)
+		}
+		if sa.DSCP > DSCPMax {
+			return nil, fmt.Errorf("invalid dscp value: %d", sa.DSCP)
+		}
+		return NewPeditDSCPAction(sa.Header, sa.DSCP), nil
+	case ActionTypeCsum:
+		return NewCsumAction(sa.Updates...), nil
+	case ActionTypePolice:
+		return NewPoliceAction(sa.Rate, sa.Burst, sa.Conform, sa.Exceed), nil
+	}
+	return nil, fmt.Errorf("unsupported action type: %q", sa.Kind)
+}
+
+// flowerSpecToSerializedFlowerSpec converts FlowerSpec to SerializedFlowerSpec
+func flowerSpecToSerializedFlowerSpec(flower *FlowerSpec) *SerializedFlowerSpec {
+	if flower == nil {
+		return nil
+	}
+
+	sf := &SerializedFlowerSpec{
+		VlanID:       flower.VlanID,
+		VlanEthType:  flower.VlanEthType,
+		CVlanID:      flower.CVlanID,
+		CVlanEthType: flower.CVlanEthType,
+		IPProto:      flower.IPProto,
+		SrcIP:        ipNetToString(flower.SrcIP),
+		DstIP:        ipNetToString(flower.DstIP),
+		ArpSIP:       ipNetToString(flower.ArpSIP),
+		SrcPort:      flower.SrcPort,
+		DstPort:      flower.DstPort,
+		IPFlags:      flower.IPFlags,
+		ICMPType:     flower.ICMPType,
+		CtState:      flower.CtState,
+	}
+	if flower.SrcMAC != nil {
+		sf.SrcMAC = flower.SrcMAC.String()
+	}
+	if flower.DstMAC != nil {
+		sf.DstMAC = flower.DstMAC.String()
+	}
+	if flower.TCPFlags != nil {
+		sf.TCPFlags = &SerializedFlowerTCPFlags{Flags: flower.TCPFlags.Flags, Mask: flower.TCPFlags.Mask}
+	}
+	return sf
+}
+
+// serializedFlowerSpecToFlowerSpec converts SerializedFlowerSpec to FlowerSpec
+func serializedFlowerSpecToFlowerSpec(sf *SerializedFlowerSpec) (*FlowerSpec, error) {
+	if sf == nil {
+		return nil, nil
+	}
+
+	var err error
+	flower := &FlowerSpec{
+		VlanID:       sf.VlanID,
+		VlanEthType:  sf.VlanEthType,
+		CVlanID:      sf.CVlanID,
+		CVlanEthType: sf.CVlanEthType,
+		IPProto:      sf.IPProto,
+		SrcPort:      sf.SrcPort,
+		DstPort:      sf.DstPort,
+		IPFlags:      sf.IPFlags,
+		ICMPType:     sf.ICMPType,
+		CtState:      sf.CtState,
+	}
+	if sf.SrcMAC != "" {
+		if flower.SrcMAC, err = net.ParseMAC(sf.SrcMAC); err != nil {
+			return nil, errors.Wrap(err, "failed to parse src mac")
+		}
+	}
+	if sf.DstMAC != "" {
+		if flower.DstMAC, err = parseFlowerMAC(sf.DstMAC); err != nil {
+			return nil, errors.Wrap(err, "failed to parse dst mac")
+		}
+	}
+	if flower.SrcIP, err = parseIPNet(sf.SrcIP); err != nil {
+		return nil, errors.Wrap(err, "failed to parse src ip")
+	}
+	if flower.DstIP, err = parseIPNet(sf.DstIP); err != nil {
+		return nil, errors.Wrap(err, "failed to parse dst ip")
+	}
+	if flower.ArpSIP, err = parseIPNet(sf.ArpSIP); err != nil {
+		return nil, errors.Wrap(err, "failed to parse arp sip")
+	}
+	if sf.TCPFlags != nil {
+		flower.TCPFlags = &FlowerTCPFlags{Flags: sf.TCPFlags.Flags, Mask: sf.TCPFlags.Mask}
+	}
+	return flower, nil
+}
+
+// u32SpecToSerializedU32Spec converts U32Spec to SerializedU32Spec
+func u32SpecToSerializedU32Spec(spec *U32Spec) *SerializedU32Spec {
+	if spec == nil {
+		return nil
+	}
+
+	su := &SerializedU32Spec{Divisor: spec.Divisor, HashTable: spec.HashTable, Bucket: spec.Bucket, Link: spec.Link}
+	for _, m := range spec.Matches {
+		su.Matches = append(su.Matches, SerializedU32Match{Value: m.Value, Mask: m.Mask, Offset: m.Offset})
+	}
+	if spec.HashKey != nil {
+		su.HashKey = &SerializedU32HashKey{Mask: spec.HashKey.Mask, Offset: spec.HashKey.Offset}
+	}
+	return su
+}
+
+// serializedU32SpecToU32Spec converts SerializedU32Spec to U32Spec
+func serializedU32SpecToU32Spec(su *SerializedU32Spec) *U32Spec {
+	if su == nil {
+		return nil
+	}
+
+	spec := &U32Spec{Divisor: su.Divisor, HashTable: su.HashTable, Bucket: su.Bucket, Link: su.Link}
+	for _, m := range su.Matches {
+		spec.Matches = append(spec.Matches, U32Match{Value: m.Value, Mask: m.Mask, Offset: m.Offset})
+	}
+	if su.HashKey != nil {
+		spec.HashKey = &U32HashKey{Mask: su.HashKey.Mask, Offset: su.HashKey.Offset}
+	}
+	return spec
+}
+
+// ipNetToString returns the IP address and mask of ipNet, with the mask in address notation
+// (e.g 10.0.0.1/255.255.255.0) to keep masks which are not a prefix, or an empty string if ipNet is nil
+func ipNetToString(ipNet *net.IPNet) string {
+	if ipNet == nil {
+		return ""
+	}
+	return ipNet.IP.String() + "/" + net.IP(ipNet.Mask).String()
+}
+
+// parseIPNet parses IP address and mask string s (see ipNetToString), or CIDR string s, keeping the IP address as is
+// (i.e host bits are not cleared). it returns nil if s is empty.
+func parseIPNet(s string) (*net.IPNet, error) {
+	if s == "" {
+		return nil, nil
+	}
+	addr, mask, found := strings.Cut(s, "/")
+	if !found || !strings.ContainsAny(mask, ".:") {
+		ip, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return &net.IPNet{IP: ip, Mask: ipNet.Mask}, nil
+	}
+
+	ip, maskIP := net.ParseIP(addr), net.ParseIP(mask)
+	if ip == nil || maskIP == nil {
+		return nil, fmt.Errorf("invalid IP address and mask: %q", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		mask4 := maskIP.To4()
+		if mask4 == nil || !strings.Contains(mask, ".") {
+			return nil, fmt.Errorf("invalid mask of IPv4 address: %q", s)
+		}
+		return &net.IPNet{IP: ip4, Mask: net.IPMask(mask4)}, nil
+	}
+	if strings.Contains(mask, ".") {
+		return nil, fmt.Errorf("invalid mask of IPv6 address: %q", s)
+	}
+	return &net.IPNet{IP: ip, Mask: net.IPMask(maskIP)}, nil
+}
+
+// parseFlowerMAC parses FlowerMAC string representation in tc format (see FlowerMAC.String())
+func parseFlowerMAC(s string) (*FlowerMAC, error) {
+	addr, mask, found := strings.Cut(s, "/")
+	fm := &FlowerMAC{}
+	var err error
+	if fm.Addr, err = net.ParseMAC(addr); err != nil {
+		return nil, err
+	}
+	if found {
+		if fm.Mask, err = net.ParseMAC(mask); err != nil {
+			return nil, err
+		}
+	}
+	return fm, nil
+}
@@ -0,0 +1,191 @@
+package types_test
+
+import (
+	"encoding/json"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/tc/types"
+)
+
+var _ = Describe("Serialization tests", func() {
+	mustParseCIDR := func(s string) *net.IPNet {
+		ip, ipNet, err := net.ParseCIDR(s)
+		Expect(err).ToNot(HaveOccurred())
+		ipNet.IP = ip
+		return ipNet
+	}
+	mustParseMAC := func(s string) net.HardwareAddr {
+		mac, err := net.ParseMAC(s)
+		Expect(err).ToNot(HaveOccurred())
+		return mac
+	}
+
+	// roundTrip serializes filter to JSON and back
+	roundTrip := func(filter types.Filter) types.Filter {
+		sf, err := types.FilterToSerializedFilter(filter)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := json.Marshal(sf)
+		Expect(err).ToNot(HaveOccurred())
+		var out types.SerializedFilter
+		Expect(json.Unmarshal(data, &out)).To(Succeed())
+		f, err := types.SerializedFilterToFilter(&out)
+		Expect(err).ToNot(HaveOccurred())
+		return f
+	}
+
+	Context("Filters", func() {
+		It("round trips flower filter with all match keys and actions", func() {
+			filter := types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocol8021Q).
+				WithChain(1).
+				WithHandle(3).
+				WithPriority(200).
+				WithParent(types.ParentEgress).
+				WithMatchKeyVlanID(100).
+				WithMatchKeyVlanEthType(types.FlowerVlanEthTypeIPv4).
+				WithMatchKeySrcMAC(mustParseMAC("00:11:22:33:44:55")).
+				WithMatchKeyDstMAC(mustParseMAC("01:00:00:00:00:00"), mustParseMAC("01:00:00:00:00:00")).
+				WithMatchKeyIPProto(types.FlowerIPProtoTCP).
+				WithMatchKeySrcIP(mustParseCIDR("10.0.0.5/24")).
+				WithMatchKeyDstIP(mustParseCIDR("2001::/64")).
+				WithMatchKeyDstPort(8080).
+				WithMatchKeyIPFlags(types.FlowerIPFlagsNoFrag).
+				WithMatchKeyTCPFlags(types.TCPFlagACK, types.TCPFlagACK).
+				WithMatchKeyCtState(types.FlowerCtStateEstablished).
+				WithSkipHW().
+				WithAction(types.NewConnTrackAction(true, 2)).
+				WithAction(types.NewPeditDSCPAction(types.PeditHeaderIPv4, 10)).
+				WithAction(types.NewCsumAction(types.CsumUpdateIPv4Header)).
+				WithAction(types.NewPoliceAction(1000, 64, types.PoliceControlPipe, types.PoliceControlDrop)).
+				WithAction(types.NewGenericGotoAction(100)).
+				Build()
+
+			out := roundTrip(filter)
+
+			Expect(out.Equals(filter)).To(BeTrue())
+			Expect(out.GenCmdLineArgs()).To(Equal(filter.GenCmdLineArgs()))
+			Expect(*out.Attrs().Handle).To(Equal(uint32(3)))
+		})
+
+		It("round trips u32, matchall and basic filters", func() {
+			filters := []types.Filter{
+				types.NewU32FilterBuilder().
+					WithProtocol(types.FilterProtocolIPv4).
+					WithPriority(206).
+					WithMatch(0, 0, 0).
+					WithHashKey(0xff, 16).
+					WithLink(0x100).
+					Build(),
+				types.NewU32FilterBuilder().WithProtocol(types.FilterProtocolIPv4).WithDivisor(0x100, 256).Build(),
+				types.NewMatchAllFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithSkipHW().
+					WithAction(types.NewGenericAction(types.ActionGenericDrop)).
+					Build(),
+				types.NewBasicFilterBuilder().
+					WithProtocol(types.FilterProtocolAll).
+					WithAction(types.NewGenericAction(types.ActionGenericPass)).
+					Build(),
+			}
+
+			for _, f := range filters {
+				Expect(roundTrip(f).Equals(f)).To(BeTrue())
+			}
+		})
+
+		It("round trips flower filter with non contiguous masks", func() {
+			filter := types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocolIPv4).
+				WithPriority(200).
+				WithMatchKeySrcIP(&net.IPNet{IP: net.ParseIP("2001::1:5"), Mask: net.IPMask(net.ParseIP("ffff::ffff"))}).
+				WithMatchKeyDstIP(&net.IPNet{IP: net.ParseIP("10.0.0.5").To4(), Mask: net.IPv4Mask(255, 0, 255, 0)}).
+				WithAction(types.NewGenericAction(types.ActionGenericPass)).
+				Build()
+			sf, err := types.FilterToSerializedFilter(filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sf.Flower.DstIP).To(Equal("10.0.0.5/255.0.255.0"))
+			Expect(sf.Flower.SrcIP).To(Equal("2001::1:5/ffff::ffff"))
+
+			out := roundTrip(filter)
+
+			Expect(out.Equals(filter)).To(BeTrue())
+			Expect(out.(*types.FlowerFilter).Flower.DstIP).To(Equal(filter.Flower.DstIP))
+			Expect(out.(*types.FlowerFilter).Flower.SrcIP).To(Equal(filter.Flower.SrcIP))
+		})
+
+		It("deserializes CIDR addresses", func() {
+			f, err := types.SerializedFilterToFilter(&types.SerializedFilter{
+				Kind: types.FilterKindFlower, Flower: &types.SerializedFlowerSpec{DstIP: "10.0.0.5/24"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.(*types.FlowerFilter).Flower.DstIP.String()).To(Equal("10.0.0.5/24"))
+		})
+
+		It("serializes filter kind and actions", func() {
+			filter := types.NewFlowerFilterBuilder().
+				WithProtocol(types.FilterProtocolIPv4).
+				WithPriority(200).
+				WithMatchKeyDstIP(mustParseCIDR("10.0.0.0/24")).
+				WithAction(types.NewGenericAction(types.ActionGenericPass)).
+				Build()
+			sf, err := types.FilterToSerializedFilter(filter)
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := json.Marshal(sf)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(MatchJSON(`{"kind": "flower", "protocol": "ip", "priority": 200,
+				"flower": {"dstIP": "10.0.0.0/255.255.255.0"}, "actions": [{"kind": "gact", "controlAction": "pass"}]}`))
+		})
+
+		It("fails to deserialize unsupported kinds", func() {
+			_, err := types.SerializedFilterToFilter(&types.SerializedFilter{Kind: "fw"})
+			Expect(err).To(HaveOccurred())
+
+			_, err = types.SerializedFilterToFilter(&types.SerializedFilter{
+				Kind: types.FilterKindMatchAll, Actions: []types.SerializedAction{{Kind: "mirred"}}})
+			Expect(err).To(HaveOccurred())
+
+			_, err = types.SerializedFilterToFilter(&types.SerializedFilter{
+				Kind: types.FilterKindFlower, Flower: &types.SerializedFlowerSpec{DstIP: "10.0.0.0"}})
+			Expect(err).To(HaveOccurred())
+
+			for _, ip := range []string{"10.0.0.0/ffff::", "2001::/255.255.0.0", "10.0.0.0/255.255.0"} {
+				_, err = types.SerializedFilterToFilter(&types.SerializedFilter{
+					Kind: types.FilterKindFlower, Flower: &types.SerializedFlowerSpec{DstIP: ip}})
+				Expect(err).To(HaveOccurred(), ip)
+			}
+		})
+	})
+
+	Context("Chains and QDiscs", func() {
+		It("round trips chain with template", func() {
+			chain := types.NewChainBuilder().
+				WithParent(types.ParentIngress).
+				WithChain(10000).
+				WithTemplate(types.FilterProtocolIPv4, &types.FlowerSpec{DstIP: mustParseCIDR("0.0.0.0/24")}).
+				Build()
+
+			out, err := types.SerializedChainToChain(types.ChainToSerializedChain(chain))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*out.Attrs().Parent).To(Equal(types.ParentIngress))
+			Expect(*out.Attrs().Chain).To(Equal(uint32(10000)))
+			Expect(out.Attrs().Template.Equals(chain.Template)).To(BeTrue())
+		})
+
+		It("round trips qdisc and fails for unsupported qdisc type", func() {
+			qdisc := types.NewClsactQDiscBuilder().WithHandle(0xffff0000).Build()
+
+			out, err := types.SerializedQDiscToQDisc(types.QDiscToSerializedQDisc(qdisc))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(BeEquivalentTo(qdisc))
+
+			_, err = types.SerializedQDiscToQDisc(&types.SerializedQDisc{Type: "mq"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -1,7 +1,13 @@
 package types
 
 import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
 	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/policyrules"
+	"github.com/k8snetworkplumbingwg/multi-networkpolicy-tc/pkg/utils"
 )
 
 // compare first with second. They are equal if:
@@ -30,6 +36,91 @@ func compare[C comparable](first *C, second *C, nilVal *C) bool {
 	return false
 }
 
+// ipNetEquals compares first with second. They are equal if both are nil or both have the same IP and mask
+func ipNetEquals(first, second *net.IPNet) bool {
+	if first == second {
+		return true
+	}
+
+	if first == nil || second == nil {
+		// one is nil the other is not
+		return false
+	}
+
+	// same IP (compare string representation to avoid cases where IP was created with 4 bytes vs 16 bytes)
+	if first.IP.String() != second.IP.String() {
+		return false
+	}
+	// same mask
+	return first.Mask.String() == second.Mask.String()
+}
+
+// flowerMACEquals compares first with second. They are equal if both are nil or both match the same MAC address
+// with the same mask
+func flowerMACEquals(first, second *FlowerMAC) bool {
+	if first == second {
+		return true
+	}
+
+	if first == nil || second == nil {
+		// one is nil the other is not
+		return false
+	}
+
+	return first.String() == second.String()
+}
+
+// nilKey is the key of a nil value
+const nilKey = "nil"
+
+// ptrKey returns a key of the value pointed by v, values are equal (see compare) if and only if their keys are equal
+func ptrKey[C comparable](v *C) string {
+	if v == nil {
+		return nilKey
+	}
+	return fmt.Sprintf("%#v", *v)
+}
+
+// ipNetKey returns a key of ipNet, ipNets are equal (see ipNetEquals) if and only if their keys are equal
+func ipNetKey(ipNet *net.IPNet) string {
+	if ipNet == nil {
+		return nilKey
+	}
+	return ipNet.IP.String() + "/" + ipNet.Mask.String()
+}
+
+// flowerMACKey returns a key of FlowerMAC, FlowerMACs are equal (see flowerMACEquals) if and only if their keys
+// are equal
+func flowerMACKey(fm *FlowerMAC) string {
+	if fm == nil {
+		return nilKey
+	}
+	return fm.String()
+}
+
+// actionsKey returns a key of actions, actions are equal (see actionsEqual) if and only if their keys are equal
+func actionsKey(actions []Action) string {
+	keys := make([]string, 0, len(actions))
+	for _, a := range actions {
+		spec := a.Spec()
+		fields := make([]string, 0, len(spec))
+		for k, v := range spec {
+			fields = append(fields, k+"="+v)
+		}
+		sort.Strings(fields)
+		keys = append(keys, fmt.Sprintf("%s{%s}", a.Type(), strings.Join(fields, ",")))
+	}
+	return strings.Join(keys, ";")
+}
+
+// ipNetCmdLineArg returns tc command line argument for the given ipNet, mask is omitted if full
+func ipNetCmdLineArg(ipNet *net.IPNet) string {
+	if ipNet.Mask != nil && !utils.IsMaskFull(ipNet.Mask) {
+		return ipNet.String()
+	}
+	return ipNet.IP.String()
+}
+
 // ProtoToFlowerVlanEthType converts FilterProtocol to FlowerVlanEthType, returns "" if conversion is invalid.
 func ProtoToFlowerVlanEthType(proto FilterProtocol) FlowerVlanEthType {
 	var vlanEthType FlowerVlanEthType
@@ -39,6 +130,10 @@ func ProtoToFlowerVlanEthType(proto FilterProtocol) FlowerVlanEthType {
 		vlanEthType = FlowerVlanEthTypeIPv4
 	case FilterProtocolIPv6:
 		vlanEthType = FlowerVlanEthTypeIPv6
+	case FilterProtocolARP:
+		vlanEthType = FlowerVlanEthTypeARP
+	case FilterProtocol8021Q:
+		vlanEthType = FlowerVlanEthType8021Q
 	}
 	return vlanEthType
 }
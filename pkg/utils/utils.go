@@ -102,6 +102,16 @@ func IPsFromStrings(ips []string) []net.IP {
 	return netIPs
 }
 
+// MACFromString receives a MAC address in string format and returns it as net.HardwareAddr
+// invalid or empty MAC address will be nil net.HardwareAddr
+func MACFromString(mac string) net.HardwareAddr {
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil
+	}
+	return hwAddr
+}
+
 // IsIPv4 returns true if IP is of type IPV4
 func IsIPv4(ip net.IP) bool {
 	// Note(adrianc): when Creating net.IP using net package e.g via net.ParseIP() it creates
@@ -245,6 +245,17 @@ var _ = Describe("utils test", func() {
 		})
 	})
 
+	Context("MACFromString()", func() {
+		It("Successfully parses MAC address", func() {
+			mac := utils.MACFromString("aa:bb:cc:dd:ee:01")
+			Expect(mac).To(Equal(net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x01}))
+		})
+		It("returns nil if MAC is empty or in bad format", func() {
+			Expect(utils.MACFromString("")).To(BeNil())
+			Expect(utils.MACFromString("invalid")).To(BeNil())
+		})
+	})
+
 	Context("IsIPv4()", func() {
 		It("returns true for IPv4 IP", func() {
 			ip := net.ParseIP("10.10.1.1")